@@ -6,6 +6,7 @@ import (
 
 	v1 "github.com/kikokikok/provider-garage/apis/v1"
 	v1alpha1 "github.com/kikokikok/provider-garage/apis/v1alpha1"
+	v1beta1 "github.com/kikokikok/provider-garage/apis/v1beta1"
 )
 
 func init() {
@@ -13,6 +14,7 @@ func init() {
 	// to GroupVersionKinds and back
 	AddToSchemes = append(AddToSchemes,
 		v1alpha1.SchemeBuilder.AddToScheme,
+		v1beta1.SchemeBuilder.AddToScheme,
 		v1.SchemeBuilder.AddToScheme,
 	)
 }
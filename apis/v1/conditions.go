@@ -0,0 +1,57 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// TypeHealthy indicates whether the Garage cluster a ProviderConfig connects
+// to is healthy.
+const TypeHealthy xpv1.ConditionType = "Healthy"
+
+// Reasons a ProviderConfig or GarageCluster is or is not ready and healthy.
+const (
+	ReasonCredentialsUnavailable xpv1.ConditionReason = "CredentialsUnavailable"
+	ReasonUnauthorized           xpv1.ConditionReason = "Unauthorized"
+	ReasonUnreachable            xpv1.ConditionReason = "Unreachable"
+	ReasonHealthy                xpv1.ConditionReason = "Healthy"
+	ReasonDegraded               xpv1.ConditionReason = "Degraded"
+	ReasonUnavailable            xpv1.ConditionReason = "Unavailable"
+)
+
+// Unready returns a condition that indicates the Garage Admin API cannot be
+// used with a ProviderConfig, or that the cluster a GarageCluster observes is
+// not healthy.
+func Unready(r xpv1.ConditionReason, msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             r,
+		Message:            msg,
+	}
+}
+
+// Healthy returns a condition that indicates the Garage cluster is healthy.
+func Healthy() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeHealthy,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonHealthy,
+	}
+}
+
+// Unhealthy returns a condition that indicates the Garage cluster is
+// degraded, unavailable, or could not be checked.
+func Unhealthy(r xpv1.ConditionReason, msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeHealthy,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             r,
+		Message:            msg,
+	}
+}
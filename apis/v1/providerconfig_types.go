@@ -5,11 +5,14 @@
 package v1
 
 import (
+	"reflect"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/scheme"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
 )
 
 // Package type metadata.
@@ -32,11 +35,16 @@ var (
 // +kubebuilder:object:root=true
 // +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="USERS",type="integer",JSONPath=".status.users"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="HEALTHY",type="string",JSONPath=".status.conditions[?(@.type=='Healthy')].status"
+// +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".status.cluster.garageVersion",priority=1
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,provider,garage}
 
-// A ProviderConfig configures the Garage provider.
-type ProviderConfig struct {
+// A ClusterProviderConfig configures the Garage provider for managed
+// resources in any namespace.
+type ClusterProviderConfig struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
@@ -44,14 +52,125 @@ type ProviderConfig struct {
 	Status ProviderConfigStatus `json:"status,omitempty"`
 }
 
-// ProviderConfigSpec defines the desired state of ProviderConfig.
+// ProviderConfigSpec defines the desired state of a provider config.
 type ProviderConfigSpec struct {
 	// Credentials required to authenticate to Garage Admin API.
 	Credentials ProviderCredentials `json:"credentials"`
 
-	// Endpoint is the Garage Admin API endpoint (e.g., http://garage:3903)
+	// Endpoint is the Garage Admin API endpoint, either a URL (e.g.,
+	// http://garage:3903) or a Unix socket (e.g., unix:///run/garage/admin.sock)
 	// +optional
 	Endpoint *string `json:"endpoint,omitempty"`
+
+	// Endpoints are further Admin API endpoints of the same Garage cluster.
+	// Requests fail over to them in turn when the active endpoint is
+	// unreachable or unavailable. Creates fail over only when they could not
+	// be sent.
+	// +optional
+	Endpoints []string `json:"endpoints,omitempty"`
+
+	// EndpointCooldown is how long an endpoint that failed is skipped before
+	// it is tried again. Defaults to 30s.
+	// +optional
+	EndpointCooldown *metav1.Duration `json:"endpointCooldown,omitempty"`
+
+	// Retry configures how Admin API requests that fail transiently are
+	// retried.
+	// +optional
+	Retry *RetryConfig `json:"retry,omitempty"`
+
+	// S3Endpoint is the Garage S3 API endpoint published to consumers of
+	// connection secrets (e.g., https://s3.garage.example.com)
+	// +optional
+	S3Endpoint *string `json:"s3Endpoint,omitempty"`
+
+	// Region is the S3 region configured in Garage (s3_region)
+	// +optional
+	Region *string `json:"region,omitempty"`
+
+	// WebEndpoint is the root domain of the Garage web endpoint
+	// (root_domain in the [s3_web] section), optionally with a scheme
+	// (e.g., https://web.garage.example.com). Buckets with website access
+	// are served at <alias>.<root domain>.
+	// +optional
+	WebEndpoint *string `json:"webEndpoint,omitempty"`
+
+	// TLS configures how the Garage Admin API endpoint is verified and how
+	// the provider authenticates to it with a client certificate.
+	// +optional
+	TLS *TLSConfig `json:"tls,omitempty"`
+}
+
+// RetryConfig configures retries of Admin API requests. Only requests that
+// are safe to repeat are retried; bucket creates are retried only after a
+// lookup confirms that the failed attempt did not take effect.
+type RetryConfig struct {
+	// MaxAttempts is the number of attempts per request, including the
+	// first. Defaults to 3; 1 disables retries.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxAttempts *int `json:"maxAttempts,omitempty"`
+
+	// BaseDelay is the delay before the first retry. It doubles with every
+	// further retry, with jitter. Defaults to 200ms.
+	// +optional
+	BaseDelay *metav1.Duration `json:"baseDelay,omitempty"`
+
+	// MaxDelay caps the delay between attempts. Requests whose Retry-After
+	// asks to wait longer are not retried. Defaults to 5s.
+	// +optional
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+}
+
+// TLSConfig configures TLS connections to the Garage Admin API.
+type TLSConfig struct {
+	// CABundle contains PEM encoded certificates trusted to sign the
+	// certificate of the Admin API endpoint, in addition to the system roots.
+	// +optional
+	CABundle *CABundleSource `json:"caBundle,omitempty"`
+
+	// ClientCertSecretRef selects a PEM encoded client certificate presented
+	// to the Admin API endpoint.
+	// +optional
+	ClientCertSecretRef *xpv1.SecretKeySelector `json:"clientCertSecretRef,omitempty"`
+
+	// ClientKeySecretRef selects the PEM encoded private key of the client
+	// certificate.
+	// +optional
+	ClientKeySecretRef *xpv1.SecretKeySelector `json:"clientKeySecretRef,omitempty"`
+
+	// ServerName overrides the name the certificate of the Admin API endpoint
+	// is verified against, e.g. when connecting through an IP address.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// InsecureSkipVerify disables verification of the certificate of the
+	// Admin API endpoint. Only use this in labs.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// CABundleSource selects a CA bundle from a Secret or a ConfigMap.
+type CABundleSource struct {
+	// SecretRef selects a key of a Secret holding the CA bundle.
+	// +optional
+	SecretRef *xpv1.SecretKeySelector `json:"secretRef,omitempty"`
+
+	// ConfigMapRef selects a key of a ConfigMap holding the CA bundle.
+	// +optional
+	ConfigMapRef *ConfigMapKeySelector `json:"configMapRef,omitempty"`
+}
+
+// ConfigMapKeySelector selects a key of a ConfigMap.
+type ConfigMapKeySelector struct {
+	// Name of the ConfigMap.
+	Name string `json:"name"`
+
+	// Namespace of the ConfigMap.
+	Namespace string `json:"namespace"`
+
+	// Key of the ConfigMap to select.
+	Key string `json:"key"`
 }
 
 // ProviderCredentials required to authenticate.
@@ -63,9 +182,67 @@ type ProviderCredentials struct {
 	xpv1.CommonCredentialSelectors `json:",inline"`
 }
 
-// ProviderConfigStatus defines the observed state of ProviderConfig.
+// ProviderConfigStatus defines the observed state of a provider config.
 type ProviderConfigStatus struct {
 	xpv1.ProviderConfigStatus `json:",inline"`
+
+	// Cluster is the Garage cluster as last observed by the health check.
+	// +optional
+	Cluster *ClusterObservation `json:"cluster,omitempty"`
+}
+
+// ClusterObservation describes the Garage cluster a provider config connects to.
+type ClusterObservation struct {
+	// GarageVersion is the version of Garage running on the node that answered.
+	GarageVersion string `json:"garageVersion,omitempty"`
+
+	// NodeID is the ID of the node that answered. Garage has no cluster-wide
+	// ID, so this identifies the cluster the endpoint reaches.
+	NodeID string `json:"nodeId,omitempty"`
+
+	// LayoutVersion is the version of the current cluster layout.
+	LayoutVersion int64 `json:"layoutVersion,omitempty"`
+
+	// KnownNodes is the number of nodes known to the cluster.
+	KnownNodes int `json:"knownNodes,omitempty"`
+
+	// ConnectedNodes is the number of nodes currently connected.
+	ConnectedNodes int `json:"connectedNodes,omitempty"`
+
+	// HealthyPartitions is the ratio of partitions with all their nodes up,
+	// e.g. 256/256.
+	HealthyPartitions string `json:"healthyPartitions,omitempty"`
+
+	// LastCheckTime is when the cluster was last checked.
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterProviderConfigList contains a list of ClusterProviderConfig.
+type ClusterProviderConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterProviderConfig `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="USERS",type="integer",JSONPath=".status.users"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="HEALTHY",type="string",JSONPath=".status.conditions[?(@.type=='Healthy')].status"
+// +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".status.cluster.garageVersion",priority=1
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,categories={crossplane,provider,garage}
+
+// A ProviderConfig configures the Garage provider for managed resources in
+// its namespace. Its credentials are read from its namespace.
+type ProviderConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProviderConfigSpec   `json:"spec"`
+	Status ProviderConfigStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -77,11 +254,58 @@ type ProviderConfigList struct {
 	Items           []ProviderConfig `json:"items"`
 }
 
+// A ProviderConfigReference references a ClusterProviderConfig, or a
+// ProviderConfig in the namespace of a managed resource.
+type ProviderConfigReference struct {
+	// Name of the referenced provider config.
+	Name string `json:"name"`
+
+	// Kind of the referenced provider config.
+	// +kubebuilder:validation:Enum=ClusterProviderConfig;ProviderConfig
+	// +kubebuilder:default=ClusterProviderConfig
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="CONFIG-NAME",type="string",JSONPath=".providerConfigRef.name"
+// +kubebuilder:printcolumn:name="RESOURCE-KIND",type="string",JSONPath=".resourceRef.kind"
+// +kubebuilder:printcolumn:name="RESOURCE-NAME",type="string",JSONPath=".resourceRef.name"
 // +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,categories={crossplane,provider,garage}
+
+// A ClusterProviderConfigUsage indicates that a resource is using a
+// ClusterProviderConfig. Usages live in the namespace of the managed resource
+// that owns them.
+type ClusterProviderConfigUsage struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	xpv1.ProviderConfigUsage `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterProviderConfigUsageList contains a list of
+// ClusterProviderConfigUsage.
+type ClusterProviderConfigUsageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterProviderConfigUsage `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="CONFIG-NAME",type="string",JSONPath=".providerConfigRef.name"
+// +kubebuilder:printcolumn:name="RESOURCE-KIND",type="string",JSONPath=".resourceRef.kind"
+// +kubebuilder:printcolumn:name="RESOURCE-NAME",type="string",JSONPath=".resourceRef.name"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,categories={crossplane,provider,garage}
 
 // A ProviderConfigUsage indicates that a resource is using a ProviderConfig.
+// It lives in the namespace of the managed resource that owns it, which is
+// also the namespace of the provider config.
 type ProviderConfigUsage struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -98,7 +322,145 @@ type ProviderConfigUsageList struct {
 	Items           []ProviderConfigUsage `json:"items"`
 }
 
+// ClusterProviderConfig type metadata.
+var (
+	ClusterProviderConfigKind             = reflect.TypeOf(ClusterProviderConfig{}).Name()
+	ClusterProviderConfigGroupKind        = schema.GroupKind{Group: Group, Kind: ClusterProviderConfigKind}.String()
+	ClusterProviderConfigKindAPIVersion   = ClusterProviderConfigKind + "." + GroupVersion.String()
+	ClusterProviderConfigGroupVersionKind = GroupVersion.WithKind(ClusterProviderConfigKind)
+)
+
+// ProviderConfig type metadata.
+var (
+	ProviderConfigKind             = reflect.TypeOf(ProviderConfig{}).Name()
+	ProviderConfigGroupKind        = schema.GroupKind{Group: Group, Kind: ProviderConfigKind}.String()
+	ProviderConfigKindAPIVersion   = ProviderConfigKind + "." + GroupVersion.String()
+	ProviderConfigGroupVersionKind = GroupVersion.WithKind(ProviderConfigKind)
+)
+
+// ClusterProviderConfigUsage type metadata.
+var (
+	ClusterProviderConfigUsageKind             = reflect.TypeOf(ClusterProviderConfigUsage{}).Name()
+	ClusterProviderConfigUsageGroupKind        = schema.GroupKind{Group: Group, Kind: ClusterProviderConfigUsageKind}.String()
+	ClusterProviderConfigUsageKindAPIVersion   = ClusterProviderConfigUsageKind + "." + GroupVersion.String()
+	ClusterProviderConfigUsageGroupVersionKind = GroupVersion.WithKind(ClusterProviderConfigUsageKind)
+
+	ClusterProviderConfigUsageListKind             = reflect.TypeOf(ClusterProviderConfigUsageList{}).Name()
+	ClusterProviderConfigUsageListGroupVersionKind = GroupVersion.WithKind(ClusterProviderConfigUsageListKind)
+)
+
+// ProviderConfigUsage type metadata.
+var (
+	ProviderConfigUsageKind             = reflect.TypeOf(ProviderConfigUsage{}).Name()
+	ProviderConfigUsageGroupKind        = schema.GroupKind{Group: Group, Kind: ProviderConfigUsageKind}.String()
+	ProviderConfigUsageKindAPIVersion   = ProviderConfigUsageKind + "." + GroupVersion.String()
+	ProviderConfigUsageGroupVersionKind = GroupVersion.WithKind(ProviderConfigUsageKind)
+
+	ProviderConfigUsageListKind             = reflect.TypeOf(ProviderConfigUsageList{}).Name()
+	ProviderConfigUsageListGroupVersionKind = GroupVersion.WithKind(ProviderConfigUsageListKind)
+)
+
 func init() {
+	SchemeBuilder.Register(&ClusterProviderConfig{}, &ClusterProviderConfigList{})
 	SchemeBuilder.Register(&ProviderConfig{}, &ProviderConfigList{})
+	SchemeBuilder.Register(&ClusterProviderConfigUsage{}, &ClusterProviderConfigUsageList{})
 	SchemeBuilder.Register(&ProviderConfigUsage{}, &ProviderConfigUsageList{})
 }
+
+// GetCondition of this ClusterProviderConfig.
+func (p *ClusterProviderConfig) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return p.Status.GetCondition(ct)
+}
+
+// GetUsers of this ClusterProviderConfig.
+func (p *ClusterProviderConfig) GetUsers() int64 {
+	return p.Status.Users
+}
+
+// SetConditions of this ClusterProviderConfig.
+func (p *ClusterProviderConfig) SetConditions(c ...xpv1.Condition) {
+	p.Status.SetConditions(c...)
+}
+
+// SetUsers of this ClusterProviderConfig.
+func (p *ClusterProviderConfig) SetUsers(i int64) {
+	p.Status.Users = i
+}
+
+// GetCondition of this ProviderConfig.
+func (p *ProviderConfig) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return p.Status.GetCondition(ct)
+}
+
+// GetUsers of this ProviderConfig.
+func (p *ProviderConfig) GetUsers() int64 {
+	return p.Status.Users
+}
+
+// SetConditions of this ProviderConfig.
+func (p *ProviderConfig) SetConditions(c ...xpv1.Condition) {
+	p.Status.SetConditions(c...)
+}
+
+// SetUsers of this ProviderConfig.
+func (p *ProviderConfig) SetUsers(i int64) {
+	p.Status.Users = i
+}
+
+// GetProviderConfigReference of this ClusterProviderConfigUsage.
+func (p *ClusterProviderConfigUsage) GetProviderConfigReference() xpv1.Reference {
+	return p.ProviderConfigReference
+}
+
+// GetResourceReference of this ClusterProviderConfigUsage.
+func (p *ClusterProviderConfigUsage) GetResourceReference() xpv1.TypedReference {
+	return p.ResourceReference
+}
+
+// SetProviderConfigReference of this ClusterProviderConfigUsage.
+func (p *ClusterProviderConfigUsage) SetProviderConfigReference(r xpv1.Reference) {
+	p.ProviderConfigReference = r
+}
+
+// SetResourceReference of this ClusterProviderConfigUsage.
+func (p *ClusterProviderConfigUsage) SetResourceReference(r xpv1.TypedReference) {
+	p.ResourceReference = r
+}
+
+// GetProviderConfigReference of this ProviderConfigUsage.
+func (p *ProviderConfigUsage) GetProviderConfigReference() xpv1.Reference {
+	return p.ProviderConfigReference
+}
+
+// GetResourceReference of this ProviderConfigUsage.
+func (p *ProviderConfigUsage) GetResourceReference() xpv1.TypedReference {
+	return p.ResourceReference
+}
+
+// SetProviderConfigReference of this ProviderConfigUsage.
+func (p *ProviderConfigUsage) SetProviderConfigReference(r xpv1.Reference) {
+	p.ProviderConfigReference = r
+}
+
+// SetResourceReference of this ProviderConfigUsage.
+func (p *ProviderConfigUsage) SetResourceReference(r xpv1.TypedReference) {
+	p.ResourceReference = r
+}
+
+// GetItems of this ClusterProviderConfigUsageList.
+func (p *ClusterProviderConfigUsageList) GetItems() []resource.ProviderConfigUsage {
+	items := make([]resource.ProviderConfigUsage, len(p.Items))
+	for i := range p.Items {
+		items[i] = &p.Items[i]
+	}
+	return items
+}
+
+// GetItems of this ProviderConfigUsageList.
+func (p *ProviderConfigUsageList) GetItems() []resource.ProviderConfigUsage {
+	items := make([]resource.ProviderConfigUsage, len(p.Items))
+	for i := range p.Items {
+		items[i] = &p.Items[i]
+	}
+	return items
+}
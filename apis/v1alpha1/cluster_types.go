@@ -0,0 +1,343 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+)
+
+// The cluster-scoped kinds below have exactly the fields of their namespaced
+// counterparts, so that they can be converted to them, e.g.
+// (*Bucket)(clusterBucket), and reconciled by the same controllers. Keep them
+// in sync.
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="ID",type="string",JSONPath=".status.atProvider.id"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,garage}
+
+// ClusterBucket is a cluster-scoped Bucket, for buckets that belong to the
+// platform rather than to a namespace. It must use a ProviderConfig,
+// and its connection secret is written to the namespace its
+// writeConnectionSecretToRef names. Its globalAlias and localAlias are
+// immutable once set.
+type ClusterBucket struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BucketSpec   `json:"spec"`
+	Status BucketStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterBucketList contains a list of ClusterBucket
+type ClusterBucketList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterBucket `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="ACCESS_KEY_ID",type="string",JSONPath=".status.atProvider.accessKeyId"
+// +kubebuilder:printcolumn:name="EXPIRATION",type="date",JSONPath=".status.atProvider.expiration",priority=1
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,garage}
+
+// ClusterKey is a cluster-scoped Key. It must use a ProviderConfig,
+// its credentials are written to the namespace its writeConnectionSecretToRef
+// names, and its bucketAccess references are to ClusterBuckets. Its name is
+// immutable.
+type ClusterKey struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KeySpec   `json:"spec"`
+	Status KeyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterKeyList contains a list of ClusterKey
+type ClusterKeyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterKey `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="BUCKET",type="string",JSONPath=".status.atProvider.bucketId"
+// +kubebuilder:printcolumn:name="KEY",type="string",JSONPath=".status.atProvider.accessKeyId"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,garage}
+
+// ClusterKeyAccess is a cluster-scoped KeyAccess. It must use a
+// ProviderConfig, and its bucket and key references and selectors are
+// to ClusterBuckets and ClusterKeys. Its bucket and key are immutable.
+type ClusterKeyAccess struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KeyAccessSpec   `json:"spec"`
+	Status KeyAccessStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterKeyAccessList contains a list of ClusterKeyAccess
+type ClusterKeyAccessList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterKeyAccess `json:"items"`
+}
+
+// GetCondition of this ClusterBucket.
+func (mg *ClusterBucket) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// GetDeletionPolicy of this ClusterBucket.
+func (mg *ClusterBucket) GetDeletionPolicy() xpv1.DeletionPolicy {
+	return mg.Spec.DeletionPolicy
+}
+
+// GetManagementPolicies of this ClusterBucket.
+func (mg *ClusterBucket) GetManagementPolicies() xpv1.ManagementPolicies {
+	return mg.Spec.ManagementPolicies
+}
+
+// GetProviderConfigReference of this ClusterBucket.
+func (mg *ClusterBucket) GetProviderConfigReference() *xpv1.Reference {
+	return (*Bucket)(mg).GetProviderConfigReference()
+}
+
+// GetTypedProviderConfigReference of this ClusterBucket, including its kind.
+func (mg *ClusterBucket) GetTypedProviderConfigReference() *v1.ProviderConfigReference {
+	return mg.Spec.ProviderConfigReference
+}
+
+// GetPublishConnectionDetailsTo of this ClusterBucket.
+func (mg *ClusterBucket) GetPublishConnectionDetailsTo() *xpv1.PublishConnectionDetailsTo {
+	return mg.Spec.PublishConnectionDetailsTo
+}
+
+// GetWriteConnectionSecretToReference of this ClusterBucket.
+func (mg *ClusterBucket) GetWriteConnectionSecretToReference() *xpv1.SecretReference {
+	return mg.Spec.WriteConnectionSecretToReference
+}
+
+// SetConditions of this ClusterBucket.
+func (mg *ClusterBucket) SetConditions(c ...xpv1.Condition) {
+	mg.Status.SetConditions(c...)
+}
+
+// SetDeletionPolicy of this ClusterBucket.
+func (mg *ClusterBucket) SetDeletionPolicy(r xpv1.DeletionPolicy) {
+	mg.Spec.DeletionPolicy = r
+}
+
+// SetManagementPolicies of this ClusterBucket.
+func (mg *ClusterBucket) SetManagementPolicies(r xpv1.ManagementPolicies) {
+	mg.Spec.ManagementPolicies = r
+}
+
+// SetProviderConfigReference of this ClusterBucket.
+func (mg *ClusterBucket) SetProviderConfigReference(r *xpv1.Reference) {
+	(*Bucket)(mg).SetProviderConfigReference(r)
+}
+
+// SetPublishConnectionDetailsTo of this ClusterBucket.
+func (mg *ClusterBucket) SetPublishConnectionDetailsTo(r *xpv1.PublishConnectionDetailsTo) {
+	mg.Spec.PublishConnectionDetailsTo = r
+}
+
+// SetWriteConnectionSecretToReference of this ClusterBucket.
+func (mg *ClusterBucket) SetWriteConnectionSecretToReference(r *xpv1.SecretReference) {
+	mg.Spec.WriteConnectionSecretToReference = r
+}
+
+// GetCondition of this ClusterKey.
+func (mg *ClusterKey) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// GetDeletionPolicy of this ClusterKey.
+func (mg *ClusterKey) GetDeletionPolicy() xpv1.DeletionPolicy {
+	return mg.Spec.DeletionPolicy
+}
+
+// GetManagementPolicies of this ClusterKey.
+func (mg *ClusterKey) GetManagementPolicies() xpv1.ManagementPolicies {
+	return mg.Spec.ManagementPolicies
+}
+
+// GetProviderConfigReference of this ClusterKey.
+func (mg *ClusterKey) GetProviderConfigReference() *xpv1.Reference {
+	return (*Key)(mg).GetProviderConfigReference()
+}
+
+// GetTypedProviderConfigReference of this ClusterKey, including its kind.
+func (mg *ClusterKey) GetTypedProviderConfigReference() *v1.ProviderConfigReference {
+	return mg.Spec.ProviderConfigReference
+}
+
+// GetPublishConnectionDetailsTo of this ClusterKey.
+func (mg *ClusterKey) GetPublishConnectionDetailsTo() *xpv1.PublishConnectionDetailsTo {
+	return mg.Spec.PublishConnectionDetailsTo
+}
+
+// GetWriteConnectionSecretToReference of this ClusterKey.
+func (mg *ClusterKey) GetWriteConnectionSecretToReference() *xpv1.SecretReference {
+	return mg.Spec.WriteConnectionSecretToReference
+}
+
+// SetConditions of this ClusterKey.
+func (mg *ClusterKey) SetConditions(c ...xpv1.Condition) {
+	mg.Status.SetConditions(c...)
+}
+
+// SetDeletionPolicy of this ClusterKey.
+func (mg *ClusterKey) SetDeletionPolicy(r xpv1.DeletionPolicy) {
+	mg.Spec.DeletionPolicy = r
+}
+
+// SetManagementPolicies of this ClusterKey.
+func (mg *ClusterKey) SetManagementPolicies(r xpv1.ManagementPolicies) {
+	mg.Spec.ManagementPolicies = r
+}
+
+// SetProviderConfigReference of this ClusterKey.
+func (mg *ClusterKey) SetProviderConfigReference(r *xpv1.Reference) {
+	(*Key)(mg).SetProviderConfigReference(r)
+}
+
+// SetPublishConnectionDetailsTo of this ClusterKey.
+func (mg *ClusterKey) SetPublishConnectionDetailsTo(r *xpv1.PublishConnectionDetailsTo) {
+	mg.Spec.PublishConnectionDetailsTo = r
+}
+
+// SetWriteConnectionSecretToReference of this ClusterKey.
+func (mg *ClusterKey) SetWriteConnectionSecretToReference(r *xpv1.SecretReference) {
+	mg.Spec.WriteConnectionSecretToReference = r
+}
+
+// GetCondition of this ClusterKeyAccess.
+func (mg *ClusterKeyAccess) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// GetDeletionPolicy of this ClusterKeyAccess.
+func (mg *ClusterKeyAccess) GetDeletionPolicy() xpv1.DeletionPolicy {
+	return mg.Spec.DeletionPolicy
+}
+
+// GetManagementPolicies of this ClusterKeyAccess.
+func (mg *ClusterKeyAccess) GetManagementPolicies() xpv1.ManagementPolicies {
+	return mg.Spec.ManagementPolicies
+}
+
+// GetProviderConfigReference of this ClusterKeyAccess.
+func (mg *ClusterKeyAccess) GetProviderConfigReference() *xpv1.Reference {
+	return (*KeyAccess)(mg).GetProviderConfigReference()
+}
+
+// GetTypedProviderConfigReference of this ClusterKeyAccess, including its
+// kind.
+func (mg *ClusterKeyAccess) GetTypedProviderConfigReference() *v1.ProviderConfigReference {
+	return mg.Spec.ProviderConfigReference
+}
+
+// GetPublishConnectionDetailsTo of this ClusterKeyAccess.
+func (mg *ClusterKeyAccess) GetPublishConnectionDetailsTo() *xpv1.PublishConnectionDetailsTo {
+	return mg.Spec.PublishConnectionDetailsTo
+}
+
+// GetWriteConnectionSecretToReference of this ClusterKeyAccess.
+func (mg *ClusterKeyAccess) GetWriteConnectionSecretToReference() *xpv1.SecretReference {
+	return mg.Spec.WriteConnectionSecretToReference
+}
+
+// SetConditions of this ClusterKeyAccess.
+func (mg *ClusterKeyAccess) SetConditions(c ...xpv1.Condition) {
+	mg.Status.SetConditions(c...)
+}
+
+// SetDeletionPolicy of this ClusterKeyAccess.
+func (mg *ClusterKeyAccess) SetDeletionPolicy(r xpv1.DeletionPolicy) {
+	mg.Spec.DeletionPolicy = r
+}
+
+// SetManagementPolicies of this ClusterKeyAccess.
+func (mg *ClusterKeyAccess) SetManagementPolicies(r xpv1.ManagementPolicies) {
+	mg.Spec.ManagementPolicies = r
+}
+
+// SetProviderConfigReference of this ClusterKeyAccess.
+func (mg *ClusterKeyAccess) SetProviderConfigReference(r *xpv1.Reference) {
+	(*KeyAccess)(mg).SetProviderConfigReference(r)
+}
+
+// SetPublishConnectionDetailsTo of this ClusterKeyAccess.
+func (mg *ClusterKeyAccess) SetPublishConnectionDetailsTo(r *xpv1.PublishConnectionDetailsTo) {
+	mg.Spec.PublishConnectionDetailsTo = r
+}
+
+// SetWriteConnectionSecretToReference of this ClusterKeyAccess.
+func (mg *ClusterKeyAccess) SetWriteConnectionSecretToReference(r *xpv1.SecretReference) {
+	mg.Spec.WriteConnectionSecretToReference = r
+}
+
+// GroupVersionKind returns the GroupVersionKind for ClusterBucket
+func (mg *ClusterBucket) GroupVersionKind() schema.GroupVersionKind {
+	return GroupVersion.WithKind(ClusterBucketKind)
+}
+
+// GroupVersionKind returns the GroupVersionKind for ClusterKey
+func (mg *ClusterKey) GroupVersionKind() schema.GroupVersionKind {
+	return GroupVersion.WithKind(ClusterKeyKind)
+}
+
+// GroupVersionKind returns the GroupVersionKind for ClusterKeyAccess
+func (mg *ClusterKeyAccess) GroupVersionKind() schema.GroupVersionKind {
+	return GroupVersion.WithKind(ClusterKeyAccessKind)
+}
+
+// GetItems of this ClusterBucketList.
+func (l *ClusterBucketList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
+
+// GetItems of this ClusterKeyList.
+func (l *ClusterKeyList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
+
+// GetItems of this ClusterKeyAccessList.
+func (l *ClusterKeyAccessList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
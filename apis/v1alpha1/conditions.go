@@ -0,0 +1,39 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// AnnotationKeyForceDelete may be set to "true" on a Bucket or Key to delete
+// it even though KeyAccess resources still reference it.
+const AnnotationKeyForceDelete = "garage.crossplane.io/force-delete"
+
+// TypeInUse indicates whether a resource is still referenced by other
+// resources and therefore cannot be deleted.
+const TypeInUse xpv1.ConditionType = "InUse"
+
+// Reasons a resource is or is not in use.
+const (
+	ReasonDependentsExist xpv1.ConditionReason = "DependentsExist"
+)
+
+// DeletionBlocked returns a condition that indicates the resource cannot be
+// deleted because other resources still depend on it.
+func DeletionBlocked(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeInUse,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDependentsExist,
+		Message:            msg,
+	}
+}
+
+// ForceDelete returns true if the supplied object is annotated to be deleted
+// regardless of its dependents.
+func ForceDelete(o metav1.Object) bool {
+	return o.GetAnnotations()[AnnotationKeyForceDelete] == "true"
+}
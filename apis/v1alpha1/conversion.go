@@ -0,0 +1,286 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/kikokikok/provider-garage/apis/v1beta1"
+)
+
+// AnnotationKeyAliases holds the aliases of a v1beta1 Bucket that do not fit
+// in a v1alpha1 Bucket, so that converting it back does not lose them.
+const AnnotationKeyAliases = "garage.crossplane.io/v1beta1-aliases"
+
+const (
+	errNotBucket    = "hub is not a v1beta1 Bucket"
+	errNotKey       = "hub is not a v1beta1 Key"
+	errNotKeyAccess = "hub is not a v1beta1 KeyAccess"
+	errAliases      = "cannot convert aliases"
+)
+
+// aliases are the aliases of a v1beta1 Bucket.
+type aliases struct {
+	GlobalAliases []string             `json:"globalAliases,omitempty"`
+	LocalAliases  []v1beta1.LocalAlias `json:"localAliases,omitempty"`
+}
+
+// ConvertTo converts this Bucket to the v1beta1 hub version. The first
+// global and local aliases come from this Bucket, and any others from the
+// aliases annotation. An alias this Bucket does not set is taken from the
+// annotation too, since aliases cannot be removed.
+func (in *Bucket) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1beta1.Bucket)
+	if !ok {
+		return errors.New(errNotBucket)
+	}
+	src := in.DeepCopy()
+
+	a := aliases{}
+	if v, ok := src.GetAnnotations()[AnnotationKeyAliases]; ok {
+		if err := json.Unmarshal([]byte(v), &a); err != nil {
+			return errors.Wrap(err, errAliases)
+		}
+		delete(src.Annotations, AnnotationKeyAliases)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec.ResourceSpec = v1beta1.ResourceSpec(src.Spec.ResourceSpec)
+	dst.Spec.ForProvider = v1beta1.BucketParameters{
+		GlobalAliases: first(src.Spec.ForProvider.GlobalAlias, a.GlobalAliases),
+		LocalAliases:  first((*v1beta1.LocalAlias)(src.Spec.ForProvider.LocalAlias), a.LocalAliases),
+		Quotas:        (*v1beta1.BucketQuotas)(src.Spec.ForProvider.Quotas),
+		Website:       (*v1beta1.BucketWebsite)(src.Spec.ForProvider.Website),
+	}
+	dst.Status = v1beta1.BucketStatus{
+		ResourceStatus: src.Status.ResourceStatus,
+		AtProvider:     v1beta1.BucketObservation(src.Status.AtProvider),
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1beta1 hub version to this Bucket. Aliases other
+// than the first global and local alias are kept in the aliases annotation.
+func (in *Bucket) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1beta1.Bucket)
+	if !ok {
+		return errors.New(errNotBucket)
+	}
+	src = src.DeepCopy()
+
+	in.ObjectMeta = src.ObjectMeta
+	in.Spec.ResourceSpec = ResourceSpec(src.Spec.ResourceSpec)
+	in.Spec.ForProvider = BucketParameters{
+		Quotas:  (*BucketQuotas)(src.Spec.ForProvider.Quotas),
+		Website: (*BucketWebsite)(src.Spec.ForProvider.Website),
+	}
+	p := src.Spec.ForProvider
+	if len(p.GlobalAliases) > 0 {
+		in.Spec.ForProvider.GlobalAlias = &p.GlobalAliases[0]
+	}
+	if len(p.LocalAliases) > 0 {
+		la := LocalAlias(p.LocalAliases[0])
+		in.Spec.ForProvider.LocalAlias = &la
+	}
+	delete(in.Annotations, AnnotationKeyAliases)
+	if len(p.GlobalAliases) > 1 || len(p.LocalAliases) > 1 {
+		v, err := json.Marshal(aliases{GlobalAliases: p.GlobalAliases, LocalAliases: p.LocalAliases})
+		if err != nil {
+			return errors.Wrap(err, errAliases)
+		}
+		if in.Annotations == nil {
+			in.Annotations = map[string]string{}
+		}
+		in.Annotations[AnnotationKeyAliases] = string(v)
+	}
+	in.Status = BucketStatus{
+		ResourceStatus: src.Status.ResourceStatus,
+		AtProvider:     BucketObservation(src.Status.AtProvider),
+	}
+	return nil
+}
+
+// first returns l with its first element replaced by v, if set.
+func first[T any](v *T, l []T) []T {
+	if v == nil {
+		return l
+	}
+	out := []T{*v}
+	if len(l) > 1 {
+		out = append(out, l[1:]...)
+	}
+	return out
+}
+
+// ConvertTo converts this Key to the v1beta1 hub version.
+func (in *Key) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1beta1.Key)
+	if !ok {
+		return errors.New(errNotKey)
+	}
+	src := in.DeepCopy()
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec.ResourceSpec = v1beta1.ResourceSpec(src.Spec.ResourceSpec)
+	dst.Spec.ForProvider = v1beta1.KeyParameters{
+		Name:        src.Spec.ForProvider.Name,
+		Permissions: (*v1beta1.KeyPermissions)(src.Spec.ForProvider.Permissions),
+		Expiration:  src.Spec.ForProvider.Expiration,
+	}
+	for _, a := range src.Spec.ForProvider.BucketAccess {
+		dst.Spec.ForProvider.BucketAccess = append(dst.Spec.ForProvider.BucketAccess, v1beta1.KeyBucketAccess{
+			BucketID:         a.BucketID,
+			BucketIDRef:      a.BucketIDRef,
+			BucketIDSelector: a.BucketIDSelector,
+			BucketAlias:      a.BucketAlias,
+			Permissions:      v1beta1.KeyAccessPermissions(a.Permissions),
+		})
+	}
+	dst.Spec.ConnectionSecretFormat = nil
+	if f := src.Spec.ConnectionSecretFormat; f != nil {
+		dst.Spec.ConnectionSecretFormat = &v1beta1.ConnectionSecretFormat{Templates: f.Templates}
+		for _, t := range f.Formats {
+			dst.Spec.ConnectionSecretFormat.Formats = append(dst.Spec.ConnectionSecretFormat.Formats, v1beta1.ConnectionSecretFormatType(t))
+		}
+	}
+
+	o := src.Status.AtProvider
+	dst.Status = v1beta1.KeyStatus{
+		ResourceStatus: src.Status.ResourceStatus,
+		AtProvider: v1beta1.KeyObservation{
+			AccessKeyID:                o.AccessKeyID,
+			Name:                       o.Name,
+			CreationTime:               o.CreationTime,
+			CreateBucket:               o.CreateBucket,
+			Expiration:                 o.Expiration,
+			Expired:                    o.Expired,
+			ConnectionSecretFormatHash: o.ConnectionSecretFormatHash,
+		},
+	}
+	for _, b := range o.Buckets {
+		dst.Status.AtProvider.Buckets = append(dst.Status.AtProvider.Buckets, v1beta1.KeyBucketObservation{
+			ID:                      b.ID,
+			GlobalAliases:           b.GlobalAliases,
+			LocalAliases:            b.LocalAliases,
+			Permissions:             v1beta1.KeyAccessPermissions(b.Permissions),
+			BucketAccessPermissions: (*v1beta1.KeyAccessPermissions)(b.BucketAccessPermissions),
+		})
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1beta1 hub version to this Key.
+func (in *Key) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1beta1.Key)
+	if !ok {
+		return errors.New(errNotKey)
+	}
+	src = src.DeepCopy()
+
+	in.ObjectMeta = src.ObjectMeta
+	in.Spec.ResourceSpec = ResourceSpec(src.Spec.ResourceSpec)
+	in.Spec.ForProvider = KeyParameters{
+		Name:        src.Spec.ForProvider.Name,
+		Permissions: (*KeyPermissions)(src.Spec.ForProvider.Permissions),
+		Expiration:  src.Spec.ForProvider.Expiration,
+	}
+	for _, a := range src.Spec.ForProvider.BucketAccess {
+		in.Spec.ForProvider.BucketAccess = append(in.Spec.ForProvider.BucketAccess, KeyBucketAccess{
+			BucketID:         a.BucketID,
+			BucketIDRef:      a.BucketIDRef,
+			BucketIDSelector: a.BucketIDSelector,
+			BucketAlias:      a.BucketAlias,
+			Permissions:      KeyAccessPermissions(a.Permissions),
+		})
+	}
+	in.Spec.ConnectionSecretFormat = nil
+	if f := src.Spec.ConnectionSecretFormat; f != nil {
+		in.Spec.ConnectionSecretFormat = &ConnectionSecretFormat{Templates: f.Templates}
+		for _, t := range f.Formats {
+			in.Spec.ConnectionSecretFormat.Formats = append(in.Spec.ConnectionSecretFormat.Formats, ConnectionSecretFormatType(t))
+		}
+	}
+
+	o := src.Status.AtProvider
+	in.Status = KeyStatus{
+		ResourceStatus: src.Status.ResourceStatus,
+		AtProvider: KeyObservation{
+			AccessKeyID:                o.AccessKeyID,
+			Name:                       o.Name,
+			CreationTime:               o.CreationTime,
+			CreateBucket:               o.CreateBucket,
+			Expiration:                 o.Expiration,
+			Expired:                    o.Expired,
+			ConnectionSecretFormatHash: o.ConnectionSecretFormatHash,
+		},
+	}
+	for _, b := range o.Buckets {
+		in.Status.AtProvider.Buckets = append(in.Status.AtProvider.Buckets, KeyBucketObservation{
+			ID:                      b.ID,
+			GlobalAliases:           b.GlobalAliases,
+			LocalAliases:            b.LocalAliases,
+			Permissions:             KeyAccessPermissions(b.Permissions),
+			BucketAccessPermissions: (*KeyAccessPermissions)(b.BucketAccessPermissions),
+		})
+	}
+	return nil
+}
+
+// ConvertTo converts this KeyAccess to the v1beta1 hub version.
+func (in *KeyAccess) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1beta1.KeyAccess)
+	if !ok {
+		return errors.New(errNotKeyAccess)
+	}
+	src := in.DeepCopy()
+
+	p := src.Spec.ForProvider
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec.ResourceSpec = v1beta1.ResourceSpec(src.Spec.ResourceSpec)
+	dst.Spec.ForProvider = v1beta1.KeyAccessParameters{
+		BucketID:              p.BucketID,
+		BucketIDRef:           p.BucketIDRef,
+		BucketIDSelector:      p.BucketIDSelector,
+		ClusterBucketIDRef:    p.ClusterBucketIDRef,
+		AccessKeyID:           p.AccessKeyID,
+		AccessKeyIDRef:        p.AccessKeyIDRef,
+		AccessKeyIDSelector:   p.AccessKeyIDSelector,
+		ClusterAccessKeyIDRef: p.ClusterAccessKeyIDRef,
+		Permissions:           v1beta1.KeyAccessPermissions(p.Permissions),
+	}
+	dst.Status = v1beta1.KeyAccessStatus{
+		ResourceStatus: src.Status.ResourceStatus,
+		AtProvider:     v1beta1.KeyAccessObservation(src.Status.AtProvider),
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1beta1 hub version to this KeyAccess.
+func (in *KeyAccess) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1beta1.KeyAccess)
+	if !ok {
+		return errors.New(errNotKeyAccess)
+	}
+	src = src.DeepCopy()
+
+	p := src.Spec.ForProvider
+	in.ObjectMeta = src.ObjectMeta
+	in.Spec.ResourceSpec = ResourceSpec(src.Spec.ResourceSpec)
+	in.Spec.ForProvider = KeyAccessParameters{
+		BucketID:              p.BucketID,
+		BucketIDRef:           p.BucketIDRef,
+		BucketIDSelector:      p.BucketIDSelector,
+		ClusterBucketIDRef:    p.ClusterBucketIDRef,
+		AccessKeyID:           p.AccessKeyID,
+		AccessKeyIDRef:        p.AccessKeyIDRef,
+		AccessKeyIDSelector:   p.AccessKeyIDSelector,
+		ClusterAccessKeyIDRef: p.ClusterAccessKeyIDRef,
+		Permissions:           KeyAccessPermissions(p.Permissions),
+	}
+	in.Status = KeyAccessStatus{
+		ResourceStatus: src.Status.ResourceStatus,
+		AtProvider:     KeyAccessObservation(src.Status.AtProvider),
+	}
+	return nil
+}
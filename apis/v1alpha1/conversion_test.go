@@ -0,0 +1,169 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	fuzz "github.com/google/gofuzz"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/kikokikok/provider-garage/apis/v1beta1"
+)
+
+const fuzzIterations = 1000
+
+// A convertible is a v1alpha1 type that converts to and from a v1beta1 hub.
+type convertible interface {
+	conversion.Convertible
+	metav1.Object
+}
+
+// TestFuzzRoundTrip converts random objects to the other version and back,
+// and expects them to be unchanged.
+func TestFuzzRoundTrip(t *testing.T) {
+	f := fuzz.New().NilChance(0.2).NumElements(0, 3)
+
+	cases := map[string]struct {
+		spoke func() convertible
+		hub   func() conversion.Hub
+	}{
+		"Bucket": {
+			spoke: func() convertible { return &Bucket{} },
+			hub:   func() conversion.Hub { return &v1beta1.Bucket{} },
+		},
+		"Key": {
+			spoke: func() convertible { return &Key{} },
+			hub:   func() conversion.Hub { return &v1beta1.Key{} },
+		},
+		"KeyAccess": {
+			spoke: func() convertible { return &KeyAccess{} },
+			hub:   func() conversion.Hub { return &v1beta1.KeyAccess{} },
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name+"/SpokeHubSpoke", func(t *testing.T) {
+			for i := 0; i < fuzzIterations; i++ {
+				want := tc.spoke()
+				f.Fuzz(want)
+				// Conversion leaves the type metadata to the caller.
+				want.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{})
+				delete(want.GetAnnotations(), AnnotationKeyAliases)
+				// Only a ClusterBucket, which has no v1beta1 version, may
+				// allow namespaces.
+				if b, ok := want.(*Bucket); ok {
+					b.Spec.AllowedNamespaces = nil
+				}
+
+				hub := tc.hub()
+				if err := want.ConvertTo(hub); err != nil {
+					t.Fatalf("ConvertTo(...): %v", err)
+				}
+				got := tc.spoke()
+				if err := got.ConvertFrom(hub); err != nil {
+					t.Fatalf("ConvertFrom(...): %v", err)
+				}
+				if diff := cmp.Diff(want, got, cmpopts.EquateEmpty()); diff != "" {
+					t.Fatalf("v1alpha1 -> v1beta1 -> v1alpha1: -want, +got:\n%s", diff)
+				}
+			}
+		})
+		t.Run(name+"/HubSpokeHub", func(t *testing.T) {
+			for i := 0; i < fuzzIterations; i++ {
+				want := tc.hub()
+				f.Fuzz(want)
+				want.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{})
+
+				spoke := tc.spoke()
+				if err := spoke.ConvertFrom(want); err != nil {
+					t.Fatalf("ConvertFrom(...): %v", err)
+				}
+				got := tc.hub()
+				if err := spoke.ConvertTo(got); err != nil {
+					t.Fatalf("ConvertTo(...): %v", err)
+				}
+				if diff := cmp.Diff(want, got, cmpopts.EquateEmpty()); diff != "" {
+					t.Fatalf("v1beta1 -> v1alpha1 -> v1beta1: -want, +got:\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestBucketAliases(t *testing.T) {
+	hub := &v1beta1.Bucket{Spec: v1beta1.BucketSpec{ForProvider: v1beta1.BucketParameters{
+		GlobalAliases: []string{"a", "b"},
+		LocalAliases:  []v1beta1.LocalAlias{{AccessKeyID: "GK1", Alias: "c"}},
+	}}}
+
+	got := &Bucket{}
+	if err := got.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom(...): %v", err)
+	}
+	want := &Bucket{}
+	want.SetAnnotations(map[string]string{
+		AnnotationKeyAliases: `{"globalAliases":["a","b"],"localAliases":[{"accessKeyId":"GK1","alias":"c"}]}`,
+	})
+	want.Spec.ForProvider = BucketParameters{
+		GlobalAlias: ptr.To("a"),
+		LocalAlias:  &LocalAlias{AccessKeyID: "GK1", Alias: "c"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ConvertFrom(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestBucketAliasesUnset(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		spoke  *Bucket
+		want   v1beta1.BucketParameters
+	}{
+		"GlobalAliasUnset": {
+			reason: "Should keep all global aliases when the first is unset",
+			spoke: &Bucket{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				AnnotationKeyAliases: `{"globalAliases":["a","b"]}`,
+			}}},
+			want: v1beta1.BucketParameters{GlobalAliases: []string{"a", "b"}},
+		},
+		"LocalAliasUnset": {
+			reason: "Should keep all local aliases when the first is unset",
+			spoke: &Bucket{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				AnnotationKeyAliases: `{"localAliases":[{"accessKeyId":"GK1","alias":"c"},{"accessKeyId":"GK2","alias":"d"}]}`,
+			}}},
+			want: v1beta1.BucketParameters{LocalAliases: []v1beta1.LocalAlias{{AccessKeyID: "GK1", Alias: "c"}, {AccessKeyID: "GK2", Alias: "d"}}},
+		},
+		"AliasesSet": {
+			reason: "Should put the aliases of the spec first",
+			spoke: &Bucket{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					AnnotationKeyAliases: `{"globalAliases":["a","b"],"localAliases":[{"accessKeyId":"GK1","alias":"c"},{"accessKeyId":"GK2","alias":"d"}]}`,
+				}},
+				Spec: BucketSpec{ForProvider: BucketParameters{
+					GlobalAlias: ptr.To("x"),
+					LocalAlias:  &LocalAlias{AccessKeyID: "GK3", Alias: "y"},
+				}},
+			},
+			want: v1beta1.BucketParameters{
+				GlobalAliases: []string{"x", "b"},
+				LocalAliases:  []v1beta1.LocalAlias{{AccessKeyID: "GK3", Alias: "y"}, {AccessKeyID: "GK2", Alias: "d"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			hub := &v1beta1.Bucket{}
+			if err := tc.spoke.ConvertTo(hub); err != nil {
+				t.Fatalf("ConvertTo(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, hub.Spec.ForProvider); diff != "" {
+				t.Errorf("\n%s\nConvertTo(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
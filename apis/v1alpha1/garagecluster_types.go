@@ -0,0 +1,217 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+)
+
+// GarageClusterSpec defines the desired state of GarageCluster
+type GarageClusterSpec struct {
+	ResourceSpec `json:",inline"`
+	// +optional
+	ForProvider GarageClusterParameters `json:"forProvider,omitempty"`
+}
+
+// GarageClusterParameters are the configurable fields of a GarageCluster.
+// There are none: a GarageCluster only observes the cluster its provider
+// config connects to.
+type GarageClusterParameters struct{}
+
+// GarageClusterStatus represents the observed state of a GarageCluster.
+type GarageClusterStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          GarageClusterObservation `json:"atProvider,omitempty"`
+}
+
+// GarageClusterObservation are the observable fields of a GarageCluster.
+type GarageClusterObservation struct {
+	// Status is the health of the cluster: healthy, degraded or unavailable
+	Status string `json:"status,omitempty"`
+	// KnownNodes is the number of nodes the answering node knows of
+	KnownNodes int `json:"knownNodes,omitempty"`
+	// ConnectedNodes is the number of nodes the answering node is connected to
+	ConnectedNodes int `json:"connectedNodes,omitempty"`
+	// StorageNodes is the number of nodes with a storage role in the layout
+	StorageNodes int `json:"storageNodes,omitempty"`
+	// StorageNodesOK is the number of storage nodes that are connected
+	StorageNodesOK int `json:"storageNodesOk,omitempty"`
+	// Partitions is the number of partitions of the layout
+	Partitions int `json:"partitions,omitempty"`
+	// PartitionsQuorum is the number of partitions with a quorum of nodes up
+	PartitionsQuorum int `json:"partitionsQuorum,omitempty"`
+	// PartitionsAllOK is the number of partitions with all their nodes up
+	PartitionsAllOK int `json:"partitionsAllOk,omitempty"`
+	// GarageVersion is the Garage version of the answering node
+	GarageVersion string `json:"garageVersion,omitempty"`
+	// LayoutVersion is the version of the current cluster layout
+	LayoutVersion int64 `json:"layoutVersion,omitempty"`
+	// Nodes are the nodes of the cluster
+	Nodes []GarageNodeObservation `json:"nodes,omitempty"`
+}
+
+// GarageNodeObservation is the observed state of a node of the cluster.
+type GarageNodeObservation struct {
+	// ID is the ID of the node
+	ID string `json:"id"`
+	// Hostname of the node
+	Hostname string `json:"hostname,omitempty"`
+	// Address of the node's RPC endpoint
+	Address string `json:"address,omitempty"`
+	// Up is true if the answering node is connected to this node
+	Up bool `json:"up"`
+	// LastSeenSecsAgo is the number of seconds since the node was last seen,
+	// if it is down
+	LastSeenSecsAgo *int64 `json:"lastSeenSecsAgo,omitempty"`
+	// GarageVersion is the Garage version of the node
+	GarageVersion string `json:"garageVersion,omitempty"`
+	// Role is the role of the node in the layout, if it has one
+	Role *GarageNodeRole `json:"role,omitempty"`
+	// Draining is true if the node is being removed from the layout
+	Draining bool `json:"draining,omitempty"`
+	// DataPartition is the space of the disk holding the node's data
+	DataPartition *GarageDiskSpace `json:"dataPartition,omitempty"`
+	// MetadataPartition is the space of the disk holding the node's metadata
+	MetadataPartition *GarageDiskSpace `json:"metadataPartition,omitempty"`
+}
+
+// GarageNodeRole is the role of a node in the cluster layout.
+type GarageNodeRole struct {
+	// Zone of the node
+	Zone string `json:"zone"`
+	// Capacity of the node in bytes, unset for gateway nodes
+	Capacity *int64 `json:"capacity,omitempty"`
+	// Tags of the node
+	Tags []string `json:"tags,omitempty"`
+}
+
+// GarageDiskSpace is the space of a disk of a node, in bytes.
+type GarageDiskSpace struct {
+	// Available space
+	Available int64 `json:"available"`
+	// Total space
+	Total int64 `json:"total"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.atProvider.status"
+// +kubebuilder:printcolumn:name="NODES",type="integer",JSONPath=".status.atProvider.connectedNodes"
+// +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".status.atProvider.garageVersion"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Namespaced,categories={crossplane,managed,garage}
+
+// GarageCluster is an observe-only managed resource that reports the health
+// and status of the Garage cluster its provider config connects to. It is
+// Ready while the cluster is healthy. Deleting it leaves the cluster alone.
+type GarageCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GarageClusterSpec   `json:"spec"`
+	Status GarageClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GarageClusterList contains a list of GarageCluster
+type GarageClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GarageCluster `json:"items"`
+}
+
+// GetCondition of this GarageCluster.
+func (mg *GarageCluster) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// GetDeletionPolicy of this GarageCluster.
+func (mg *GarageCluster) GetDeletionPolicy() xpv1.DeletionPolicy {
+	return mg.Spec.DeletionPolicy
+}
+
+// GetManagementPolicies of this GarageCluster.
+func (mg *GarageCluster) GetManagementPolicies() xpv1.ManagementPolicies {
+	return mg.Spec.ManagementPolicies
+}
+
+// GetProviderConfigReference of this GarageCluster.
+func (mg *GarageCluster) GetProviderConfigReference() *xpv1.Reference {
+	if mg.Spec.ProviderConfigReference == nil {
+		return nil
+	}
+	return &xpv1.Reference{Name: mg.Spec.ProviderConfigReference.Name}
+}
+
+// GetTypedProviderConfigReference of this GarageCluster, including its kind.
+func (mg *GarageCluster) GetTypedProviderConfigReference() *v1.ProviderConfigReference {
+	return mg.Spec.ProviderConfigReference
+}
+
+// GetPublishConnectionDetailsTo of this GarageCluster.
+func (mg *GarageCluster) GetPublishConnectionDetailsTo() *xpv1.PublishConnectionDetailsTo {
+	return mg.Spec.PublishConnectionDetailsTo
+}
+
+// GetWriteConnectionSecretToReference of this GarageCluster.
+func (mg *GarageCluster) GetWriteConnectionSecretToReference() *xpv1.SecretReference {
+	return mg.Spec.WriteConnectionSecretToReference
+}
+
+// SetConditions of this GarageCluster.
+func (mg *GarageCluster) SetConditions(c ...xpv1.Condition) {
+	mg.Status.SetConditions(c...)
+}
+
+// SetDeletionPolicy of this GarageCluster.
+func (mg *GarageCluster) SetDeletionPolicy(r xpv1.DeletionPolicy) {
+	mg.Spec.DeletionPolicy = r
+}
+
+// SetManagementPolicies of this GarageCluster.
+func (mg *GarageCluster) SetManagementPolicies(r xpv1.ManagementPolicies) {
+	mg.Spec.ManagementPolicies = r
+}
+
+// SetProviderConfigReference of this GarageCluster.
+func (mg *GarageCluster) SetProviderConfigReference(r *xpv1.Reference) {
+	if r == nil {
+		mg.Spec.ProviderConfigReference = nil
+		return
+	}
+	if mg.Spec.ProviderConfigReference == nil {
+		mg.Spec.ProviderConfigReference = &v1.ProviderConfigReference{Kind: v1.ClusterProviderConfigKind}
+	}
+	mg.Spec.ProviderConfigReference.Name = r.Name
+}
+
+// SetPublishConnectionDetailsTo of this GarageCluster.
+func (mg *GarageCluster) SetPublishConnectionDetailsTo(r *xpv1.PublishConnectionDetailsTo) {
+	mg.Spec.PublishConnectionDetailsTo = r
+}
+
+// SetWriteConnectionSecretToReference of this GarageCluster.
+func (mg *GarageCluster) SetWriteConnectionSecretToReference(r *xpv1.SecretReference) {
+	mg.Spec.WriteConnectionSecretToReference = r
+}
+
+// GroupVersionKind returns the GroupVersionKind for GarageCluster
+func (mg *GarageCluster) GroupVersionKind() schema.GroupVersionKind {
+	return GroupVersion.WithKind(GarageClusterKind)
+}
+
+// GetItems of this GarageClusterList.
+func (l *GarageClusterList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
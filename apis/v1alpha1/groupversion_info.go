@@ -51,8 +51,62 @@ var (
 	KeyAccessGroupVersionKind = GroupVersion.WithKind(KeyAccessKind)
 )
 
+// BucketAccessPolicy type metadata.
+var (
+	BucketAccessPolicyKind             = reflect.TypeOf(BucketAccessPolicy{}).Name()
+	BucketAccessPolicyGroupKind        = schema.GroupKind{Group: Group, Kind: BucketAccessPolicyKind}.String()
+	BucketAccessPolicyKindAPIVersion   = BucketAccessPolicyKind + "." + GroupVersion.String()
+	BucketAccessPolicyGroupVersionKind = GroupVersion.WithKind(BucketAccessPolicyKind)
+)
+
+// ClusterBucket type metadata.
+var (
+	ClusterBucketKind             = reflect.TypeOf(ClusterBucket{}).Name()
+	ClusterBucketGroupKind        = schema.GroupKind{Group: Group, Kind: ClusterBucketKind}.String()
+	ClusterBucketKindAPIVersion   = ClusterBucketKind + "." + GroupVersion.String()
+	ClusterBucketGroupVersionKind = GroupVersion.WithKind(ClusterBucketKind)
+)
+
+// ClusterKey type metadata.
+var (
+	ClusterKeyKind             = reflect.TypeOf(ClusterKey{}).Name()
+	ClusterKeyGroupKind        = schema.GroupKind{Group: Group, Kind: ClusterKeyKind}.String()
+	ClusterKeyKindAPIVersion   = ClusterKeyKind + "." + GroupVersion.String()
+	ClusterKeyGroupVersionKind = GroupVersion.WithKind(ClusterKeyKind)
+)
+
+// ClusterKeyAccess type metadata.
+var (
+	ClusterKeyAccessKind             = reflect.TypeOf(ClusterKeyAccess{}).Name()
+	ClusterKeyAccessGroupKind        = schema.GroupKind{Group: Group, Kind: ClusterKeyAccessKind}.String()
+	ClusterKeyAccessKindAPIVersion   = ClusterKeyAccessKind + "." + GroupVersion.String()
+	ClusterKeyAccessGroupVersionKind = GroupVersion.WithKind(ClusterKeyAccessKind)
+)
+
+// GarageCluster type metadata.
+var (
+	GarageClusterKind             = reflect.TypeOf(GarageCluster{}).Name()
+	GarageClusterGroupKind        = schema.GroupKind{Group: Group, Kind: GarageClusterKind}.String()
+	GarageClusterKindAPIVersion   = GarageClusterKind + "." + GroupVersion.String()
+	GarageClusterGroupVersionKind = GroupVersion.WithKind(GarageClusterKind)
+)
+
+// StoreConfig type metadata.
+var (
+	StoreConfigKind             = reflect.TypeOf(StoreConfig{}).Name()
+	StoreConfigGroupKind        = schema.GroupKind{Group: Group, Kind: StoreConfigKind}.String()
+	StoreConfigKindAPIVersion   = StoreConfigKind + "." + GroupVersion.String()
+	StoreConfigGroupVersionKind = GroupVersion.WithKind(StoreConfigKind)
+)
+
 func init() {
 	SchemeBuilder.Register(&Bucket{}, &BucketList{})
 	SchemeBuilder.Register(&Key{}, &KeyList{})
 	SchemeBuilder.Register(&KeyAccess{}, &KeyAccessList{})
+	SchemeBuilder.Register(&BucketAccessPolicy{}, &BucketAccessPolicyList{})
+	SchemeBuilder.Register(&ClusterBucket{}, &ClusterBucketList{})
+	SchemeBuilder.Register(&ClusterKey{}, &ClusterKeyList{})
+	SchemeBuilder.Register(&ClusterKeyAccess{}, &ClusterKeyAccessList{})
+	SchemeBuilder.Register(&GarageCluster{}, &GarageClusterList{})
+	SchemeBuilder.Register(&StoreConfig{}, &StoreConfigList{})
 }
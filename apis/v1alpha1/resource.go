@@ -0,0 +1,49 @@
+package v1alpha1
+
+import (
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+)
+
+// A ProviderConfigReferencer references a ProviderConfig or
+// ClusterProviderConfig by kind.
+// +kubebuilder:object:generate=false
+type ProviderConfigReferencer interface {
+	GetTypedProviderConfigReference() *v1.ProviderConfigReference
+}
+
+// ResourceSpec is the common spec of the managed resources of this provider.
+// It matches xpv1.ResourceSpec except that providerConfigRef has a kind.
+type ResourceSpec struct {
+	// WriteConnectionSecretToReference specifies the namespace and name of a
+	// Secret to which any connection details for this managed resource should
+	// be written.
+	// +optional
+	WriteConnectionSecretToReference *xpv1.SecretReference `json:"writeConnectionSecretToRef,omitempty"`
+
+	// PublishConnectionDetailsTo specifies the connection secret config which
+	// contains a name, metadata and a reference to secret store config to
+	// which any connection details for this managed resource should be written.
+	// +optional
+	PublishConnectionDetailsTo *xpv1.PublishConnectionDetailsTo `json:"publishConnectionDetailsTo,omitempty"`
+
+	// ProviderConfigReference specifies the ProviderConfig or
+	// ClusterProviderConfig used to create, observe, update, and delete this
+	// managed resource.
+	// +kubebuilder:default={"kind": "ClusterProviderConfig", "name": "default"}
+	ProviderConfigReference *v1.ProviderConfigReference `json:"providerConfigRef,omitempty"`
+
+	// ManagementPolicies specify the array of actions Crossplane is allowed to
+	// take on the managed and external resources.
+	// +optional
+	// +kubebuilder:default={"*"}
+	ManagementPolicies xpv1.ManagementPolicies `json:"managementPolicies,omitempty"`
+
+	// DeletionPolicy specifies what will happen to the underlying external
+	// when this managed resource is deleted - either "Delete" or "Orphan" the
+	// external resource.
+	// +optional
+	// +kubebuilder:default=Delete
+	DeletionPolicy xpv1.DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
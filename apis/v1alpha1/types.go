@@ -8,27 +8,53 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
 )
 
 // BucketSpec defines the desired state of Bucket
 type BucketSpec struct {
-	xpv1.ResourceSpec `json:",inline"`
-	ForProvider       BucketParameters `json:"forProvider"`
+	ResourceSpec `json:",inline"`
+	ForProvider  BucketParameters `json:"forProvider"`
+
+	// AllowedNamespaces are the namespaces whose KeyAccess resources may
+	// grant keys access to a ClusterBucket with clusterBucketIdRef. It can
+	// only be set on a ClusterBucket.
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
 }
 
 // BucketParameters are the configurable fields of a Bucket.
 type BucketParameters struct {
-	// GlobalAlias is the global alias for the bucket (S3 bucket name)
+	// GlobalAlias is the global alias for the bucket (S3 bucket name). It
+	// cannot be changed once set.
 	// +optional
 	GlobalAlias *string `json:"globalAlias,omitempty"`
 
-	// LocalAlias is a local alias for the bucket
+	// LocalAlias is a local alias for the bucket. It cannot be changed once set.
 	// +optional
 	LocalAlias *LocalAlias `json:"localAlias,omitempty"`
 
 	// Quotas for the bucket
 	// +optional
 	Quotas *BucketQuotas `json:"quotas,omitempty"`
+
+	// Website configures serving the bucket on the Garage web endpoint
+	// +optional
+	Website *BucketWebsite `json:"website,omitempty"`
+}
+
+// BucketWebsite configures serving a bucket as a website
+type BucketWebsite struct {
+	// Enabled serves the bucket on the Garage web endpoint
+	Enabled bool `json:"enabled"`
+	// IndexDocument is served for requests to a directory
+	// +optional
+	IndexDocument *string `json:"indexDocument,omitempty"`
+	// ErrorDocument is served when the requested object does not exist
+	// +optional
+	ErrorDocument *string `json:"errorDocument,omitempty"`
 }
 
 // LocalAlias represents a local alias for a bucket
@@ -64,7 +90,6 @@ type BucketObservation struct {
 }
 
 // +kubebuilder:object:root=true
-// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
@@ -92,18 +117,91 @@ type BucketList struct {
 
 // KeySpec defines the desired state of Key
 type KeySpec struct {
-	xpv1.ResourceSpec `json:",inline"`
-	ForProvider       KeyParameters `json:"forProvider"`
+	ResourceSpec `json:",inline"`
+	ForProvider  KeyParameters `json:"forProvider"`
+
+	// ConnectionSecretFormat adds keys in well-known formats to the
+	// connection secret, next to accessKeyId and secretAccessKey
+	// +optional
+	ConnectionSecretFormat *ConnectionSecretFormat `json:"connectionSecretFormat,omitempty"`
+}
+
+// A ConnectionSecretFormatType is a built-in connection secret format.
+// +kubebuilder:validation:Enum=aws-env;aws-credentials-file;rclone;s3cmd
+type ConnectionSecretFormatType string
+
+// Built-in connection secret formats.
+const (
+	// ConnectionSecretFormatAWSEnv adds AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY, AWS_ENDPOINT_URL and AWS_REGION.
+	ConnectionSecretFormatAWSEnv ConnectionSecretFormatType = "aws-env"
+
+	// ConnectionSecretFormatAWSCredentialsFile adds AWS shared "credentials"
+	// and "config" files.
+	ConnectionSecretFormatAWSCredentialsFile ConnectionSecretFormatType = "aws-credentials-file"
+
+	// ConnectionSecretFormatRclone adds an "rclone.conf" with a "garage" remote.
+	ConnectionSecretFormatRclone ConnectionSecretFormatType = "rclone"
+
+	// ConnectionSecretFormatS3cmd adds an s3cmd ".s3cfg".
+	ConnectionSecretFormatS3cmd ConnectionSecretFormatType = "s3cmd"
+)
+
+// ConnectionSecretFormat configures additional keys of a Key's connection secret
+type ConnectionSecretFormat struct {
+	// Formats are built-in formats whose keys are added to the connection secret
+	// +optional
+	Formats []ConnectionSecretFormatType `json:"formats,omitempty"`
+
+	// Templates are Go templates rendered into the connection secret key of
+	// the same name. Templates can use .AccessKeyID, .SecretAccessKey, .Name,
+	// .Endpoint and .Region.
+	// +optional
+	Templates map[string]string `json:"templates,omitempty"`
 }
 
 // KeyParameters are the configurable fields of a Key.
 type KeyParameters struct {
-	// Name is the name of the key
+	// Name is the name of the key. It cannot be changed.
 	Name string `json:"name"`
 
 	// Permissions for the key
 	// +optional
 	Permissions *KeyPermissions `json:"permissions,omitempty"`
+
+	// Expiration is when the key expires. Keys without one never expire.
+	// +optional
+	Expiration *metav1.Time `json:"expiration,omitempty"`
+
+	// BucketAccess grants the key access to buckets. Removing an entry
+	// revokes the access it granted. Buckets that were never listed are left
+	// alone, so grants made by KeyAccess or BucketAccessPolicy resources are
+	// kept. An imported key without bucketAccess is late-initialized with
+	// the access it has.
+	// +optional
+	BucketAccess []KeyBucketAccess `json:"bucketAccess,omitempty"`
+}
+
+// KeyBucketAccess grants a key access to a single bucket
+type KeyBucketAccess struct {
+	// BucketID is the ID of the bucket
+	// +optional
+	BucketID *string `json:"bucketId,omitempty"`
+
+	// BucketIDRef is a reference to a Bucket to retrieve its ID
+	// +optional
+	BucketIDRef *xpv1.Reference `json:"bucketIdRef,omitempty"`
+
+	// BucketIDSelector selects a reference to a Bucket
+	// +optional
+	BucketIDSelector *xpv1.Selector `json:"bucketIdSelector,omitempty"`
+
+	// BucketAlias is the global alias of the bucket
+	// +optional
+	BucketAlias *string `json:"bucketAlias,omitempty"`
+
+	// Permissions for the key on the bucket
+	Permissions KeyAccessPermissions `json:"permissions"`
 }
 
 // KeyPermissions represents global permissions for a key
@@ -122,14 +220,46 @@ type KeyStatus struct {
 type KeyObservation struct {
 	// AccessKeyID is the access key ID
 	AccessKeyID string `json:"accessKeyId,omitempty"`
+	// Name is the name of the key in Garage
+	Name string `json:"name,omitempty"`
+	// CreationTime is when the key was created
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+	// CreateBucket is true if the key may create buckets
+	CreateBucket bool `json:"createBucket,omitempty"`
+	// Expiration is when the key expires, if ever
+	Expiration *metav1.Time `json:"expiration,omitempty"`
+	// Expired is true if the key has expired
+	Expired bool `json:"expired,omitempty"`
+	// Buckets the key has access to
+	Buckets []KeyBucketObservation `json:"buckets,omitempty"`
+	// ConnectionSecretFormatHash identifies the connection secret format last
+	// published, so that it is only published again when it changes.
+	ConnectionSecretFormatHash string `json:"connectionSecretFormatHash,omitempty"`
+}
+
+// KeyBucketObservation is the observed access of a key to a bucket
+type KeyBucketObservation struct {
+	// ID is the unique identifier of the bucket
+	ID string `json:"id"`
+	// GlobalAliases are the global aliases of the bucket
+	GlobalAliases []string `json:"globalAliases,omitempty"`
+	// LocalAliases are the aliases of the bucket local to the key
+	LocalAliases []string `json:"localAliases,omitempty"`
+	// Permissions of the key on the bucket
+	Permissions KeyAccessPermissions `json:"permissions"`
+	// BucketAccessPermissions are the permissions listed by entries of the
+	// key's bucketAccess, which are revoked when no entry lists them anymore.
+	// Other permissions are left to KeyAccess and BucketAccessPolicy resources.
+	// +optional
+	BucketAccessPermissions *KeyAccessPermissions `json:"bucketAccessPermissions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
-// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
 // +kubebuilder:printcolumn:name="ACCESS_KEY_ID",type="string",JSONPath=".status.atProvider.accessKeyId"
+// +kubebuilder:printcolumn:name="EXPIRATION",type="date",JSONPath=".status.atProvider.expiration",priority=1
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:scope=Namespaced,categories={crossplane,managed,garage}
 
@@ -153,11 +283,14 @@ type KeyList struct {
 
 // KeyAccessSpec defines the desired state of KeyAccess
 type KeyAccessSpec struct {
-	xpv1.ResourceSpec `json:",inline"`
-	ForProvider       KeyAccessParameters `json:"forProvider"`
+	ResourceSpec `json:",inline"`
+	ForProvider  KeyAccessParameters `json:"forProvider"`
 }
 
-// KeyAccessParameters are the configurable fields of a KeyAccess.
+// KeyAccessParameters are the configurable fields of a KeyAccess. Exactly one
+// of bucketId, bucketIdRef, clusterBucketIdRef and bucketIdSelector and one of
+// accessKeyId, accessKeyIdRef, clusterAccessKeyIdRef and accessKeyIdSelector
+// must be set, and none of them can be changed.
 type KeyAccessParameters struct {
 	// BucketID is the ID of the bucket
 	// +optional
@@ -171,6 +304,10 @@ type KeyAccessParameters struct {
 	// +optional
 	BucketIDSelector *xpv1.Selector `json:"bucketIdSelector,omitempty"`
 
+	// ClusterBucketIDRef is a reference to a ClusterBucket to retrieve its ID
+	// +optional
+	ClusterBucketIDRef *xpv1.Reference `json:"clusterBucketIdRef,omitempty"`
+
 	// AccessKeyID is the access key ID
 	// +optional
 	AccessKeyID *string `json:"accessKeyId,omitempty"`
@@ -183,6 +320,11 @@ type KeyAccessParameters struct {
 	// +optional
 	AccessKeyIDSelector *xpv1.Selector `json:"accessKeyIdSelector,omitempty"`
 
+	// ClusterAccessKeyIDRef is a reference to a ClusterKey to retrieve its
+	// access key ID
+	// +optional
+	ClusterAccessKeyIDRef *xpv1.Reference `json:"clusterAccessKeyIdRef,omitempty"`
+
 	// Permissions for the key on the bucket
 	Permissions KeyAccessPermissions `json:"permissions"`
 }
@@ -212,7 +354,6 @@ type KeyAccessObservation struct {
 }
 
 // +kubebuilder:object:root=true
-// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
@@ -239,6 +380,130 @@ type KeyAccessList struct {
 	Items           []KeyAccess `json:"items"`
 }
 
+// BucketAccessPolicySpec defines the desired state of BucketAccessPolicy
+type BucketAccessPolicySpec struct {
+	ResourceSpec `json:",inline"`
+	ForProvider  BucketAccessPolicyParameters `json:"forProvider"`
+}
+
+// BucketAccessPolicyMode determines how grants that are not listed in a
+// BucketAccessPolicy are handled.
+// +kubebuilder:validation:Enum=Additive;Authoritative
+type BucketAccessPolicyMode string
+
+// Bucket access policy modes.
+const (
+	// BucketAccessPolicyModeAdditive leaves grants of keys that are not
+	// listed in the policy untouched.
+	BucketAccessPolicyModeAdditive BucketAccessPolicyMode = "Additive"
+
+	// BucketAccessPolicyModeAuthoritative revokes every grant on the bucket
+	// of keys that are not listed in the policy.
+	BucketAccessPolicyModeAuthoritative BucketAccessPolicyMode = "Authoritative"
+)
+
+// BucketAccessPolicyParameters are the configurable fields of a BucketAccessPolicy.
+// Exactly one of bucketId, bucketIdRef and bucketIdSelector must be set, and
+// none of them can be changed.
+type BucketAccessPolicyParameters struct {
+	// BucketID is the ID of the bucket
+	// +optional
+	BucketID *string `json:"bucketId,omitempty"`
+
+	// BucketIDRef is a reference to a Bucket to retrieve its ID
+	// +optional
+	BucketIDRef *xpv1.Reference `json:"bucketIdRef,omitempty"`
+
+	// BucketIDSelector selects a reference to a Bucket
+	// +optional
+	BucketIDSelector *xpv1.Selector `json:"bucketIdSelector,omitempty"`
+
+	// Mode is Additive to leave grants of unlisted keys alone, or
+	// Authoritative to revoke them
+	// +optional
+	// +kubebuilder:default=Additive
+	Mode BucketAccessPolicyMode `json:"mode,omitempty"`
+
+	// Grants are the keys that should have access to the bucket
+	// +optional
+	Grants []BucketAccessGrant `json:"grants,omitempty"`
+}
+
+// BucketAccessGrant grants a single key access to the bucket of a BucketAccessPolicy
+type BucketAccessGrant struct {
+	// AccessKeyID is the access key ID
+	// +optional
+	AccessKeyID *string `json:"accessKeyId,omitempty"`
+
+	// AccessKeyIDRef is a reference to a Key to retrieve its access key ID
+	// +optional
+	AccessKeyIDRef *xpv1.Reference `json:"accessKeyIdRef,omitempty"`
+
+	// AccessKeyIDSelector selects a reference to a Key
+	// +optional
+	AccessKeyIDSelector *xpv1.Selector `json:"accessKeyIdSelector,omitempty"`
+
+	// KeyName is the name of the key in Garage
+	// +optional
+	KeyName *string `json:"keyName,omitempty"`
+
+	// Permissions for the key on the bucket
+	Permissions KeyAccessPermissions `json:"permissions"`
+}
+
+// BucketAccessPolicyStatus represents the observed state of a BucketAccessPolicy.
+type BucketAccessPolicyStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          BucketAccessPolicyObservation `json:"atProvider,omitempty"`
+}
+
+// BucketAccessPolicyObservation are the observable fields of a BucketAccessPolicy.
+type BucketAccessPolicyObservation struct {
+	// BucketID is the ID of the bucket
+	BucketID string `json:"bucketId,omitempty"`
+	// Grants is the observed state of each grant, in the order of the spec
+	Grants []BucketAccessGrantObservation `json:"grants,omitempty"`
+}
+
+// BucketAccessGrantObservation is the observed state of a single grant
+type BucketAccessGrantObservation struct {
+	// AccessKeyID is the resolved access key ID
+	AccessKeyID string `json:"accessKeyId,omitempty"`
+	// Permissions the key currently has on the bucket
+	Permissions KeyAccessPermissions `json:"permissions"`
+	// Synced is true if the key has exactly the desired permissions
+	Synced bool `json:"synced"`
+	// Message explains why the grant is not synced
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="BUCKET",type="string",JSONPath=".status.atProvider.bucketId"
+// +kubebuilder:printcolumn:name="MODE",type="string",JSONPath=".spec.forProvider.mode"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Namespaced,categories={crossplane,managed,garage}
+
+// BucketAccessPolicy is a managed resource that grants many keys access to a bucket.
+type BucketAccessPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BucketAccessPolicySpec   `json:"spec"`
+	Status BucketAccessPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BucketAccessPolicyList contains a list of BucketAccessPolicy
+type BucketAccessPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BucketAccessPolicy `json:"items"`
+}
+
 // GetCondition of this Bucket.
 func (mg *Bucket) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
 	return mg.Status.GetCondition(ct)
@@ -256,6 +521,14 @@ func (mg *Bucket) GetManagementPolicies() xpv1.ManagementPolicies {
 
 // GetProviderConfigReference of this Bucket.
 func (mg *Bucket) GetProviderConfigReference() *xpv1.Reference {
+	if mg.Spec.ProviderConfigReference == nil {
+		return nil
+	}
+	return &xpv1.Reference{Name: mg.Spec.ProviderConfigReference.Name}
+}
+
+// GetTypedProviderConfigReference of this Bucket, including its kind.
+func (mg *Bucket) GetTypedProviderConfigReference() *v1.ProviderConfigReference {
 	return mg.Spec.ProviderConfigReference
 }
 
@@ -286,7 +559,14 @@ func (mg *Bucket) SetManagementPolicies(r xpv1.ManagementPolicies) {
 
 // SetProviderConfigReference of this Bucket.
 func (mg *Bucket) SetProviderConfigReference(r *xpv1.Reference) {
-	mg.Spec.ProviderConfigReference = r
+	if r == nil {
+		mg.Spec.ProviderConfigReference = nil
+		return
+	}
+	if mg.Spec.ProviderConfigReference == nil {
+		mg.Spec.ProviderConfigReference = &v1.ProviderConfigReference{Kind: v1.ClusterProviderConfigKind}
+	}
+	mg.Spec.ProviderConfigReference.Name = r.Name
 }
 
 // SetPublishConnectionDetailsTo of this Bucket.
@@ -313,6 +593,13 @@ func (mg *Key) GetManagementPolicies() xpv1.ManagementPolicies {
 }
 
 func (mg *Key) GetProviderConfigReference() *xpv1.Reference {
+	if mg.Spec.ProviderConfigReference == nil {
+		return nil
+	}
+	return &xpv1.Reference{Name: mg.Spec.ProviderConfigReference.Name}
+}
+
+func (mg *Key) GetTypedProviderConfigReference() *v1.ProviderConfigReference {
 	return mg.Spec.ProviderConfigReference
 }
 
@@ -337,7 +624,14 @@ func (mg *Key) SetManagementPolicies(r xpv1.ManagementPolicies) {
 }
 
 func (mg *Key) SetProviderConfigReference(r *xpv1.Reference) {
-	mg.Spec.ProviderConfigReference = r
+	if r == nil {
+		mg.Spec.ProviderConfigReference = nil
+		return
+	}
+	if mg.Spec.ProviderConfigReference == nil {
+		mg.Spec.ProviderConfigReference = &v1.ProviderConfigReference{Kind: v1.ClusterProviderConfigKind}
+	}
+	mg.Spec.ProviderConfigReference.Name = r.Name
 }
 
 func (mg *Key) SetPublishConnectionDetailsTo(r *xpv1.PublishConnectionDetailsTo) {
@@ -362,6 +656,13 @@ func (mg *KeyAccess) GetManagementPolicies() xpv1.ManagementPolicies {
 }
 
 func (mg *KeyAccess) GetProviderConfigReference() *xpv1.Reference {
+	if mg.Spec.ProviderConfigReference == nil {
+		return nil
+	}
+	return &xpv1.Reference{Name: mg.Spec.ProviderConfigReference.Name}
+}
+
+func (mg *KeyAccess) GetTypedProviderConfigReference() *v1.ProviderConfigReference {
 	return mg.Spec.ProviderConfigReference
 }
 
@@ -386,7 +687,14 @@ func (mg *KeyAccess) SetManagementPolicies(r xpv1.ManagementPolicies) {
 }
 
 func (mg *KeyAccess) SetProviderConfigReference(r *xpv1.Reference) {
-	mg.Spec.ProviderConfigReference = r
+	if r == nil {
+		mg.Spec.ProviderConfigReference = nil
+		return
+	}
+	if mg.Spec.ProviderConfigReference == nil {
+		mg.Spec.ProviderConfigReference = &v1.ProviderConfigReference{Kind: v1.ClusterProviderConfigKind}
+	}
+	mg.Spec.ProviderConfigReference.Name = r.Name
 }
 
 func (mg *KeyAccess) SetPublishConnectionDetailsTo(r *xpv1.PublishConnectionDetailsTo) {
@@ -397,6 +705,69 @@ func (mg *KeyAccess) SetWriteConnectionSecretToReference(r *xpv1.SecretReference
 	mg.Spec.WriteConnectionSecretToReference = r
 }
 
+// Similar methods for BucketAccessPolicy
+func (mg *BucketAccessPolicy) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+func (mg *BucketAccessPolicy) GetDeletionPolicy() xpv1.DeletionPolicy {
+	return mg.Spec.DeletionPolicy
+}
+
+func (mg *BucketAccessPolicy) GetManagementPolicies() xpv1.ManagementPolicies {
+	return mg.Spec.ManagementPolicies
+}
+
+func (mg *BucketAccessPolicy) GetProviderConfigReference() *xpv1.Reference {
+	if mg.Spec.ProviderConfigReference == nil {
+		return nil
+	}
+	return &xpv1.Reference{Name: mg.Spec.ProviderConfigReference.Name}
+}
+
+func (mg *BucketAccessPolicy) GetTypedProviderConfigReference() *v1.ProviderConfigReference {
+	return mg.Spec.ProviderConfigReference
+}
+
+func (mg *BucketAccessPolicy) GetPublishConnectionDetailsTo() *xpv1.PublishConnectionDetailsTo {
+	return mg.Spec.PublishConnectionDetailsTo
+}
+
+func (mg *BucketAccessPolicy) GetWriteConnectionSecretToReference() *xpv1.SecretReference {
+	return mg.Spec.WriteConnectionSecretToReference
+}
+
+func (mg *BucketAccessPolicy) SetConditions(c ...xpv1.Condition) {
+	mg.Status.SetConditions(c...)
+}
+
+func (mg *BucketAccessPolicy) SetDeletionPolicy(r xpv1.DeletionPolicy) {
+	mg.Spec.DeletionPolicy = r
+}
+
+func (mg *BucketAccessPolicy) SetManagementPolicies(r xpv1.ManagementPolicies) {
+	mg.Spec.ManagementPolicies = r
+}
+
+func (mg *BucketAccessPolicy) SetProviderConfigReference(r *xpv1.Reference) {
+	if r == nil {
+		mg.Spec.ProviderConfigReference = nil
+		return
+	}
+	if mg.Spec.ProviderConfigReference == nil {
+		mg.Spec.ProviderConfigReference = &v1.ProviderConfigReference{Kind: v1.ClusterProviderConfigKind}
+	}
+	mg.Spec.ProviderConfigReference.Name = r.Name
+}
+
+func (mg *BucketAccessPolicy) SetPublishConnectionDetailsTo(r *xpv1.PublishConnectionDetailsTo) {
+	mg.Spec.PublishConnectionDetailsTo = r
+}
+
+func (mg *BucketAccessPolicy) SetWriteConnectionSecretToReference(r *xpv1.SecretReference) {
+	mg.Spec.WriteConnectionSecretToReference = r
+}
+
 // GroupVersionKind returns the GroupVersionKind for Bucket
 func (mg *Bucket) GroupVersionKind() schema.GroupVersionKind {
 	return schema.GroupVersionKind{
@@ -423,3 +794,48 @@ func (mg *KeyAccess) GroupVersionKind() schema.GroupVersionKind {
 		Kind:    "KeyAccess",
 	}
 }
+
+// GroupVersionKind returns the GroupVersionKind for BucketAccessPolicy
+func (mg *BucketAccessPolicy) GroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{
+		Group:   GroupVersion.Group,
+		Version: GroupVersion.Version,
+		Kind:    "BucketAccessPolicy",
+	}
+}
+
+// GetItems of this BucketList.
+func (l *BucketList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
+
+// GetItems of this KeyList.
+func (l *KeyList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
+
+// GetItems of this KeyAccessList.
+func (l *KeyAccessList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
+
+// GetItems of this BucketAccessPolicyList.
+func (l *BucketAccessPolicyList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
@@ -22,6 +22,7 @@ package v1alpha1
 
 import (
 	"github.com/crossplane/crossplane-runtime/apis/common/v1"
+	apisv1 "github.com/kikokikok/provider-garage/apis/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -52,6 +53,208 @@ func (in *Bucket) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketAccessGrant) DeepCopyInto(out *BucketAccessGrant) {
+	*out = *in
+	if in.AccessKeyID != nil {
+		in, out := &in.AccessKeyID, &out.AccessKeyID
+		*out = new(string)
+		**out = **in
+	}
+	if in.AccessKeyIDRef != nil {
+		in, out := &in.AccessKeyIDRef, &out.AccessKeyIDRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccessKeyIDSelector != nil {
+		in, out := &in.AccessKeyIDSelector, &out.AccessKeyIDSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeyName != nil {
+		in, out := &in.KeyName, &out.KeyName
+		*out = new(string)
+		**out = **in
+	}
+	out.Permissions = in.Permissions
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketAccessGrant.
+func (in *BucketAccessGrant) DeepCopy() *BucketAccessGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketAccessGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketAccessGrantObservation) DeepCopyInto(out *BucketAccessGrantObservation) {
+	*out = *in
+	out.Permissions = in.Permissions
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketAccessGrantObservation.
+func (in *BucketAccessGrantObservation) DeepCopy() *BucketAccessGrantObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketAccessGrantObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketAccessPolicy) DeepCopyInto(out *BucketAccessPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketAccessPolicy.
+func (in *BucketAccessPolicy) DeepCopy() *BucketAccessPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketAccessPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BucketAccessPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketAccessPolicyList) DeepCopyInto(out *BucketAccessPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BucketAccessPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketAccessPolicyList.
+func (in *BucketAccessPolicyList) DeepCopy() *BucketAccessPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketAccessPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BucketAccessPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketAccessPolicyObservation) DeepCopyInto(out *BucketAccessPolicyObservation) {
+	*out = *in
+	if in.Grants != nil {
+		in, out := &in.Grants, &out.Grants
+		*out = make([]BucketAccessGrantObservation, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketAccessPolicyObservation.
+func (in *BucketAccessPolicyObservation) DeepCopy() *BucketAccessPolicyObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketAccessPolicyObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketAccessPolicyParameters) DeepCopyInto(out *BucketAccessPolicyParameters) {
+	*out = *in
+	if in.BucketID != nil {
+		in, out := &in.BucketID, &out.BucketID
+		*out = new(string)
+		**out = **in
+	}
+	if in.BucketIDRef != nil {
+		in, out := &in.BucketIDRef, &out.BucketIDRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BucketIDSelector != nil {
+		in, out := &in.BucketIDSelector, &out.BucketIDSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Grants != nil {
+		in, out := &in.Grants, &out.Grants
+		*out = make([]BucketAccessGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketAccessPolicyParameters.
+func (in *BucketAccessPolicyParameters) DeepCopy() *BucketAccessPolicyParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketAccessPolicyParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketAccessPolicySpec) DeepCopyInto(out *BucketAccessPolicySpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketAccessPolicySpec.
+func (in *BucketAccessPolicySpec) DeepCopy() *BucketAccessPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketAccessPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketAccessPolicyStatus) DeepCopyInto(out *BucketAccessPolicyStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketAccessPolicyStatus.
+func (in *BucketAccessPolicyStatus) DeepCopy() *BucketAccessPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketAccessPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BucketList) DeepCopyInto(out *BucketList) {
 	*out = *in
@@ -71,13 +274,420 @@ func (in *BucketList) DeepCopy() *BucketList {
 	if in == nil {
 		return nil
 	}
-	out := new(BucketList)
+	out := new(BucketList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BucketList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketObservation) DeepCopyInto(out *BucketObservation) {
+	*out = *in
+	if in.GlobalAliases != nil {
+		in, out := &in.GlobalAliases, &out.GlobalAliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketObservation.
+func (in *BucketObservation) DeepCopy() *BucketObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketParameters) DeepCopyInto(out *BucketParameters) {
+	*out = *in
+	if in.GlobalAlias != nil {
+		in, out := &in.GlobalAlias, &out.GlobalAlias
+		*out = new(string)
+		**out = **in
+	}
+	if in.LocalAlias != nil {
+		in, out := &in.LocalAlias, &out.LocalAlias
+		*out = new(LocalAlias)
+		**out = **in
+	}
+	if in.Quotas != nil {
+		in, out := &in.Quotas, &out.Quotas
+		*out = new(BucketQuotas)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Website != nil {
+		in, out := &in.Website, &out.Website
+		*out = new(BucketWebsite)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketParameters.
+func (in *BucketParameters) DeepCopy() *BucketParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketQuotas) DeepCopyInto(out *BucketQuotas) {
+	*out = *in
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxObjects != nil {
+		in, out := &in.MaxObjects, &out.MaxObjects
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketQuotas.
+func (in *BucketQuotas) DeepCopy() *BucketQuotas {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketQuotas)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketSpec) DeepCopyInto(out *BucketSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketSpec.
+func (in *BucketSpec) DeepCopy() *BucketSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketStatus) DeepCopyInto(out *BucketStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketStatus.
+func (in *BucketStatus) DeepCopy() *BucketStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketWebsite) DeepCopyInto(out *BucketWebsite) {
+	*out = *in
+	if in.IndexDocument != nil {
+		in, out := &in.IndexDocument, &out.IndexDocument
+		*out = new(string)
+		**out = **in
+	}
+	if in.ErrorDocument != nil {
+		in, out := &in.ErrorDocument, &out.ErrorDocument
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketWebsite.
+func (in *BucketWebsite) DeepCopy() *BucketWebsite {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketWebsite)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterBucket) DeepCopyInto(out *ClusterBucket) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterBucket.
+func (in *ClusterBucket) DeepCopy() *ClusterBucket {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterBucket)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterBucket) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterBucketList) DeepCopyInto(out *ClusterBucketList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterBucket, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterBucketList.
+func (in *ClusterBucketList) DeepCopy() *ClusterBucketList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterBucketList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterBucketList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterKey) DeepCopyInto(out *ClusterKey) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterKey.
+func (in *ClusterKey) DeepCopy() *ClusterKey {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterKey) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterKeyAccess) DeepCopyInto(out *ClusterKeyAccess) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterKeyAccess.
+func (in *ClusterKeyAccess) DeepCopy() *ClusterKeyAccess {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterKeyAccess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterKeyAccess) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterKeyAccessList) DeepCopyInto(out *ClusterKeyAccessList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterKeyAccess, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterKeyAccessList.
+func (in *ClusterKeyAccessList) DeepCopy() *ClusterKeyAccessList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterKeyAccessList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterKeyAccessList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterKeyList) DeepCopyInto(out *ClusterKeyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterKey, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterKeyList.
+func (in *ClusterKeyList) DeepCopy() *ClusterKeyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterKeyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterKeyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionSecretFormat) DeepCopyInto(out *ConnectionSecretFormat) {
+	*out = *in
+	if in.Formats != nil {
+		in, out := &in.Formats, &out.Formats
+		*out = make([]ConnectionSecretFormatType, len(*in))
+		copy(*out, *in)
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionSecretFormat.
+func (in *ConnectionSecretFormat) DeepCopy() *ConnectionSecretFormat {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionSecretFormat)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarageCluster) DeepCopyInto(out *GarageCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarageCluster.
+func (in *GarageCluster) DeepCopy() *GarageCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(GarageCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GarageCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarageClusterList) DeepCopyInto(out *GarageClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GarageCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarageClusterList.
+func (in *GarageClusterList) DeepCopy() *GarageClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(GarageClusterList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *BucketList) DeepCopyObject() runtime.Object {
+func (in *GarageClusterList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -85,110 +695,147 @@ func (in *BucketList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BucketObservation) DeepCopyInto(out *BucketObservation) {
+func (in *GarageClusterObservation) DeepCopyInto(out *GarageClusterObservation) {
 	*out = *in
-	if in.GlobalAliases != nil {
-		in, out := &in.GlobalAliases, &out.GlobalAliases
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]GarageNodeObservation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketObservation.
-func (in *BucketObservation) DeepCopy() *BucketObservation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarageClusterObservation.
+func (in *GarageClusterObservation) DeepCopy() *GarageClusterObservation {
 	if in == nil {
 		return nil
 	}
-	out := new(BucketObservation)
+	out := new(GarageClusterObservation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BucketParameters) DeepCopyInto(out *BucketParameters) {
+func (in *GarageClusterParameters) DeepCopyInto(out *GarageClusterParameters) {
 	*out = *in
-	if in.GlobalAlias != nil {
-		in, out := &in.GlobalAlias, &out.GlobalAlias
-		*out = new(string)
-		**out = **in
-	}
-	if in.LocalAlias != nil {
-		in, out := &in.LocalAlias, &out.LocalAlias
-		*out = new(LocalAlias)
-		**out = **in
-	}
-	if in.Quotas != nil {
-		in, out := &in.Quotas, &out.Quotas
-		*out = new(BucketQuotas)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketParameters.
-func (in *BucketParameters) DeepCopy() *BucketParameters {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarageClusterParameters.
+func (in *GarageClusterParameters) DeepCopy() *GarageClusterParameters {
 	if in == nil {
 		return nil
 	}
-	out := new(BucketParameters)
+	out := new(GarageClusterParameters)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BucketQuotas) DeepCopyInto(out *BucketQuotas) {
+func (in *GarageClusterSpec) DeepCopyInto(out *GarageClusterSpec) {
 	*out = *in
-	if in.MaxSize != nil {
-		in, out := &in.MaxSize, &out.MaxSize
-		*out = new(int64)
-		**out = **in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	out.ForProvider = in.ForProvider
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarageClusterSpec.
+func (in *GarageClusterSpec) DeepCopy() *GarageClusterSpec {
+	if in == nil {
+		return nil
 	}
-	if in.MaxObjects != nil {
-		in, out := &in.MaxObjects, &out.MaxObjects
-		*out = new(int64)
-		**out = **in
+	out := new(GarageClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarageClusterStatus) DeepCopyInto(out *GarageClusterStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarageClusterStatus.
+func (in *GarageClusterStatus) DeepCopy() *GarageClusterStatus {
+	if in == nil {
+		return nil
 	}
+	out := new(GarageClusterStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketQuotas.
-func (in *BucketQuotas) DeepCopy() *BucketQuotas {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarageDiskSpace) DeepCopyInto(out *GarageDiskSpace) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarageDiskSpace.
+func (in *GarageDiskSpace) DeepCopy() *GarageDiskSpace {
 	if in == nil {
 		return nil
 	}
-	out := new(BucketQuotas)
+	out := new(GarageDiskSpace)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BucketSpec) DeepCopyInto(out *BucketSpec) {
+func (in *GarageNodeObservation) DeepCopyInto(out *GarageNodeObservation) {
 	*out = *in
-	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
-	in.ForProvider.DeepCopyInto(&out.ForProvider)
+	if in.LastSeenSecsAgo != nil {
+		in, out := &in.LastSeenSecsAgo, &out.LastSeenSecsAgo
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Role != nil {
+		in, out := &in.Role, &out.Role
+		*out = new(GarageNodeRole)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataPartition != nil {
+		in, out := &in.DataPartition, &out.DataPartition
+		*out = new(GarageDiskSpace)
+		**out = **in
+	}
+	if in.MetadataPartition != nil {
+		in, out := &in.MetadataPartition, &out.MetadataPartition
+		*out = new(GarageDiskSpace)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketSpec.
-func (in *BucketSpec) DeepCopy() *BucketSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarageNodeObservation.
+func (in *GarageNodeObservation) DeepCopy() *GarageNodeObservation {
 	if in == nil {
 		return nil
 	}
-	out := new(BucketSpec)
+	out := new(GarageNodeObservation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BucketStatus) DeepCopyInto(out *BucketStatus) {
+func (in *GarageNodeRole) DeepCopyInto(out *GarageNodeRole) {
 	*out = *in
-	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
-	in.AtProvider.DeepCopyInto(&out.AtProvider)
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketStatus.
-func (in *BucketStatus) DeepCopy() *BucketStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarageNodeRole.
+func (in *GarageNodeRole) DeepCopy() *GarageNodeRole {
 	if in == nil {
 		return nil
 	}
-	out := new(BucketStatus)
+	out := new(GarageNodeRole)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -312,6 +959,11 @@ func (in *KeyAccessParameters) DeepCopyInto(out *KeyAccessParameters) {
 		*out = new(v1.Selector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ClusterBucketIDRef != nil {
+		in, out := &in.ClusterBucketIDRef, &out.ClusterBucketIDRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AccessKeyID != nil {
 		in, out := &in.AccessKeyID, &out.AccessKeyID
 		*out = new(string)
@@ -327,6 +979,11 @@ func (in *KeyAccessParameters) DeepCopyInto(out *KeyAccessParameters) {
 		*out = new(v1.Selector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ClusterAccessKeyIDRef != nil {
+		in, out := &in.ClusterAccessKeyIDRef, &out.ClusterAccessKeyIDRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Permissions = in.Permissions
 }
 
@@ -389,6 +1046,73 @@ func (in *KeyAccessStatus) DeepCopy() *KeyAccessStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyBucketAccess) DeepCopyInto(out *KeyBucketAccess) {
+	*out = *in
+	if in.BucketID != nil {
+		in, out := &in.BucketID, &out.BucketID
+		*out = new(string)
+		**out = **in
+	}
+	if in.BucketIDRef != nil {
+		in, out := &in.BucketIDRef, &out.BucketIDRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BucketIDSelector != nil {
+		in, out := &in.BucketIDSelector, &out.BucketIDSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BucketAlias != nil {
+		in, out := &in.BucketAlias, &out.BucketAlias
+		*out = new(string)
+		**out = **in
+	}
+	out.Permissions = in.Permissions
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyBucketAccess.
+func (in *KeyBucketAccess) DeepCopy() *KeyBucketAccess {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyBucketAccess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyBucketObservation) DeepCopyInto(out *KeyBucketObservation) {
+	*out = *in
+	if in.GlobalAliases != nil {
+		in, out := &in.GlobalAliases, &out.GlobalAliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LocalAliases != nil {
+		in, out := &in.LocalAliases, &out.LocalAliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Permissions = in.Permissions
+	if in.BucketAccessPermissions != nil {
+		in, out := &in.BucketAccessPermissions, &out.BucketAccessPermissions
+		*out = new(KeyAccessPermissions)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyBucketObservation.
+func (in *KeyBucketObservation) DeepCopy() *KeyBucketObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyBucketObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeyList) DeepCopyInto(out *KeyList) {
 	*out = *in
@@ -424,6 +1148,21 @@ func (in *KeyList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeyObservation) DeepCopyInto(out *KeyObservation) {
 	*out = *in
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Expiration != nil {
+		in, out := &in.Expiration, &out.Expiration
+		*out = (*in).DeepCopy()
+	}
+	if in.Buckets != nil {
+		in, out := &in.Buckets, &out.Buckets
+		*out = make([]KeyBucketObservation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyObservation.
@@ -444,6 +1183,17 @@ func (in *KeyParameters) DeepCopyInto(out *KeyParameters) {
 		*out = new(KeyPermissions)
 		**out = **in
 	}
+	if in.Expiration != nil {
+		in, out := &in.Expiration, &out.Expiration
+		*out = (*in).DeepCopy()
+	}
+	if in.BucketAccess != nil {
+		in, out := &in.BucketAccess, &out.BucketAccess
+		*out = make([]KeyBucketAccess, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyParameters.
@@ -476,6 +1226,11 @@ func (in *KeySpec) DeepCopyInto(out *KeySpec) {
 	*out = *in
 	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
 	in.ForProvider.DeepCopyInto(&out.ForProvider)
+	if in.ConnectionSecretFormat != nil {
+		in, out := &in.ConnectionSecretFormat, &out.ConnectionSecretFormat
+		*out = new(ConnectionSecretFormat)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeySpec.
@@ -492,7 +1247,7 @@ func (in *KeySpec) DeepCopy() *KeySpec {
 func (in *KeyStatus) DeepCopyInto(out *KeyStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
-	out.AtProvider = in.AtProvider
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyStatus.
@@ -519,3 +1274,129 @@ func (in *LocalAlias) DeepCopy() *LocalAlias {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSpec) DeepCopyInto(out *ResourceSpec) {
+	*out = *in
+	if in.WriteConnectionSecretToReference != nil {
+		in, out := &in.WriteConnectionSecretToReference, &out.WriteConnectionSecretToReference
+		*out = new(v1.SecretReference)
+		**out = **in
+	}
+	if in.PublishConnectionDetailsTo != nil {
+		in, out := &in.PublishConnectionDetailsTo, &out.PublishConnectionDetailsTo
+		*out = new(v1.PublishConnectionDetailsTo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProviderConfigReference != nil {
+		in, out := &in.ProviderConfigReference, &out.ProviderConfigReference
+		*out = new(apisv1.ProviderConfigReference)
+		**out = **in
+	}
+	if in.ManagementPolicies != nil {
+		in, out := &in.ManagementPolicies, &out.ManagementPolicies
+		*out = make(v1.ManagementPolicies, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSpec.
+func (in *ResourceSpec) DeepCopy() *ResourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreConfig) DeepCopyInto(out *StoreConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreConfig.
+func (in *StoreConfig) DeepCopy() *StoreConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StoreConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreConfigList) DeepCopyInto(out *StoreConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StoreConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreConfigList.
+func (in *StoreConfigList) DeepCopy() *StoreConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StoreConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreConfigSpec) DeepCopyInto(out *StoreConfigSpec) {
+	*out = *in
+	in.SecretStoreConfig.DeepCopyInto(&out.SecretStoreConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreConfigSpec.
+func (in *StoreConfigSpec) DeepCopy() *StoreConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreConfigStatus) DeepCopyInto(out *StoreConfigStatus) {
+	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreConfigStatus.
+func (in *StoreConfigStatus) DeepCopy() *StoreConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
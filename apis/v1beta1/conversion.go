@@ -0,0 +1,11 @@
+package v1beta1
+
+// Hub marks Bucket as the conversion hub. Other versions convert to and
+// from it.
+func (*Bucket) Hub() {}
+
+// Hub marks Key as the conversion hub.
+func (*Key) Hub() {}
+
+// Hub marks KeyAccess as the conversion hub.
+func (*KeyAccess) Hub() {}
@@ -0,0 +1,6 @@
+// Package v1beta1 contains API Schema definitions for the garage v1beta1 API group
+// +kubebuilder:object:generate=true
+// +groupName=garage.crossplane.io
+//
+//go:generate go run sigs.k8s.io/controller-tools/cmd/controller-gen object:headerFile=../../hack/boilerplate.go.txt paths=./...
+package v1beta1
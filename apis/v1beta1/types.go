@@ -0,0 +1,645 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+)
+
+// BucketSpec defines the desired state of Bucket
+type BucketSpec struct {
+	ResourceSpec `json:",inline"`
+	ForProvider  BucketParameters `json:"forProvider"`
+}
+
+// BucketParameters are the configurable fields of a Bucket.
+type BucketParameters struct {
+	// GlobalAliases are the global aliases of the bucket (S3 bucket names).
+	// Aliases cannot be changed once set. Only one global alias is supported
+	// for now.
+	// +optional
+	// +listType=set
+	GlobalAliases []string `json:"globalAliases,omitempty"`
+
+	// LocalAliases are aliases of the bucket local to an access key. Aliases
+	// cannot be changed once set. Only one local alias is supported for now.
+	// +optional
+	LocalAliases []LocalAlias `json:"localAliases,omitempty"`
+
+	// Quotas for the bucket
+	// +optional
+	Quotas *BucketQuotas `json:"quotas,omitempty"`
+
+	// Website configures serving the bucket on the Garage web endpoint
+	// +optional
+	Website *BucketWebsite `json:"website,omitempty"`
+}
+
+// BucketWebsite configures serving a bucket as a website
+type BucketWebsite struct {
+	// Enabled serves the bucket on the Garage web endpoint
+	Enabled bool `json:"enabled"`
+	// IndexDocument is served for requests to a directory
+	// +optional
+	IndexDocument *string `json:"indexDocument,omitempty"`
+	// ErrorDocument is served when the requested object does not exist
+	// +optional
+	ErrorDocument *string `json:"errorDocument,omitempty"`
+}
+
+// LocalAlias represents a local alias for a bucket
+type LocalAlias struct {
+	// AccessKeyID is the access key ID to associate the alias with
+	AccessKeyID string `json:"accessKeyId"`
+	// Alias is the local alias name
+	Alias string `json:"alias"`
+}
+
+// BucketQuotas represents quotas for a bucket
+type BucketQuotas struct {
+	// MaxSize is the maximum size in bytes
+	// +optional
+	MaxSize *int64 `json:"maxSize,omitempty"`
+	// MaxObjects is the maximum number of objects
+	// +optional
+	MaxObjects *int64 `json:"maxObjects,omitempty"`
+}
+
+// BucketStatus represents the observed state of a Bucket.
+type BucketStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          BucketObservation `json:"atProvider,omitempty"`
+}
+
+// BucketObservation are the observable fields of a Bucket.
+type BucketObservation struct {
+	// ID is the unique identifier of the bucket
+	ID string `json:"id,omitempty"`
+	// GlobalAliases are the global aliases of the bucket
+	GlobalAliases []string `json:"globalAliases,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="ID",type="string",JSONPath=".status.atProvider.id"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Namespaced,categories={crossplane,managed,garage}
+
+// Bucket is a managed resource that represents a Garage bucket.
+type Bucket struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BucketSpec   `json:"spec"`
+	Status BucketStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BucketList contains a list of Bucket
+type BucketList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Bucket `json:"items"`
+}
+
+// KeySpec defines the desired state of Key
+type KeySpec struct {
+	ResourceSpec `json:",inline"`
+	ForProvider  KeyParameters `json:"forProvider"`
+
+	// ConnectionSecretFormat adds keys in well-known formats to the
+	// connection secret, next to accessKeyId and secretAccessKey
+	// +optional
+	ConnectionSecretFormat *ConnectionSecretFormat `json:"connectionSecretFormat,omitempty"`
+}
+
+// A ConnectionSecretFormatType is a built-in connection secret format.
+// +kubebuilder:validation:Enum=aws-env;aws-credentials-file;rclone;s3cmd
+type ConnectionSecretFormatType string
+
+// Built-in connection secret formats.
+const (
+	// ConnectionSecretFormatAWSEnv adds AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY, AWS_ENDPOINT_URL and AWS_REGION.
+	ConnectionSecretFormatAWSEnv ConnectionSecretFormatType = "aws-env"
+
+	// ConnectionSecretFormatAWSCredentialsFile adds AWS shared "credentials"
+	// and "config" files.
+	ConnectionSecretFormatAWSCredentialsFile ConnectionSecretFormatType = "aws-credentials-file"
+
+	// ConnectionSecretFormatRclone adds an "rclone.conf" with a "garage" remote.
+	ConnectionSecretFormatRclone ConnectionSecretFormatType = "rclone"
+
+	// ConnectionSecretFormatS3cmd adds an s3cmd ".s3cfg".
+	ConnectionSecretFormatS3cmd ConnectionSecretFormatType = "s3cmd"
+)
+
+// ConnectionSecretFormat configures additional keys of a Key's connection secret
+type ConnectionSecretFormat struct {
+	// Formats are built-in formats whose keys are added to the connection secret
+	// +optional
+	Formats []ConnectionSecretFormatType `json:"formats,omitempty"`
+
+	// Templates are Go templates rendered into the connection secret key of
+	// the same name. Templates can use .AccessKeyID, .SecretAccessKey, .Name,
+	// .Endpoint and .Region.
+	// +optional
+	Templates map[string]string `json:"templates,omitempty"`
+}
+
+// KeyParameters are the configurable fields of a Key.
+type KeyParameters struct {
+	// Name is the name of the key. It cannot be changed.
+	Name string `json:"name"`
+
+	// Permissions for the key
+	// +optional
+	Permissions *KeyPermissions `json:"permissions,omitempty"`
+
+	// Expiration is when the key expires. Keys without one never expire.
+	// +optional
+	Expiration *metav1.Time `json:"expiration,omitempty"`
+
+	// BucketAccess grants the key access to buckets. Removing an entry
+	// revokes the access it granted. Buckets that were never listed are left
+	// alone, so grants made by KeyAccess or BucketAccessPolicy resources are
+	// kept. An imported key without bucketAccess is late-initialized with
+	// the access it has.
+	// +optional
+	BucketAccess []KeyBucketAccess `json:"bucketAccess,omitempty"`
+}
+
+// KeyBucketAccess grants a key access to a single bucket
+type KeyBucketAccess struct {
+	// BucketID is the ID of the bucket
+	// +optional
+	BucketID *string `json:"bucketId,omitempty"`
+
+	// BucketIDRef is a reference to a Bucket to retrieve its ID
+	// +optional
+	BucketIDRef *xpv1.Reference `json:"bucketIdRef,omitempty"`
+
+	// BucketIDSelector selects a reference to a Bucket
+	// +optional
+	BucketIDSelector *xpv1.Selector `json:"bucketIdSelector,omitempty"`
+
+	// BucketAlias is the global alias of the bucket
+	// +optional
+	BucketAlias *string `json:"bucketAlias,omitempty"`
+
+	// Permissions for the key on the bucket
+	Permissions KeyAccessPermissions `json:"permissions"`
+}
+
+// KeyPermissions represents global permissions for a key
+type KeyPermissions struct {
+	// CreateBucket allows the key to create buckets
+	CreateBucket bool `json:"createBucket"`
+}
+
+// KeyStatus represents the observed state of a Key.
+type KeyStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          KeyObservation `json:"atProvider,omitempty"`
+}
+
+// KeyObservation are the observable fields of a Key.
+type KeyObservation struct {
+	// AccessKeyID is the access key ID
+	AccessKeyID string `json:"accessKeyId,omitempty"`
+	// Name is the name of the key in Garage
+	Name string `json:"name,omitempty"`
+	// CreationTime is when the key was created
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+	// CreateBucket is true if the key may create buckets
+	CreateBucket bool `json:"createBucket,omitempty"`
+	// Expiration is when the key expires, if ever
+	Expiration *metav1.Time `json:"expiration,omitempty"`
+	// Expired is true if the key has expired
+	Expired bool `json:"expired,omitempty"`
+	// Buckets the key has access to
+	Buckets []KeyBucketObservation `json:"buckets,omitempty"`
+	// ConnectionSecretFormatHash identifies the connection secret format last
+	// published, so that it is only published again when it changes.
+	ConnectionSecretFormatHash string `json:"connectionSecretFormatHash,omitempty"`
+}
+
+// KeyBucketObservation is the observed access of a key to a bucket
+type KeyBucketObservation struct {
+	// ID is the unique identifier of the bucket
+	ID string `json:"id"`
+	// GlobalAliases are the global aliases of the bucket
+	GlobalAliases []string `json:"globalAliases,omitempty"`
+	// LocalAliases are the aliases of the bucket local to the key
+	LocalAliases []string `json:"localAliases,omitempty"`
+	// Permissions of the key on the bucket
+	Permissions KeyAccessPermissions `json:"permissions"`
+	// BucketAccessPermissions are the permissions listed by entries of the
+	// key's bucketAccess, which are revoked when no entry lists them anymore.
+	// Other permissions are left to KeyAccess and BucketAccessPolicy resources.
+	// +optional
+	BucketAccessPermissions *KeyAccessPermissions `json:"bucketAccessPermissions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="ACCESS_KEY_ID",type="string",JSONPath=".status.atProvider.accessKeyId"
+// +kubebuilder:printcolumn:name="EXPIRATION",type="date",JSONPath=".status.atProvider.expiration",priority=1
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Namespaced,categories={crossplane,managed,garage}
+
+// Key is a managed resource that represents a Garage access key.
+type Key struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KeySpec   `json:"spec"`
+	Status KeyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KeyList contains a list of Key
+type KeyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Key `json:"items"`
+}
+
+// KeyAccessSpec defines the desired state of KeyAccess
+type KeyAccessSpec struct {
+	ResourceSpec `json:",inline"`
+	ForProvider  KeyAccessParameters `json:"forProvider"`
+}
+
+// KeyAccessParameters are the configurable fields of a KeyAccess. Exactly one
+// of bucketId, bucketIdRef, clusterBucketIdRef and bucketIdSelector and one of
+// accessKeyId, accessKeyIdRef, clusterAccessKeyIdRef and accessKeyIdSelector
+// must be set, and none of them can be changed.
+type KeyAccessParameters struct {
+	// BucketID is the ID of the bucket
+	// +optional
+	BucketID *string `json:"bucketId,omitempty"`
+
+	// BucketIDRef is a reference to a Bucket to retrieve its ID
+	// +optional
+	BucketIDRef *xpv1.Reference `json:"bucketIdRef,omitempty"`
+
+	// BucketIDSelector selects a reference to a Bucket
+	// +optional
+	BucketIDSelector *xpv1.Selector `json:"bucketIdSelector,omitempty"`
+
+	// ClusterBucketIDRef is a reference to a ClusterBucket to retrieve its ID
+	// +optional
+	ClusterBucketIDRef *xpv1.Reference `json:"clusterBucketIdRef,omitempty"`
+
+	// AccessKeyID is the access key ID
+	// +optional
+	AccessKeyID *string `json:"accessKeyId,omitempty"`
+
+	// AccessKeyIDRef is a reference to a Key to retrieve its access key ID
+	// +optional
+	AccessKeyIDRef *xpv1.Reference `json:"accessKeyIdRef,omitempty"`
+
+	// AccessKeyIDSelector selects a reference to a Key
+	// +optional
+	AccessKeyIDSelector *xpv1.Selector `json:"accessKeyIdSelector,omitempty"`
+
+	// ClusterAccessKeyIDRef is a reference to a ClusterKey to retrieve its
+	// access key ID
+	// +optional
+	ClusterAccessKeyIDRef *xpv1.Reference `json:"clusterAccessKeyIdRef,omitempty"`
+
+	// Permissions for the key on the bucket
+	Permissions KeyAccessPermissions `json:"permissions"`
+}
+
+// KeyAccessPermissions represents permissions for a key on a bucket
+type KeyAccessPermissions struct {
+	// Read permission
+	Read bool `json:"read"`
+	// Write permission
+	Write bool `json:"write"`
+	// Owner permission
+	Owner bool `json:"owner"`
+}
+
+// KeyAccessStatus represents the observed state of a KeyAccess.
+type KeyAccessStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          KeyAccessObservation `json:"atProvider,omitempty"`
+}
+
+// KeyAccessObservation are the observable fields of a KeyAccess.
+type KeyAccessObservation struct {
+	// BucketID is the ID of the bucket
+	BucketID string `json:"bucketId,omitempty"`
+	// AccessKeyID is the access key ID
+	AccessKeyID string `json:"accessKeyId,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="BUCKET",type="string",JSONPath=".status.atProvider.bucketId"
+// +kubebuilder:printcolumn:name="KEY",type="string",JSONPath=".status.atProvider.accessKeyId"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Namespaced,categories={crossplane,managed,garage}
+
+// KeyAccess is a managed resource that represents access permissions for a key on a bucket.
+type KeyAccess struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KeyAccessSpec   `json:"spec"`
+	Status KeyAccessStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KeyAccessList contains a list of KeyAccess
+type KeyAccessList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KeyAccess `json:"items"`
+}
+
+// GetCondition of this Bucket.
+func (mg *Bucket) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// GetDeletionPolicy of this Bucket.
+func (mg *Bucket) GetDeletionPolicy() xpv1.DeletionPolicy {
+	return mg.Spec.DeletionPolicy
+}
+
+// GetManagementPolicies of this Bucket.
+func (mg *Bucket) GetManagementPolicies() xpv1.ManagementPolicies {
+	return mg.Spec.ManagementPolicies
+}
+
+// GetProviderConfigReference of this Bucket.
+func (mg *Bucket) GetProviderConfigReference() *xpv1.Reference {
+	if mg.Spec.ProviderConfigReference == nil {
+		return nil
+	}
+	return &xpv1.Reference{Name: mg.Spec.ProviderConfigReference.Name}
+}
+
+// GetTypedProviderConfigReference of this Bucket, including its kind.
+func (mg *Bucket) GetTypedProviderConfigReference() *v1.ProviderConfigReference {
+	return mg.Spec.ProviderConfigReference
+}
+
+// GetPublishConnectionDetailsTo of this Bucket.
+func (mg *Bucket) GetPublishConnectionDetailsTo() *xpv1.PublishConnectionDetailsTo {
+	return mg.Spec.PublishConnectionDetailsTo
+}
+
+// GetWriteConnectionSecretToReference of this Bucket.
+func (mg *Bucket) GetWriteConnectionSecretToReference() *xpv1.SecretReference {
+	return mg.Spec.WriteConnectionSecretToReference
+}
+
+// SetConditions of this Bucket.
+func (mg *Bucket) SetConditions(c ...xpv1.Condition) {
+	mg.Status.SetConditions(c...)
+}
+
+// SetDeletionPolicy of this Bucket.
+func (mg *Bucket) SetDeletionPolicy(r xpv1.DeletionPolicy) {
+	mg.Spec.DeletionPolicy = r
+}
+
+// SetManagementPolicies of this Bucket.
+func (mg *Bucket) SetManagementPolicies(r xpv1.ManagementPolicies) {
+	mg.Spec.ManagementPolicies = r
+}
+
+// SetProviderConfigReference of this Bucket.
+func (mg *Bucket) SetProviderConfigReference(r *xpv1.Reference) {
+	if r == nil {
+		mg.Spec.ProviderConfigReference = nil
+		return
+	}
+	if mg.Spec.ProviderConfigReference == nil {
+		mg.Spec.ProviderConfigReference = &v1.ProviderConfigReference{Kind: v1.ClusterProviderConfigKind}
+	}
+	mg.Spec.ProviderConfigReference.Name = r.Name
+}
+
+// SetPublishConnectionDetailsTo of this Bucket.
+func (mg *Bucket) SetPublishConnectionDetailsTo(r *xpv1.PublishConnectionDetailsTo) {
+	mg.Spec.PublishConnectionDetailsTo = r
+}
+
+// SetWriteConnectionSecretToReference of this Bucket.
+func (mg *Bucket) SetWriteConnectionSecretToReference(r *xpv1.SecretReference) {
+	mg.Spec.WriteConnectionSecretToReference = r
+}
+
+// GetCondition of this Key.
+func (mg *Key) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// GetDeletionPolicy of this Key.
+func (mg *Key) GetDeletionPolicy() xpv1.DeletionPolicy {
+	return mg.Spec.DeletionPolicy
+}
+
+// GetManagementPolicies of this Key.
+func (mg *Key) GetManagementPolicies() xpv1.ManagementPolicies {
+	return mg.Spec.ManagementPolicies
+}
+
+// GetProviderConfigReference of this Key.
+func (mg *Key) GetProviderConfigReference() *xpv1.Reference {
+	if mg.Spec.ProviderConfigReference == nil {
+		return nil
+	}
+	return &xpv1.Reference{Name: mg.Spec.ProviderConfigReference.Name}
+}
+
+// GetTypedProviderConfigReference of this Key, including its kind.
+func (mg *Key) GetTypedProviderConfigReference() *v1.ProviderConfigReference {
+	return mg.Spec.ProviderConfigReference
+}
+
+// GetPublishConnectionDetailsTo of this Key.
+func (mg *Key) GetPublishConnectionDetailsTo() *xpv1.PublishConnectionDetailsTo {
+	return mg.Spec.PublishConnectionDetailsTo
+}
+
+// GetWriteConnectionSecretToReference of this Key.
+func (mg *Key) GetWriteConnectionSecretToReference() *xpv1.SecretReference {
+	return mg.Spec.WriteConnectionSecretToReference
+}
+
+// SetConditions of this Key.
+func (mg *Key) SetConditions(c ...xpv1.Condition) {
+	mg.Status.SetConditions(c...)
+}
+
+// SetDeletionPolicy of this Key.
+func (mg *Key) SetDeletionPolicy(r xpv1.DeletionPolicy) {
+	mg.Spec.DeletionPolicy = r
+}
+
+// SetManagementPolicies of this Key.
+func (mg *Key) SetManagementPolicies(r xpv1.ManagementPolicies) {
+	mg.Spec.ManagementPolicies = r
+}
+
+// SetProviderConfigReference of this Key.
+func (mg *Key) SetProviderConfigReference(r *xpv1.Reference) {
+	if r == nil {
+		mg.Spec.ProviderConfigReference = nil
+		return
+	}
+	if mg.Spec.ProviderConfigReference == nil {
+		mg.Spec.ProviderConfigReference = &v1.ProviderConfigReference{Kind: v1.ClusterProviderConfigKind}
+	}
+	mg.Spec.ProviderConfigReference.Name = r.Name
+}
+
+// SetPublishConnectionDetailsTo of this Key.
+func (mg *Key) SetPublishConnectionDetailsTo(r *xpv1.PublishConnectionDetailsTo) {
+	mg.Spec.PublishConnectionDetailsTo = r
+}
+
+// SetWriteConnectionSecretToReference of this Key.
+func (mg *Key) SetWriteConnectionSecretToReference(r *xpv1.SecretReference) {
+	mg.Spec.WriteConnectionSecretToReference = r
+}
+
+// GetCondition of this KeyAccess.
+func (mg *KeyAccess) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// GetDeletionPolicy of this KeyAccess.
+func (mg *KeyAccess) GetDeletionPolicy() xpv1.DeletionPolicy {
+	return mg.Spec.DeletionPolicy
+}
+
+// GetManagementPolicies of this KeyAccess.
+func (mg *KeyAccess) GetManagementPolicies() xpv1.ManagementPolicies {
+	return mg.Spec.ManagementPolicies
+}
+
+// GetProviderConfigReference of this KeyAccess.
+func (mg *KeyAccess) GetProviderConfigReference() *xpv1.Reference {
+	if mg.Spec.ProviderConfigReference == nil {
+		return nil
+	}
+	return &xpv1.Reference{Name: mg.Spec.ProviderConfigReference.Name}
+}
+
+// GetTypedProviderConfigReference of this KeyAccess, including its kind.
+func (mg *KeyAccess) GetTypedProviderConfigReference() *v1.ProviderConfigReference {
+	return mg.Spec.ProviderConfigReference
+}
+
+// GetPublishConnectionDetailsTo of this KeyAccess.
+func (mg *KeyAccess) GetPublishConnectionDetailsTo() *xpv1.PublishConnectionDetailsTo {
+	return mg.Spec.PublishConnectionDetailsTo
+}
+
+// GetWriteConnectionSecretToReference of this KeyAccess.
+func (mg *KeyAccess) GetWriteConnectionSecretToReference() *xpv1.SecretReference {
+	return mg.Spec.WriteConnectionSecretToReference
+}
+
+// SetConditions of this KeyAccess.
+func (mg *KeyAccess) SetConditions(c ...xpv1.Condition) {
+	mg.Status.SetConditions(c...)
+}
+
+// SetDeletionPolicy of this KeyAccess.
+func (mg *KeyAccess) SetDeletionPolicy(r xpv1.DeletionPolicy) {
+	mg.Spec.DeletionPolicy = r
+}
+
+// SetManagementPolicies of this KeyAccess.
+func (mg *KeyAccess) SetManagementPolicies(r xpv1.ManagementPolicies) {
+	mg.Spec.ManagementPolicies = r
+}
+
+// SetProviderConfigReference of this KeyAccess.
+func (mg *KeyAccess) SetProviderConfigReference(r *xpv1.Reference) {
+	if r == nil {
+		mg.Spec.ProviderConfigReference = nil
+		return
+	}
+	if mg.Spec.ProviderConfigReference == nil {
+		mg.Spec.ProviderConfigReference = &v1.ProviderConfigReference{Kind: v1.ClusterProviderConfigKind}
+	}
+	mg.Spec.ProviderConfigReference.Name = r.Name
+}
+
+// SetPublishConnectionDetailsTo of this KeyAccess.
+func (mg *KeyAccess) SetPublishConnectionDetailsTo(r *xpv1.PublishConnectionDetailsTo) {
+	mg.Spec.PublishConnectionDetailsTo = r
+}
+
+// SetWriteConnectionSecretToReference of this KeyAccess.
+func (mg *KeyAccess) SetWriteConnectionSecretToReference(r *xpv1.SecretReference) {
+	mg.Spec.WriteConnectionSecretToReference = r
+}
+
+// GroupVersionKind returns the GroupVersionKind for Bucket
+func (mg *Bucket) GroupVersionKind() schema.GroupVersionKind {
+	return GroupVersion.WithKind(BucketKind)
+}
+
+// GroupVersionKind returns the GroupVersionKind for Key
+func (mg *Key) GroupVersionKind() schema.GroupVersionKind {
+	return GroupVersion.WithKind(KeyKind)
+}
+
+// GroupVersionKind returns the GroupVersionKind for KeyAccess
+func (mg *KeyAccess) GroupVersionKind() schema.GroupVersionKind {
+	return GroupVersion.WithKind(KeyAccessKind)
+}
+
+// GetItems of this BucketList.
+func (l *BucketList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
+
+// GetItems of this KeyList.
+func (l *KeyList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
+
+// GetItems of this KeyAccessList.
+func (l *KeyAccessList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
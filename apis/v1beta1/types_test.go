@@ -0,0 +1,77 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+)
+
+var (
+	_ resource.Managed = &Bucket{}
+	_ resource.Managed = &Key{}
+	_ resource.Managed = &KeyAccess{}
+)
+
+// A referencer is a managed resource whose provider config reference has a
+// kind.
+type referencer interface {
+	resource.Managed
+	GetTypedProviderConfigReference() *v1.ProviderConfigReference
+}
+
+func TestSetProviderConfigReference(t *testing.T) {
+	type want struct {
+		typed *v1.ProviderConfigReference
+		ref   *xpv1.Reference
+	}
+
+	cases := map[string]struct {
+		reason string
+		mg     referencer
+		ref    *xpv1.Reference
+		want   want
+	}{
+		"BucketDefaultKind": {
+			reason: "Should reference a ClusterProviderConfig if the Bucket referenced none",
+			mg:     &Bucket{},
+			ref:    &xpv1.Reference{Name: "default"},
+			want: want{
+				typed: &v1.ProviderConfigReference{Name: "default", Kind: v1.ClusterProviderConfigKind},
+				ref:   &xpv1.Reference{Name: "default"},
+			},
+		},
+		"KeyKeepsKind": {
+			reason: "Should keep the kind of the provider config a Key referenced",
+			mg: &Key{Spec: KeySpec{ResourceSpec: ResourceSpec{
+				ProviderConfigReference: &v1.ProviderConfigReference{Name: "old", Kind: v1.ProviderConfigKind},
+			}}},
+			ref: &xpv1.Reference{Name: "team"},
+			want: want{
+				typed: &v1.ProviderConfigReference{Name: "team", Kind: v1.ProviderConfigKind},
+				ref:   &xpv1.Reference{Name: "team"},
+			},
+		},
+		"KeyAccessUnset": {
+			reason: "Should clear the reference of a KeyAccess",
+			mg:     &KeyAccess{Spec: KeyAccessSpec{ResourceSpec: ResourceSpec{ProviderConfigReference: &v1.ProviderConfigReference{Name: "default"}}}},
+			want:   want{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.mg.SetProviderConfigReference(tc.ref)
+			if diff := cmp.Diff(tc.want.typed, tc.mg.GetTypedProviderConfigReference()); diff != "" {
+				t.Errorf("\n%s\nGetTypedProviderConfigReference(): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ref, tc.mg.GetProviderConfigReference()); diff != "" {
+				t.Errorf("\n%s\nGetProviderConfigReference(): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
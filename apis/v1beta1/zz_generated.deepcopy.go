@@ -0,0 +1,722 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright 2024 The provider-garage Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"github.com/crossplane/crossplane-runtime/apis/common/v1"
+	apisv1 "github.com/kikokikok/provider-garage/apis/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Bucket) DeepCopyInto(out *Bucket) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Bucket.
+func (in *Bucket) DeepCopy() *Bucket {
+	if in == nil {
+		return nil
+	}
+	out := new(Bucket)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Bucket) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketList) DeepCopyInto(out *BucketList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Bucket, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketList.
+func (in *BucketList) DeepCopy() *BucketList {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BucketList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketObservation) DeepCopyInto(out *BucketObservation) {
+	*out = *in
+	if in.GlobalAliases != nil {
+		in, out := &in.GlobalAliases, &out.GlobalAliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketObservation.
+func (in *BucketObservation) DeepCopy() *BucketObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketParameters) DeepCopyInto(out *BucketParameters) {
+	*out = *in
+	if in.GlobalAliases != nil {
+		in, out := &in.GlobalAliases, &out.GlobalAliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LocalAliases != nil {
+		in, out := &in.LocalAliases, &out.LocalAliases
+		*out = make([]LocalAlias, len(*in))
+		copy(*out, *in)
+	}
+	if in.Quotas != nil {
+		in, out := &in.Quotas, &out.Quotas
+		*out = new(BucketQuotas)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Website != nil {
+		in, out := &in.Website, &out.Website
+		*out = new(BucketWebsite)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketParameters.
+func (in *BucketParameters) DeepCopy() *BucketParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketQuotas) DeepCopyInto(out *BucketQuotas) {
+	*out = *in
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxObjects != nil {
+		in, out := &in.MaxObjects, &out.MaxObjects
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketQuotas.
+func (in *BucketQuotas) DeepCopy() *BucketQuotas {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketQuotas)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketSpec) DeepCopyInto(out *BucketSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketSpec.
+func (in *BucketSpec) DeepCopy() *BucketSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketStatus) DeepCopyInto(out *BucketStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketStatus.
+func (in *BucketStatus) DeepCopy() *BucketStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketWebsite) DeepCopyInto(out *BucketWebsite) {
+	*out = *in
+	if in.IndexDocument != nil {
+		in, out := &in.IndexDocument, &out.IndexDocument
+		*out = new(string)
+		**out = **in
+	}
+	if in.ErrorDocument != nil {
+		in, out := &in.ErrorDocument, &out.ErrorDocument
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketWebsite.
+func (in *BucketWebsite) DeepCopy() *BucketWebsite {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketWebsite)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionSecretFormat) DeepCopyInto(out *ConnectionSecretFormat) {
+	*out = *in
+	if in.Formats != nil {
+		in, out := &in.Formats, &out.Formats
+		*out = make([]ConnectionSecretFormatType, len(*in))
+		copy(*out, *in)
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionSecretFormat.
+func (in *ConnectionSecretFormat) DeepCopy() *ConnectionSecretFormat {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionSecretFormat)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Key) DeepCopyInto(out *Key) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Key.
+func (in *Key) DeepCopy() *Key {
+	if in == nil {
+		return nil
+	}
+	out := new(Key)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Key) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyAccess) DeepCopyInto(out *KeyAccess) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyAccess.
+func (in *KeyAccess) DeepCopy() *KeyAccess {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyAccess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KeyAccess) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyAccessList) DeepCopyInto(out *KeyAccessList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KeyAccess, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyAccessList.
+func (in *KeyAccessList) DeepCopy() *KeyAccessList {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyAccessList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KeyAccessList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyAccessObservation) DeepCopyInto(out *KeyAccessObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyAccessObservation.
+func (in *KeyAccessObservation) DeepCopy() *KeyAccessObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyAccessObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyAccessParameters) DeepCopyInto(out *KeyAccessParameters) {
+	*out = *in
+	if in.BucketID != nil {
+		in, out := &in.BucketID, &out.BucketID
+		*out = new(string)
+		**out = **in
+	}
+	if in.BucketIDRef != nil {
+		in, out := &in.BucketIDRef, &out.BucketIDRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BucketIDSelector != nil {
+		in, out := &in.BucketIDSelector, &out.BucketIDSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterBucketIDRef != nil {
+		in, out := &in.ClusterBucketIDRef, &out.ClusterBucketIDRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccessKeyID != nil {
+		in, out := &in.AccessKeyID, &out.AccessKeyID
+		*out = new(string)
+		**out = **in
+	}
+	if in.AccessKeyIDRef != nil {
+		in, out := &in.AccessKeyIDRef, &out.AccessKeyIDRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccessKeyIDSelector != nil {
+		in, out := &in.AccessKeyIDSelector, &out.AccessKeyIDSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterAccessKeyIDRef != nil {
+		in, out := &in.ClusterAccessKeyIDRef, &out.ClusterAccessKeyIDRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Permissions = in.Permissions
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyAccessParameters.
+func (in *KeyAccessParameters) DeepCopy() *KeyAccessParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyAccessParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyAccessPermissions) DeepCopyInto(out *KeyAccessPermissions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyAccessPermissions.
+func (in *KeyAccessPermissions) DeepCopy() *KeyAccessPermissions {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyAccessPermissions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyAccessSpec) DeepCopyInto(out *KeyAccessSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyAccessSpec.
+func (in *KeyAccessSpec) DeepCopy() *KeyAccessSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyAccessSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyAccessStatus) DeepCopyInto(out *KeyAccessStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	out.AtProvider = in.AtProvider
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyAccessStatus.
+func (in *KeyAccessStatus) DeepCopy() *KeyAccessStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyAccessStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyBucketAccess) DeepCopyInto(out *KeyBucketAccess) {
+	*out = *in
+	if in.BucketID != nil {
+		in, out := &in.BucketID, &out.BucketID
+		*out = new(string)
+		**out = **in
+	}
+	if in.BucketIDRef != nil {
+		in, out := &in.BucketIDRef, &out.BucketIDRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BucketIDSelector != nil {
+		in, out := &in.BucketIDSelector, &out.BucketIDSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BucketAlias != nil {
+		in, out := &in.BucketAlias, &out.BucketAlias
+		*out = new(string)
+		**out = **in
+	}
+	out.Permissions = in.Permissions
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyBucketAccess.
+func (in *KeyBucketAccess) DeepCopy() *KeyBucketAccess {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyBucketAccess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyBucketObservation) DeepCopyInto(out *KeyBucketObservation) {
+	*out = *in
+	if in.GlobalAliases != nil {
+		in, out := &in.GlobalAliases, &out.GlobalAliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LocalAliases != nil {
+		in, out := &in.LocalAliases, &out.LocalAliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Permissions = in.Permissions
+	if in.BucketAccessPermissions != nil {
+		in, out := &in.BucketAccessPermissions, &out.BucketAccessPermissions
+		*out = new(KeyAccessPermissions)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyBucketObservation.
+func (in *KeyBucketObservation) DeepCopy() *KeyBucketObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyBucketObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyList) DeepCopyInto(out *KeyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Key, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyList.
+func (in *KeyList) DeepCopy() *KeyList {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KeyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyObservation) DeepCopyInto(out *KeyObservation) {
+	*out = *in
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Expiration != nil {
+		in, out := &in.Expiration, &out.Expiration
+		*out = (*in).DeepCopy()
+	}
+	if in.Buckets != nil {
+		in, out := &in.Buckets, &out.Buckets
+		*out = make([]KeyBucketObservation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyObservation.
+func (in *KeyObservation) DeepCopy() *KeyObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyParameters) DeepCopyInto(out *KeyParameters) {
+	*out = *in
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = new(KeyPermissions)
+		**out = **in
+	}
+	if in.Expiration != nil {
+		in, out := &in.Expiration, &out.Expiration
+		*out = (*in).DeepCopy()
+	}
+	if in.BucketAccess != nil {
+		in, out := &in.BucketAccess, &out.BucketAccess
+		*out = make([]KeyBucketAccess, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyParameters.
+func (in *KeyParameters) DeepCopy() *KeyParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyPermissions) DeepCopyInto(out *KeyPermissions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyPermissions.
+func (in *KeyPermissions) DeepCopy() *KeyPermissions {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyPermissions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeySpec) DeepCopyInto(out *KeySpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+	if in.ConnectionSecretFormat != nil {
+		in, out := &in.ConnectionSecretFormat, &out.ConnectionSecretFormat
+		*out = new(ConnectionSecretFormat)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeySpec.
+func (in *KeySpec) DeepCopy() *KeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyStatus) DeepCopyInto(out *KeyStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyStatus.
+func (in *KeyStatus) DeepCopy() *KeyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalAlias) DeepCopyInto(out *LocalAlias) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalAlias.
+func (in *LocalAlias) DeepCopy() *LocalAlias {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalAlias)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSpec) DeepCopyInto(out *ResourceSpec) {
+	*out = *in
+	if in.WriteConnectionSecretToReference != nil {
+		in, out := &in.WriteConnectionSecretToReference, &out.WriteConnectionSecretToReference
+		*out = new(v1.SecretReference)
+		**out = **in
+	}
+	if in.PublishConnectionDetailsTo != nil {
+		in, out := &in.PublishConnectionDetailsTo, &out.PublishConnectionDetailsTo
+		*out = new(v1.PublishConnectionDetailsTo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProviderConfigReference != nil {
+		in, out := &in.ProviderConfigReference, &out.ProviderConfigReference
+		*out = new(apisv1.ProviderConfigReference)
+		**out = **in
+	}
+	if in.ManagementPolicies != nil {
+		in, out := &in.ManagementPolicies, &out.ManagementPolicies
+		*out = make(v1.ManagementPolicies, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSpec.
+func (in *ResourceSpec) DeepCopy() *ResourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
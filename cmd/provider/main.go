@@ -2,34 +2,63 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"os"
 	"path/filepath"
 	"time"
 
 	kingpin "github.com/alecthomas/kingpin/v2"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/certificates"
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/feature"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/statemetrics"
 
 	"github.com/kikokikok/provider-garage/apis"
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/internal/clients"
 	"github.com/kikokikok/provider-garage/internal/controller/bucket"
+	"github.com/kikokikok/provider-garage/internal/controller/bucketaccesspolicy"
+	"github.com/kikokikok/provider-garage/internal/controller/config"
+	"github.com/kikokikok/provider-garage/internal/controller/garagecluster"
 	"github.com/kikokikok/provider-garage/internal/controller/key"
 	"github.com/kikokikok/provider-garage/internal/controller/keyaccess"
+	"github.com/kikokikok/provider-garage/internal/features"
+	"github.com/kikokikok/provider-garage/internal/tracing"
+	garagewebhook "github.com/kikokikok/provider-garage/internal/webhook"
 )
 
 func main() {
 	var (
-		app              = kingpin.New(filepath.Base(os.Args[0]), "Garage Crossplane Provider").DefaultEnvars()
-		debug            = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
-		syncInterval     = app.Flag("sync", "Sync interval for controllers.").Short('s').Default("1h").Duration()
-		pollInterval     = app.Flag("poll", "Poll interval for managed resources.").Default("1m").Duration()
-		leaderElection   = app.Flag("leader-election", "Use leader election for controllers.").Short('l').Default("false").Envar("LEADER_ELECTION").Bool()
-		maxReconcileRate = app.Flag("max-reconcile-rate", "Maximum rate of reconciliation per controller.").Default("10").Int()
+		app                        = kingpin.New(filepath.Base(os.Args[0]), "Garage Crossplane Provider").DefaultEnvars()
+		debug                      = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
+		syncInterval               = app.Flag("sync", "Sync interval for controllers.").Short('s').Default("1h").Duration()
+		pollInterval               = app.Flag("poll", "Poll interval for managed resources.").Default("1m").Duration()
+		pollStateMetric            = app.Flag("poll-state-metric", "Interval at which managed resource state metrics are recorded.").Default("5s").Duration()
+		leaderElection             = app.Flag("leader-election", "Use leader election for controllers.").Short('l').Default("false").Envar("LEADER_ELECTION").Bool()
+		maxReconcileRate           = app.Flag("max-reconcile-rate", "Maximum rate of reconciliation per controller.").Default("10").Int()
+		enableManagementPolicies   = app.Flag("enable-management-policies", "Enable support for management policies, e.g. to observe existing resources without managing them.").Default("false").Envar("ENABLE_MANAGEMENT_POLICIES").Bool()
+		enableExternalSecretStores = app.Flag("enable-external-secret-stores", "Enable support for publishing connection details to External Secret Stores.").Default("false").Envar("ENABLE_EXTERNAL_SECRET_STORES").Bool()
+		essTLSCertsPath            = app.Flag("ess-tls-cert-dir", "Path of the directory holding the ca.crt, tls.crt and tls.key used to connect to External Secret Store plugins.").Envar("ESS_TLS_CERTS_DIR").String()
+		namespace                  = app.Flag("namespace", "Namespace the provider runs in. It holds the ProviderConfigUsages of cluster-scoped resources, and is the default scope of the default StoreConfig.").Default("crossplane-system").Envar("POD_NAMESPACE").String()
+		enableWebhooks             = app.Flag("enable-webhooks", "Serve the validating webhooks of the Garage resources.").Default("true").Envar("ENABLE_WEBHOOKS").Bool()
+		webhookPort                = app.Flag("webhook-port", "Port the webhook server listens on.").Default("9443").Int()
+		tlsServerCertsDir          = app.Flag("tls-server-certs-dir", "Path of the directory holding the tls.crt and tls.key of the webhook server.").Default("/tls/server").Envar("TLS_SERVER_CERTS_DIR").String()
+		enableTracing              = app.Flag("tracing", "Export OpenTelemetry traces, configured by the standard OTEL_* environment variables.").Default("false").Bool()
+		tracingEndpoint            = app.Flag("tracing-endpoint", "OTLP/HTTP endpoint URL to export traces to, overriding OTEL_EXPORTER_OTLP_TRACES_ENDPOINT.").String()
 	)
 	kingpin.MustParse(app.Parse(os.Args[1:]))
 
@@ -37,6 +66,14 @@ func main() {
 	log := logging.NewLogrLogger(zl.WithValues("provider", "garage"))
 	ctrl.SetLogger(zl)
 
+	if *enableTracing {
+		shutdown, err := tracing.Setup(context.Background(), *tracingEndpoint)
+		kingpin.FatalIfError(err, "Cannot setup tracing")
+		defer func() {
+			_ = shutdown(context.Background())
+		}()
+	}
+
 	cfg, err := ctrl.GetConfig()
 	kingpin.FatalIfError(err, "Cannot get API server rest config")
 
@@ -48,22 +85,80 @@ func main() {
 		LeaderElectionID: "crossplane-leader-election-provider-garage",
 		LeaseDuration:    func() *time.Duration { d := 60 * time.Second; return &d }(),
 		RenewDeadline:    func() *time.Duration { d := 50 * time.Second; return &d }(),
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    *webhookPort,
+			CertDir: *tlsServerCertsDir,
+			TLSOpts: []func(*tls.Config){
+				func(c *tls.Config) {
+					c.MinVersion = tls.VersionTLS12
+				},
+			},
+		}),
 	})
 	kingpin.FatalIfError(err, "Cannot create controller manager")
 
 	kingpin.FatalIfError(apis.AddToScheme(mgr.GetScheme()), "Cannot add Garage APIs to scheme")
 
+	mm := managed.NewMRMetricRecorder()
+	sm := statemetrics.NewMRStateMetrics()
+	metrics.Registry.MustRegister(mm, sm)
+
 	o := controller.Options{
 		Logger:                  log,
 		MaxConcurrentReconciles: *maxReconcileRate,
 		PollInterval:            *pollInterval,
 		GlobalRateLimiter:       ratelimiter.NewGlobal(*maxReconcileRate),
 		Features:                &feature.Flags{},
+		MetricOptions: &controller.MetricOptions{
+			PollStateMetricInterval: *pollStateMetric,
+			MRMetrics:               mm,
+			MRStateMetrics:          sm,
+		},
 	}
 
-	kingpin.FatalIfError(bucket.Setup(mgr, o), "Cannot setup Bucket controller")
-	kingpin.FatalIfError(key.Setup(mgr, o), "Cannot setup Key controller")
-	kingpin.FatalIfError(keyaccess.Setup(mgr, o), "Cannot setup KeyAccess controller")
+	if *enableManagementPolicies {
+		o.Features.Enable(feature.EnableBetaManagementPolicies)
+		log.Info("Beta feature enabled", "flag", feature.EnableBetaManagementPolicies)
+	}
+
+	if *enableExternalSecretStores {
+		o.Features.Enable(features.EnableAlphaExternalSecretStores)
+		log.Info("Alpha feature enabled", "flag", features.EnableAlphaExternalSecretStores)
+
+		o.ESSOptions = &controller.ESSOptions{}
+		if *essTLSCertsPath != "" {
+			tCfg, err := certificates.LoadMTLSConfig(filepath.Join(*essTLSCertsPath, "ca.crt"), filepath.Join(*essTLSCertsPath, "tls.crt"), filepath.Join(*essTLSCertsPath, "tls.key"), false)
+			kingpin.FatalIfError(err, "Cannot load TLS certificates for External Secret Stores")
+			o.ESSOptions.TLSConfig = tCfg
+		}
+
+		// Ensure the default StoreConfig exists so that managed resources can
+		// publish to it without further setup.
+		kingpin.FatalIfError(resource.Ignore(kerrors.IsAlreadyExists, mgr.GetClient().Create(context.Background(), &v1alpha1.StoreConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: v1alpha1.StoreConfigSpec{
+				SecretStoreConfig: xpv1.SecretStoreConfig{DefaultScope: *namespace},
+			},
+		})), "Cannot create default StoreConfig")
+	}
+
+	// All controllers share one Connector, and so its cache of Garage clients
+	// and their connections.
+	conn := clients.NewConnector(mgr.GetClient(), clients.WithLogger(log))
+
+	kingpin.FatalIfError(config.Setup(mgr, o, conn), "Cannot setup ProviderConfig controller")
+	kingpin.FatalIfError(bucket.Setup(mgr, o, conn), "Cannot setup Bucket controller")
+	kingpin.FatalIfError(key.Setup(mgr, o, conn), "Cannot setup Key controller")
+	kingpin.FatalIfError(keyaccess.Setup(mgr, o, conn), "Cannot setup KeyAccess controller")
+	kingpin.FatalIfError(bucket.SetupCluster(mgr, o, conn, *namespace), "Cannot setup ClusterBucket controller")
+	kingpin.FatalIfError(key.SetupCluster(mgr, o, conn, *namespace), "Cannot setup ClusterKey controller")
+	kingpin.FatalIfError(keyaccess.SetupCluster(mgr, o, conn, *namespace), "Cannot setup ClusterKeyAccess controller")
+	kingpin.FatalIfError(bucketaccesspolicy.Setup(mgr, o, conn), "Cannot setup BucketAccessPolicy controller")
+	kingpin.FatalIfError(garagecluster.Setup(mgr, o, conn), "Cannot setup GarageCluster controller")
+
+	if *enableWebhooks {
+		kingpin.FatalIfError(garagewebhook.Setup(mgr), "Cannot setup webhooks")
+	}
 
 	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
 }
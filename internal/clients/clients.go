@@ -0,0 +1,148 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+const (
+	errNoProviderConfigRef = "managed resource does not reference a ProviderConfig or ClusterProviderConfig"
+	errGetCPC              = "cannot get ClusterProviderConfig"
+	errGetPC               = "cannot get ProviderConfig"
+	errGetCreds            = "cannot get credentials"
+	errClusterScoped       = "a cluster-scoped managed resource must reference a ClusterProviderConfig"
+)
+
+// ProviderConfigRef returns the ProviderConfig or ClusterProviderConfig the
+// supplied managed resource references. A reference without a kind is to a
+// ClusterProviderConfig.
+func ProviderConfigRef(mg resource.Managed) (v1.ProviderConfigReference, error) {
+	r, ok := mg.(v1alpha1.ProviderConfigReferencer)
+	if !ok || r.GetTypedProviderConfigReference() == nil {
+		return v1.ProviderConfigReference{}, errors.New(errNoProviderConfigRef)
+	}
+	ref := *r.GetTypedProviderConfigReference()
+	if ref.Kind == "" {
+		ref.Kind = v1.ClusterProviderConfigKind
+	}
+	return ref, nil
+}
+
+// GetProviderConfig returns the ProviderConfig or ClusterProviderConfig the
+// supplied managed resource references, and its spec. The credentials of a
+// ProviderConfig are read from the namespace of the managed resource, so
+// cluster-scoped managed resources can only use a ClusterProviderConfig.
+func GetProviderConfig(ctx context.Context, kube client.Client, mg resource.Managed) (metav1.Object, *v1.ProviderConfigSpec, error) {
+	ref, err := ProviderConfigRef(mg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ref.Kind == v1.ProviderConfigKind {
+		if mg.GetNamespace() == "" {
+			return nil, nil, errors.New(errClusterScoped)
+		}
+		pc := &v1.ProviderConfig{}
+		if err := kube.Get(ctx, types.NamespacedName{Namespace: mg.GetNamespace(), Name: ref.Name}, pc); err != nil {
+			return nil, nil, errors.Wrap(err, errGetPC)
+		}
+		return pc, InNamespace(&pc.Spec, mg.GetNamespace()), nil
+	}
+
+	cpc := &v1.ClusterProviderConfig{}
+	if err := kube.Get(ctx, types.NamespacedName{Name: ref.Name}, cpc); err != nil {
+		return nil, nil, errors.Wrap(err, errGetCPC)
+	}
+	return cpc, &cpc.Spec, nil
+}
+
+// InNamespace returns a copy of the supplied spec whose credentials Secret and
+// TLS material are read from the supplied namespace, regardless of the
+// namespace they name.
+func InNamespace(spec *v1.ProviderConfigSpec, namespace string) *v1.ProviderConfigSpec {
+	s := spec.DeepCopy()
+	if s.Credentials.SecretRef != nil {
+		s.Credentials.SecretRef.Namespace = namespace
+	}
+	if t := s.TLS; t != nil {
+		for _, ref := range []*xpv1.SecretKeySelector{t.ClientCertSecretRef, t.ClientKeySecretRef} {
+			if ref != nil {
+				ref.Namespace = namespace
+			}
+		}
+		if t.CABundle != nil && t.CABundle.SecretRef != nil {
+			t.CABundle.SecretRef.Namespace = namespace
+		}
+		if t.CABundle != nil && t.CABundle.ConfigMapRef != nil {
+			t.CABundle.ConfigMapRef.Namespace = namespace
+		}
+	}
+	return s
+}
+
+// NewClient returns a Garage Admin API client configured by the supplied
+// ProviderConfig spec and the credentials it references.
+func NewClient(ctx context.Context, kube client.Client, spec *v1.ProviderConfigSpec, opts ...garage.Option) (*garage.Client, error) {
+	cd := spec.Credentials
+	data, err := resource.CommonCredentialExtractor(ctx, cd.Source, kube, cd.CommonCredentialSelectors)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+
+	creds := struct {
+		Endpoint   string `json:"endpoint"`
+		AdminToken string `json:"adminToken"`
+	}{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &creds); err != nil {
+			return nil, errors.Wrap(err, errGetCreds)
+		}
+	}
+
+	endpoint := creds.Endpoint
+	if spec.Endpoint != nil && *spec.Endpoint != "" {
+		endpoint = *spec.Endpoint
+	}
+	endpoints := spec.Endpoints
+	if endpoint == "" && len(endpoints) > 0 {
+		endpoint, endpoints = endpoints[0], endpoints[1:]
+	}
+
+	opts = append([]garage.Option{garage.WithEndpoints(endpoints...)}, opts...)
+	if spec.EndpointCooldown != nil {
+		opts = append(opts, garage.WithCooldown(spec.EndpointCooldown.Duration))
+	}
+	if spec.Retry != nil {
+		opts = append(opts, garage.WithRetryPolicy(retryPolicy(spec.Retry)))
+	}
+
+	return garage.NewClient(endpoint, creds.AdminToken, opts...), nil
+}
+
+// retryPolicy returns the retry policy configured by the supplied ProviderConfig
+// settings, using the defaults for those that are not set.
+func retryPolicy(rc *v1.RetryConfig) garage.RetryPolicy {
+	p := garage.DefaultRetryPolicy
+	if rc.MaxAttempts != nil {
+		p.MaxAttempts = *rc.MaxAttempts
+	}
+	if rc.BaseDelay != nil {
+		p.BaseDelay = rc.BaseDelay.Duration
+	}
+	if rc.MaxDelay != nil {
+		p.MaxDelay = rc.MaxDelay.Duration
+	}
+	return p
+}
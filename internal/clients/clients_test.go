@@ -0,0 +1,106 @@
+package clients
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+)
+
+func TestGetProviderConfig(t *testing.T) {
+	errBoom := errors.New("boom")
+	endpoint := "http://garage:3903"
+
+	spec := func(ns string) *v1.ProviderConfigSpec {
+		return &v1.ProviderConfigSpec{
+			Endpoint: &endpoint,
+			Credentials: v1.ProviderCredentials{
+				Source: xpv1.CredentialsSourceSecret,
+				CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: &xpv1.SecretKeySelector{
+					SecretReference: xpv1.SecretReference{Namespace: ns, Name: "garage"},
+					Key:             "credentials",
+				}},
+			},
+		}
+	}
+	bucket := func(ref *v1.ProviderConfigReference) *v1alpha1.Bucket {
+		return &v1alpha1.Bucket{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "my-bucket"},
+			Spec:       v1alpha1.BucketSpec{ResourceSpec: v1alpha1.ResourceSpec{ProviderConfigReference: ref}},
+		}
+	}
+
+	type want struct {
+		spec *v1.ProviderConfigSpec
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Client
+		b      *v1alpha1.Bucket
+		want   want
+	}{
+		"ProviderConfig": {
+			reason: "Should get a ProviderConfig from the namespace of the managed resource and read its credentials there",
+			kube: &test.MockClient{MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+				if key.Namespace != "team-a" || key.Name != "team" {
+					return errBoom
+				}
+				obj.(*v1.ProviderConfig).Spec = *spec("elsewhere")
+				return nil
+			}},
+			b:    bucket(&v1.ProviderConfigReference{Name: "team", Kind: v1.ProviderConfigKind}),
+			want: want{spec: spec("team-a")},
+		},
+		"ClusterProviderConfig": {
+			reason: "Should get a ClusterProviderConfig and read its credentials from the namespace it names",
+			kube: &test.MockClient{MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+				if key.Namespace != "" || key.Name != "default" {
+					return errBoom
+				}
+				obj.(*v1.ClusterProviderConfig).Spec = *spec("crossplane-system")
+				return nil
+			}},
+			b:    bucket(&v1.ProviderConfigReference{Name: "default"}),
+			want: want{spec: spec("crossplane-system")},
+		},
+		"ClusterScoped": {
+			reason: "Should return an error if a cluster-scoped managed resource references a ProviderConfig",
+			kube:   &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			b: func() *v1alpha1.Bucket {
+				b := bucket(&v1.ProviderConfigReference{Name: "team", Kind: v1.ProviderConfigKind})
+				b.SetNamespace("")
+				return b
+			}(),
+			want: want{err: errors.New(errClusterScoped)},
+		},
+		"GetError": {
+			reason: "Should return an error if the ProviderConfig cannot be found",
+			kube:   &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			b:      bucket(&v1.ProviderConfigReference{Name: "team", Kind: v1.ProviderConfigKind}),
+			want:   want{err: errors.Wrap(errBoom, errGetPC)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, got, err := GetProviderConfig(context.Background(), tc.kube, tc.b)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetProviderConfig(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.spec, got); diff != "" {
+				t.Errorf("\n%s\nGetProviderConfig(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+const (
+	errTLS = "cannot configure TLS"
+)
+
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	// Every managed resource of a provider config talks to the same few
+	// admin endpoints.
+	t.MaxIdleConnsPerHost = 16
+	return t
+}
+
+// version identifies the state of a provider config, its credentials and TLS
+// material a client was built from. The provider config is identified by its
+// UID and generation rather than its resource version, which changes with
+// every status update, e.g. of its health or user count, and would otherwise
+// throw away the failover state and connections of its client.
+type version struct {
+	uid        types.UID
+	generation int64
+	refs       string
+}
+
+type cached struct {
+	version version
+	client  *garage.Client
+}
+
+// A Connector resolves the provider configs of managed resources into Garage
+// clients. Clients are cached per provider config and rebuilt when the
+// provider config, its credentials Secret or its TLS material changes. A
+// single Connector is meant to be shared by all controllers, so that they
+// share its cache and its HTTP client's connections.
+type Connector struct {
+	kube client.Client
+	http *http.Client
+	opts []garage.Option
+	log  logging.Logger
+
+	mu      sync.Mutex
+	clients map[types.NamespacedName]cached
+}
+
+// A ConnectorOption configures a Connector.
+type ConnectorOption func(*Connector)
+
+// WithLogger specifies how the Connector logs failovers between admin
+// endpoints.
+func WithLogger(l logging.Logger) ConnectorOption {
+	return func(c *Connector) {
+		c.log = l
+	}
+}
+
+// NewConnector returns a Connector that reads provider configs and their
+// credentials with the supplied client.
+func NewConnector(kube client.Client, o ...ConnectorOption) *Connector {
+	c := &Connector{
+		kube:    kube,
+		http:    &http.Client{Transport: newTransport(), Timeout: 30 * time.Second},
+		log:     logging.NewNopLogger(),
+		clients: map[types.NamespacedName]cached{},
+	}
+	c.opts = []garage.Option{garage.WithHTTPClient(c.http)}
+	for _, fn := range o {
+		fn(c)
+	}
+	return c
+}
+
+// Connect returns a Garage client for the provider config the supplied
+// managed resource references, and the spec of that provider config.
+func (c *Connector) Connect(ctx context.Context, mg resource.Managed) (*garage.Client, *v1.ProviderConfigSpec, error) {
+	pc, spec, err := GetProviderConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, nil, err
+	}
+	gc, err := c.Client(ctx, pc, spec)
+	return gc, spec, err
+}
+
+// Client returns a Garage client for the supplied provider config, reusing
+// the one built for it last unless it or its credentials changed since.
+func (c *Connector) Client(ctx context.Context, pc metav1.Object, spec *v1.ProviderConfigSpec) (*garage.Client, error) {
+	v := version{uid: pc.GetUID(), generation: pc.GetGeneration()}
+
+	if s := spec.Credentials.SecretRef; spec.Credentials.Source == xpv1.CredentialsSourceSecret && s != nil {
+		secret := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: s.Namespace, Name: s.Name}, secret); err != nil {
+			return nil, errors.Wrap(err, errGetCreds)
+		}
+		v.refs = secret.GetResourceVersion()
+	}
+
+	var tc *garage.TLSConfig
+	if spec.TLS != nil {
+		var versions []string
+		var err error
+		if tc, versions, err = getTLSConfig(ctx, c.kube, spec.TLS); err != nil {
+			return nil, err
+		}
+		v.refs += "/" + strings.Join(versions, "/")
+	}
+
+	key := types.NamespacedName{Namespace: pc.GetNamespace(), Name: pc.GetName()}
+	c.mu.Lock()
+	e, ok := c.clients[key]
+	c.mu.Unlock()
+	if ok && e.version == v {
+		return e.client, nil
+	}
+
+	opts := append([]garage.Option{}, c.opts...)
+	if tc != nil {
+		t, err := garage.NewTransport(*tc)
+		if err != nil {
+			return nil, errors.Wrap(err, errTLS)
+		}
+		// The shared HTTP client cannot carry per provider config TLS
+		// material, so replace it while keeping the other options.
+		opts = append(opts, garage.WithHTTPClient(&http.Client{Transport: t, Timeout: c.http.Timeout}))
+	}
+	obs := &endpointObserver{log: c.log, config: configName(pc)}
+	opts = append(opts, garage.WithEndpointObserver(obs), garage.WithRequestObserver(&requestObserver{config: obs.config}))
+
+	gc, err := NewClient(ctx, c.kube, spec, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	obs.setActive(gc.ActiveEndpoint())
+
+	c.mu.Lock()
+	c.clients[key] = cached{version: v, client: gc}
+	c.mu.Unlock()
+	return gc, nil
+}
+
+// Evict forgets the client built for the provider config with the supplied
+// namespace and name, if any. It is called once the provider config is gone.
+// A ClusterProviderConfig has no namespace, so it cannot be confused with a
+// ProviderConfig of the same name.
+func (c *Connector) Evict(key types.NamespacedName) {
+	c.mu.Lock()
+	delete(c.clients, key)
+	c.mu.Unlock()
+}
+
+// configName identifies the supplied provider config in logs and metrics.
+func configName(pc metav1.Object) string {
+	if pc.GetNamespace() == "" {
+		return pc.GetName()
+	}
+	return pc.GetNamespace() + "/" + pc.GetName()
+}
@@ -0,0 +1,119 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+func TestConnectorClient(t *testing.T) {
+	secretVersion := "1"
+	kube := &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+		s := obj.(*corev1.Secret)
+		s.ResourceVersion = secretVersion
+		s.Data = map[string][]byte{"credentials": []byte(`{"endpoint":"http://garage:3903","adminToken":"token"}`)}
+		return nil
+	}}
+
+	spec := &v1.ProviderConfigSpec{Credentials: v1.ProviderCredentials{
+		Source: xpv1.CredentialsSourceSecret,
+		CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: &xpv1.SecretKeySelector{
+			SecretReference: xpv1.SecretReference{Namespace: "crossplane-system", Name: "garage"},
+			Key:             "credentials",
+		}},
+	}}
+	pc := &v1.ClusterProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: "default", UID: "uid-1", Generation: 1, ResourceVersion: "1"}}
+
+	c := NewConnector(kube)
+	connect := func() any {
+		gc, err := c.Client(context.Background(), pc, spec)
+		if err != nil {
+			t.Fatalf("Client(...): %v", err)
+		}
+		return gc
+	}
+
+	first := connect()
+	if connect() != first {
+		t.Errorf("Client(...): should reuse the client while nothing changed")
+	}
+
+	secretVersion = "2"
+	second := connect()
+	if second == first {
+		t.Errorf("Client(...): should build a new client when the credentials Secret changed")
+	}
+
+	pc.ResourceVersion = "2"
+	if connect() != second {
+		t.Errorf("Client(...): should reuse the client when only the status of the provider config changed")
+	}
+
+	pc.Generation = 2
+	changed := connect()
+	if changed == second {
+		t.Errorf("Client(...): should build a new client when the spec of the provider config changed")
+	}
+
+	pc.UID, pc.Generation = "uid-2", 1
+	third := connect()
+	if third == changed {
+		t.Errorf("Client(...): should build a new client when the provider config was recreated")
+	}
+
+	c.Evict(types.NamespacedName{Name: pc.GetName()})
+	if connect() == third {
+		t.Errorf("Client(...): should build a new client once the provider config was evicted")
+	}
+}
+
+func TestConnectorClientTLS(t *testing.T) {
+	var agent string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agent = r.UserAgent()
+		_, _ = w.Write([]byte(`{"status":"healthy"}`))
+	}))
+	defer srv.Close()
+
+	kube := &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+		obj.(*corev1.Secret).Data = map[string][]byte{"credentials": []byte(`{"endpoint":"` + srv.URL + `","adminToken":"token"}`)}
+		return nil
+	}}
+	spec := &v1.ProviderConfigSpec{
+		Credentials: v1.ProviderCredentials{
+			Source: xpv1.CredentialsSourceSecret,
+			CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: &xpv1.SecretKeySelector{
+				SecretReference: xpv1.SecretReference{Namespace: "crossplane-system", Name: "garage"},
+				Key:             "credentials",
+			}},
+		},
+		TLS: &v1.TLSConfig{InsecureSkipVerify: true},
+	}
+	pc := &v1.ClusterProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+
+	c := NewConnector(kube, func(c *Connector) {
+		c.opts = append(c.opts, garage.WithUserAgent("test-agent"))
+	})
+	gc, err := c.Client(context.Background(), pc, spec)
+	if err != nil {
+		t.Fatalf("Client(...): %v", err)
+	}
+	if _, err := gc.GetClusterHealth(context.Background()); err != nil {
+		t.Fatalf("GetClusterHealth(...): %v", err)
+	}
+	if agent != "test-agent" {
+		t.Errorf("Client(...): should keep the options of the Connector when configuring TLS, got user agent %q", agent)
+	}
+}
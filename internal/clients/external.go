@@ -0,0 +1,51 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+)
+
+// An ExternalFn returns the external client of the supplied managed resource,
+// given a Garage client and the spec of the provider config it uses.
+type ExternalFn func(mg resource.Managed, gc *garage.Client, pc *v1.ProviderConfigSpec) (managed.ExternalClient, error)
+
+// An ExternalConnecter connects managed resources to Garage. It records that a
+// managed resource uses its provider config before connecting, so that the
+// provider config cannot be deleted while it is in use.
+type ExternalConnecter struct {
+	usage    resource.Tracker
+	clients  *Connector
+	external ExternalFn
+}
+
+// NewExternalConnecter returns an ExternalConnecter that tracks provider config
+// usage with the supplied tracker, gets Garage clients from the supplied
+// Connector and builds external clients with the supplied function.
+func NewExternalConnecter(usage resource.Tracker, clients *Connector, fn ExternalFn) *ExternalConnecter {
+	return &ExternalConnecter{usage: usage, clients: clients, external: fn}
+}
+
+// Connect returns the external client of the supplied managed resource.
+func (c *ExternalConnecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	gc, pc, err := c.clients.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.external(mg, gc, pc)
+}
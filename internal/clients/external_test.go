@@ -0,0 +1,110 @@
+package clients
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+// externalClient is the external client built in tests.
+type externalClient struct {
+	managed.ExternalClient
+	region string
+}
+
+func TestExternalConnecter(t *testing.T) {
+	errBoom := errors.New("boom")
+	region := "garage"
+
+	kube := func(err error) client.Client {
+		return &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			if err != nil {
+				return err
+			}
+			switch o := obj.(type) {
+			case *v1.ClusterProviderConfig:
+				o.Spec = v1.ProviderConfigSpec{
+					Region: &region,
+					Credentials: v1.ProviderCredentials{
+						Source: xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: &xpv1.SecretKeySelector{
+							SecretReference: xpv1.SecretReference{Namespace: "crossplane-system", Name: "garage"},
+							Key:             "credentials",
+						}},
+					},
+				}
+			case *corev1.Secret:
+				o.Data = map[string][]byte{"credentials": []byte(`{"endpoint":"http://garage:3903","adminToken":"token"}`)}
+			}
+			return nil
+		}}
+	}
+	external := func(_ resource.Managed, gc *garage.Client, pc *v1.ProviderConfigSpec) (managed.ExternalClient, error) {
+		if gc == nil {
+			return nil, errBoom
+		}
+		return &externalClient{region: *pc.Region}, nil
+	}
+
+	type want struct {
+		ec  managed.ExternalClient
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		usage  resource.Tracker
+		kube   client.Client
+		want   want
+	}{
+		"TrackError": {
+			reason: "Should return an error if the usage of the provider config cannot be tracked",
+			usage:  resource.TrackerFn(func(context.Context, resource.Managed) error { return errBoom }),
+			kube:   kube(nil),
+			want:   want{err: errors.Wrap(errBoom, errTrackPCUsage)},
+		},
+		"ConnectError": {
+			reason: "Should return an error if the provider config cannot be read",
+			usage:  resource.TrackerFn(func(context.Context, resource.Managed) error { return nil }),
+			kube:   kube(errBoom),
+			want:   want{err: errors.Wrap(errBoom, errGetCPC)},
+		},
+		"Success": {
+			reason: "Should build the external client from the Garage client and provider config",
+			usage:  resource.TrackerFn(func(context.Context, resource.Managed) error { return nil }),
+			kube:   kube(nil),
+			want:   want{ec: &externalClient{region: region}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mg := &v1alpha1.Bucket{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-bucket"},
+				Spec:       v1alpha1.BucketSpec{ResourceSpec: v1alpha1.ResourceSpec{ProviderConfigReference: &v1.ProviderConfigReference{Name: "default"}}},
+			}
+			c := NewExternalConnecter(tc.usage, NewConnector(tc.kube), external)
+			got, err := c.Connect(context.Background(), mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ec, got, cmp.AllowUnexported(externalClient{})); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package clients
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/statemetrics"
+)
+
+const (
+	errStateMetrics = "cannot register managed resource state metrics recorder"
+)
+
+var (
+	activeEndpoint = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "garage_admin_active_endpoint",
+		Help: "Admin API endpoint requests for a provider config are sent to (1 for the active endpoint).",
+	}, []string{"provider_config", "endpoint"})
+
+	endpointEjections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "garage_admin_endpoint_ejections_total",
+		Help: "Number of times an Admin API endpoint failed and was ejected for its cool-down period.",
+	}, []string{"provider_config", "endpoint"})
+
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "garage_admin_requests_total",
+		Help: "Number of Admin API requests by operation and status code.",
+	}, []string{"provider_config", "operation", "code"})
+
+	requestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "garage_admin_request_errors_total",
+		Help: "Number of Admin API requests that failed, by operation and status code.",
+	}, []string{"provider_config", "operation", "code"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "garage_admin_request_duration_seconds",
+		Help:    "Latency of Admin API requests by operation and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"provider_config", "operation", "code"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(activeEndpoint, endpointEjections, requests, requestErrors, requestDuration)
+}
+
+// requestObserver exports metrics of the Admin API requests of a provider
+// config.
+type requestObserver struct {
+	config string
+}
+
+func (o *requestObserver) Observe(operation string, code int, d time.Duration, err error) {
+	c := "none"
+	if code != 0 {
+		c = strconv.Itoa(code)
+	}
+	requests.WithLabelValues(o.config, operation, c).Inc()
+	requestDuration.WithLabelValues(o.config, operation, c).Observe(d.Seconds())
+	if err != nil {
+		requestErrors.WithLabelValues(o.config, operation, c).Inc()
+	}
+}
+
+// endpointObserver logs and exports failovers between the admin endpoints of a
+// provider config.
+type endpointObserver struct {
+	log    logging.Logger
+	config string
+}
+
+func (o *endpointObserver) Ejected(endpoint string, err error) {
+	endpointEjections.WithLabelValues(o.config, endpoint).Inc()
+	o.log.Info("Garage admin endpoint failed, ejecting it", "providerConfig", o.config, "endpoint", endpoint, "error", err)
+}
+
+func (o *endpointObserver) Activated(endpoint string) {
+	o.setActive(endpoint)
+	o.log.Info("Failed over to Garage admin endpoint", "providerConfig", o.config, "endpoint", endpoint)
+}
+
+func (o *endpointObserver) setActive(endpoint string) {
+	activeEndpoint.DeletePartialMatch(prometheus.Labels{"provider_config": o.config})
+	activeEndpoint.WithLabelValues(o.config, endpoint).Set(1)
+}
+
+// MetricRecorders returns the options that make a managed reconciler record
+// metrics of its managed resources, if metrics are enabled. If state metrics
+// are enabled too, it adds a recorder of the state of the managed resources
+// list lists to the supplied manager.
+func MetricRecorders(mgr ctrl.Manager, o controller.Options, list resource.ManagedList) ([]managed.ReconcilerOption, error) {
+	if o.MetricOptions == nil {
+		return nil, nil
+	}
+	if o.MetricOptions.MRStateMetrics != nil {
+		sr := statemetrics.NewMRStateRecorder(mgr.GetClient(), o.Logger, o.MetricOptions.MRStateMetrics, list, o.MetricOptions.PollStateMetricInterval)
+		if err := mgr.Add(sr); err != nil {
+			return nil, errors.Wrap(err, errStateMetrics)
+		}
+	}
+	return []managed.ReconcilerOption{managed.WithMetricRecorder(o.MetricOptions.MRMetrics)}, nil
+}
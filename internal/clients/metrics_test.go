@@ -0,0 +1,101 @@
+package clients
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/statemetrics"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+)
+
+// A fakeManager records the runnables added to it.
+type fakeManager struct {
+	ctrl.Manager
+	added []manager.Runnable
+	err   error
+}
+
+func (m *fakeManager) GetClient() client.Client { return &test.MockClient{} }
+
+func (m *fakeManager) Add(r manager.Runnable) error {
+	m.added = append(m.added, r)
+	return m.err
+}
+
+func TestMetricRecorders(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		opts          int
+		stateRecorder bool
+		err           error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		metrics *controller.MetricOptions
+		addErr  error
+		want    want
+	}{
+		"Disabled": {
+			reason: "Should return no options and add no recorder if metrics are disabled",
+			want:   want{},
+		},
+		"NoStateMetrics": {
+			reason: "Should record managed resource metrics without adding a state recorder if state metrics are disabled",
+			metrics: &controller.MetricOptions{
+				MRMetrics: managed.NewMRMetricRecorder(),
+			},
+			want: want{opts: 1},
+		},
+		"StateMetrics": {
+			reason: "Should record managed resource metrics and add a state recorder to the manager",
+			metrics: &controller.MetricOptions{
+				MRMetrics:      managed.NewMRMetricRecorder(),
+				MRStateMetrics: statemetrics.NewMRStateMetrics(),
+			},
+			want: want{opts: 1, stateRecorder: true},
+		},
+		"AddError": {
+			reason: "Should return an error if the state recorder cannot be added to the manager",
+			metrics: &controller.MetricOptions{
+				MRMetrics:      managed.NewMRMetricRecorder(),
+				MRStateMetrics: statemetrics.NewMRStateMetrics(),
+			},
+			addErr: errBoom,
+			want:   want{stateRecorder: true, err: errors.Wrap(errBoom, errStateMetrics)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mgr := &fakeManager{err: tc.addErr}
+			o := controller.Options{Logger: logging.NewNopLogger(), MetricOptions: tc.metrics}
+
+			opts, err := MetricRecorders(mgr, o, &v1alpha1.BucketList{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nMetricRecorders(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.opts, len(opts)); diff != "" {
+				t.Errorf("\n%s\nMetricRecorders(...): -want options, +got options:\n%s\n", tc.reason, diff)
+			}
+			recorder := len(mgr.added) == 1
+			if recorder {
+				_, recorder = mgr.added[0].(*statemetrics.MRStateRecorder)
+			}
+			if diff := cmp.Diff(tc.want.stateRecorder, recorder); diff != "" {
+				t.Errorf("\n%s\nMetricRecorders(...): -want state recorder, +got state recorder:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+const (
+	errGetCABundle   = "cannot get CA bundle"
+	errGetClientCert = "cannot get client certificate"
+	errGetClientKey  = "cannot get client certificate key"
+	errGetSecret     = "cannot get Secret"
+	errGetConfigMap  = "cannot get ConfigMap"
+	errMissingKey    = "key not found"
+)
+
+// getTLSConfig reads the TLS material the supplied configuration references.
+// It also returns the resource versions of the Secrets and ConfigMaps it was
+// read from.
+func getTLSConfig(ctx context.Context, kube client.Client, t *v1.TLSConfig) (*garage.TLSConfig, []string, error) {
+	c := &garage.TLSConfig{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	var versions []string
+
+	// The webhook requires a CA bundle to name either a Secret or a
+	// ConfigMap. One admitted without it that names neither adds no roots.
+	if b := t.CABundle; b != nil && (b.SecretRef != nil || b.ConfigMapRef != nil) {
+		var data []byte
+		var version string
+		var err error
+		if b.SecretRef != nil {
+			data, version, err = secretKey(ctx, kube, b.SecretRef)
+		} else {
+			data, version, err = configMapKey(ctx, kube, b.ConfigMapRef)
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(err, errGetCABundle)
+		}
+		c.CAData = data
+		versions = append(versions, version)
+	}
+
+	if t.ClientCertSecretRef != nil {
+		data, version, err := secretKey(ctx, kube, t.ClientCertSecretRef)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, errGetClientCert)
+		}
+		c.CertData = data
+		versions = append(versions, version)
+	}
+
+	if t.ClientKeySecretRef != nil {
+		data, version, err := secretKey(ctx, kube, t.ClientKeySecretRef)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, errGetClientKey)
+		}
+		c.KeyData = data
+		versions = append(versions, version)
+	}
+
+	return c, versions, nil
+}
+
+func secretKey(ctx context.Context, kube client.Client, sel *xpv1.SecretKeySelector) ([]byte, string, error) {
+	s := &corev1.Secret{}
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: sel.Namespace, Name: sel.Name}, s); err != nil {
+		return nil, "", errors.Wrap(err, errGetSecret)
+	}
+	data, ok := s.Data[sel.Key]
+	if !ok {
+		return nil, "", errors.Errorf("%s: %s", errMissingKey, sel.Key)
+	}
+	return data, s.GetResourceVersion(), nil
+}
+
+func configMapKey(ctx context.Context, kube client.Client, sel *v1.ConfigMapKeySelector) ([]byte, string, error) {
+	cm := &corev1.ConfigMap{}
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: sel.Namespace, Name: sel.Name}, cm); err != nil {
+		return nil, "", errors.Wrap(err, errGetConfigMap)
+	}
+	data, ok := cm.Data[sel.Key]
+	if !ok {
+		return nil, "", errors.Errorf("%s: %s", errMissingKey, sel.Key)
+	}
+	return []byte(data), cm.GetResourceVersion(), nil
+}
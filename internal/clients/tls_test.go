@@ -0,0 +1,98 @@
+package clients
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+func TestGetTLSConfig(t *testing.T) {
+	kube := &test.MockClient{MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+		switch o := obj.(type) {
+		case *corev1.Secret:
+			o.ResourceVersion = "s1"
+			o.Data = map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key"), "ca.crt": []byte("secret-ca")}
+		case *corev1.ConfigMap:
+			o.ResourceVersion = "c1"
+			o.Data = map[string]string{"ca.crt": "configmap-ca"}
+		}
+		return nil
+	}}
+	sel := func(key string) *xpv1.SecretKeySelector {
+		return &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: "ns", Name: "tls"}, Key: key}
+	}
+
+	type want struct {
+		c        *garage.TLSConfig
+		versions []string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		t      *v1.TLSConfig
+		want   want
+	}{
+		"ConfigMapCAAndClientCertificate": {
+			reason: "Should read the CA bundle from a ConfigMap and the client certificate and key from Secrets",
+			t: &v1.TLSConfig{
+				CABundle:            &v1.CABundleSource{ConfigMapRef: &v1.ConfigMapKeySelector{Namespace: "ns", Name: "ca", Key: "ca.crt"}},
+				ClientCertSecretRef: sel("tls.crt"),
+				ClientKeySecretRef:  sel("tls.key"),
+				ServerName:          "garage.internal",
+			},
+			want: want{
+				c:        &garage.TLSConfig{CAData: []byte("configmap-ca"), CertData: []byte("cert"), KeyData: []byte("key"), ServerName: "garage.internal"},
+				versions: []string{"c1", "s1", "s1"},
+			},
+		},
+		"SecretCA": {
+			reason: "Should read the CA bundle from a Secret",
+			t:      &v1.TLSConfig{CABundle: &v1.CABundleSource{SecretRef: sel("ca.crt")}},
+			want: want{
+				c:        &garage.TLSConfig{CAData: []byte("secret-ca")},
+				versions: []string{"s1"},
+			},
+		},
+		"EmptyCABundle": {
+			reason: "Should trust only the system roots, and read nothing, if the CA bundle names no source",
+			t:      &v1.TLSConfig{CABundle: &v1.CABundleSource{}},
+			want:   want{c: &garage.TLSConfig{}},
+		},
+		"InsecureSkipVerify": {
+			reason: "Should pass through insecureSkipVerify without reading anything",
+			t:      &v1.TLSConfig{InsecureSkipVerify: true},
+			want:   want{c: &garage.TLSConfig{InsecureSkipVerify: true}},
+		},
+		"MissingKey": {
+			reason: "Should return an error if the selected key does not exist",
+			t:      &v1.TLSConfig{ClientCertSecretRef: sel("missing")},
+			want:   want{err: errors.Wrap(errors.Errorf("%s: %s", errMissingKey, "missing"), errGetClientCert)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, versions, err := getTLSConfig(context.Background(), kube, tc.t)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ngetTLSConfig(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, c); diff != "" {
+				t.Errorf("\n%s\ngetTLSConfig(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.versions, versions); diff != "" {
+				t.Errorf("\n%s\ngetTLSConfig(...): -want versions, +got versions:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Package clients contains helpers shared by the controllers that connect to
+// Garage.
+package clients
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+)
+
+const (
+	errApplyPCU = "cannot apply ProviderConfigUsage"
+)
+
+// A UsageTrackerOption configures a usage tracker.
+type UsageTrackerOption func(*usageTracker)
+
+// WithUsageNamespace specifies the namespace in which the
+// ClusterProviderConfigUsages of cluster-scoped managed resources, which have
+// no namespace of their own, are created.
+func WithUsageNamespace(namespace string) UsageTrackerOption {
+	return func(t *usageTracker) {
+		t.namespace = namespace
+	}
+}
+
+type usageTracker struct {
+	namespace string
+}
+
+// NewUsageTracker returns a tracker that records which ProviderConfig or
+// ClusterProviderConfig a managed resource uses, with a ProviderConfigUsage
+// or a ClusterProviderConfigUsage respectively. Each usage is created in
+// the namespace of its managed resource, or for a cluster-scoped managed
+// resource in the namespace given by WithUsageNamespace, where it can be
+// owned, and garbage collected, by it.
+func NewUsageTracker(c client.Client, o ...UsageTrackerOption) resource.Tracker {
+	t := &usageTracker{}
+	for _, fn := range o {
+		fn(t)
+	}
+	a := resource.NewAPIUpdatingApplicator(c)
+	return resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error {
+		ref, err := ProviderConfigRef(mg)
+		if err != nil {
+			return err
+		}
+		gvk := mg.GetObjectKind().GroupVersionKind()
+
+		ns := mg.GetNamespace()
+		if ns == "" {
+			ns = t.namespace
+		}
+
+		var pcu resource.ProviderConfigUsage = &v1.ClusterProviderConfigUsage{}
+		if ref.Kind == v1.ProviderConfigKind {
+			pcu = &v1.ProviderConfigUsage{}
+		}
+		pcu.SetNamespace(ns)
+		pcu.SetName(string(mg.GetUID()))
+		pcu.SetLabels(map[string]string{xpv1.LabelKeyProviderName: ref.Name})
+		pcu.SetOwnerReferences([]metav1.OwnerReference{meta.AsController(meta.TypedReferenceTo(mg, gvk))})
+		pcu.SetProviderConfigReference(xpv1.Reference{Name: ref.Name})
+		pcu.SetResourceReference(xpv1.TypedReference{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Name:       mg.GetName(),
+		})
+
+		err = a.Apply(ctx, pcu,
+			resource.MustBeControllableBy(mg.GetUID()),
+			resource.AllowUpdateIf(func(current, _ runtime.Object) bool {
+				return current.(resource.ProviderConfigUsage).GetProviderConfigReference() != pcu.GetProviderConfigReference()
+			}),
+		)
+		return errors.Wrap(resource.Ignore(resource.IsNotAllowed, err), errApplyPCU)
+	})
+}
@@ -0,0 +1,119 @@
+package clients
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+)
+
+func TestNewUsageTracker(t *testing.T) {
+	bucket := func(ref *v1.ProviderConfigReference) *v1alpha1.Bucket {
+		b := &v1alpha1.Bucket{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "my-bucket", UID: types.UID("uid")},
+			Spec:       v1alpha1.BucketSpec{ResourceSpec: v1alpha1.ResourceSpec{ProviderConfigReference: ref}},
+		}
+		b.SetGroupVersionKind(v1alpha1.BucketGroupVersionKind)
+		return b
+	}
+
+	type want struct {
+		kind      string
+		namespace string
+		labels    map[string]string
+		ref       xpv1.Reference
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		b      *v1alpha1.Bucket
+		want   want
+	}{
+		"ProviderConfig": {
+			reason: "Should record the use of a ProviderConfig in the namespace of the managed resource",
+			b:      bucket(&v1.ProviderConfigReference{Name: "team", Kind: v1.ProviderConfigKind}),
+			want: want{
+				kind:      v1.ProviderConfigUsageKind,
+				namespace: "team-a",
+				labels:    map[string]string{xpv1.LabelKeyProviderName: "team"},
+				ref:       xpv1.Reference{Name: "team"},
+			},
+		},
+		"DefaultKind": {
+			reason: "Should record a reference without a kind as the use of a ClusterProviderConfig",
+			b:      bucket(&v1.ProviderConfigReference{Name: "default"}),
+			want: want{
+				kind:      v1.ClusterProviderConfigUsageKind,
+				namespace: "team-a",
+				labels:    map[string]string{xpv1.LabelKeyProviderName: "default"},
+				ref:       xpv1.Reference{Name: "default"},
+			},
+		},
+		"ClusterScoped": {
+			reason: "Should record the use of a cluster-scoped managed resource in the usage namespace",
+			b: func() *v1alpha1.Bucket {
+				b := bucket(&v1.ProviderConfigReference{Name: "default", Kind: v1.ClusterProviderConfigKind})
+				b.SetNamespace("")
+				return b
+			}(),
+			want: want{
+				kind:      v1.ClusterProviderConfigUsageKind,
+				namespace: "crossplane-system",
+				labels:    map[string]string{xpv1.LabelKeyProviderName: "default"},
+				ref:       xpv1.Reference{Name: "default"},
+			},
+		},
+		"NoReference": {
+			reason: "Should return an error if the managed resource references no provider config",
+			b:      bucket(nil),
+			want:   want{err: errors.New(errNoProviderConfigRef)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got resource.ProviderConfigUsage = &v1.ClusterProviderConfigUsage{}
+			kind := ""
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "uid")),
+				MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+					got = obj.(resource.ProviderConfigUsage)
+					kind = reflect.TypeOf(obj).Elem().Name()
+					return nil
+				},
+			}
+
+			err := NewUsageTracker(kube, WithUsageNamespace("crossplane-system")).Track(context.Background(), tc.b)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nTrack(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.kind, kind); diff != "" {
+				t.Errorf("\n%s\nTrack(...): -want kind, +got kind:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.namespace, got.GetNamespace()); diff != "" {
+				t.Errorf("\n%s\nTrack(...): -want namespace, +got namespace:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.labels, got.GetLabels()); diff != "" {
+				t.Errorf("\n%s\nTrack(...): -want labels, +got labels:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ref, got.GetProviderConfigReference()); diff != "" {
+				t.Errorf("\n%s\nTrack(...): -want reference, +got reference:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -3,103 +3,178 @@ package bucket
 
 import (
 	"context"
-	"encoding/json"
+	"net/url"
+	"slices"
 
 	"github.com/pkg/errors"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	v1 "github.com/kikokikok/provider-garage/apis/v1"
 	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/internal/clients"
+	"github.com/kikokikok/provider-garage/internal/dependency"
+	"github.com/kikokikok/provider-garage/internal/features"
+	"github.com/kikokikok/provider-garage/internal/tracing"
 	"github.com/kikokikok/provider-garage/pkg/garage"
 )
 
 const (
-	errNotBucket    = "managed resource is not a Bucket custom resource"
-	errTrackPCUsage = "cannot track ProviderConfig usage"
-	errGetPC        = "cannot get ProviderConfig"
-	errGetCreds     = "cannot get credentials"
+	errNotBucket    = "managed resource is not a Bucket or ClusterBucket custom resource"
+	errGetBucket    = "cannot get bucket"
 	errCreateBucket = "cannot create bucket"
+	errUpdateBucket = "cannot update bucket"
 	errDeleteBucket = "cannot delete bucket"
+	errDependents   = "cannot determine resources granting access to bucket"
 )
 
-// Setup adds a controller that reconciles Bucket managed resources.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
-	name := managed.ControllerName(v1alpha1.BucketGroupKind)
-
-	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+// Setup adds a controller that reconciles Bucket managed resources, which
+// connect to Garage with the supplied Connector.
+func Setup(mgr ctrl.Manager, o controller.Options, conn *clients.Connector) error {
+	return setup(mgr, o, conn, kind{
+		groupKind: v1alpha1.BucketGroupKind,
+		gvk:       v1alpha1.BucketGroupVersionKind,
+		object:    &v1alpha1.Bucket{},
+		list:      &v1alpha1.BucketList{},
+		usage:     clients.NewUsageTracker(mgr.GetClient()),
+		dependents: []dependents{
+			{object: &v1alpha1.KeyAccess{}, enqueue: dependency.EnqueueDeletingBuckets(mgr.GetClient())},
+			{object: &v1alpha1.BucketAccessPolicy{}, enqueue: dependency.EnqueueDeletingBuckets(mgr.GetClient())},
+			{object: &v1alpha1.Key{}, enqueue: dependency.EnqueueDeletingBuckets(mgr.GetClient())},
+		},
+	})
+}
 
-	r := managed.NewReconciler(mgr,
-		resource.ManagedKind(v1alpha1.BucketGroupVersionKind),
-		managed.WithExternalConnecter(&connector{
-			kube: mgr.GetClient(),
-		}),
-		managed.WithLogger(o.Logger.WithValues("controller", name)),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
-		managed.WithConnectionPublishers(cps...))
+// SetupCluster adds a controller that reconciles ClusterBucket managed
+// resources. They connect to Garage with the supplied Connector, and their
+// ProviderConfigUsages are created in the supplied namespace.
+func SetupCluster(mgr ctrl.Manager, o controller.Options, conn *clients.Connector, namespace string) error {
+	return setup(mgr, o, conn, kind{
+		groupKind: v1alpha1.ClusterBucketGroupKind,
+		gvk:       v1alpha1.ClusterBucketGroupVersionKind,
+		object:    &v1alpha1.ClusterBucket{},
+		list:      &v1alpha1.ClusterBucketList{},
+		usage:     clients.NewUsageTracker(mgr.GetClient(), clients.WithUsageNamespace(namespace)),
+		dependents: []dependents{
+			{object: &v1alpha1.KeyAccess{}, enqueue: dependency.EnqueueDeletingClusterBuckets(mgr.GetClient())},
+			{object: &v1alpha1.ClusterKeyAccess{}, enqueue: dependency.EnqueueDeletingClusterBuckets(mgr.GetClient())},
+			{object: &v1alpha1.BucketAccessPolicy{}, enqueue: dependency.EnqueueDeletingClusterBuckets(mgr.GetClient())},
+			{object: &v1alpha1.Key{}, enqueue: dependency.EnqueueDeletingClusterBuckets(mgr.GetClient())},
+			{object: &v1alpha1.ClusterKey{}, enqueue: dependency.EnqueueDeletingClusterBuckets(mgr.GetClient())},
+		},
+	})
+}
 
-	return ctrl.NewControllerManagedBy(mgr).
-		Named(name).
-		WithOptions(o.ForControllerRuntime()).
-		For(&v1alpha1.Bucket{}).
-		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+// A kind of bucket reconciled by this controller.
+type kind struct {
+	groupKind  string
+	gvk        schema.GroupVersionKind
+	object     client.Object
+	list       resource.ManagedList
+	usage      resource.Tracker
+	dependents []dependents
 }
 
-type connector struct {
-	kube client.Client
+// dependents of a bucket, and how to map them to the bucket they depend on.
+type dependents struct {
+	object  client.Object
+	enqueue handler.MapFunc
 }
 
-func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
-	cr, ok := mg.(*v1alpha1.Bucket)
-	if !ok {
-		return nil, errors.New(errNotBucket)
-	}
+func setup(mgr ctrl.Manager, o controller.Options, conn *clients.Connector, k kind) error {
+	name := managed.ControllerName(k.groupKind)
 
-	pc := &v1.ProviderConfig{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
-		return nil, errors.Wrap(err, errGetPC)
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), v1alpha1.StoreConfigGroupVersionKind, connection.WithTLSConfig(o.ESSOptions.TLSConfig)))
 	}
 
-	cd := pc.Spec.Credentials
-	data, err := resource.CommonCredentialExtractor(ctx, cd.Source, c.kube, cd.CommonCredentialSelectors)
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(k.gvk.Kind, clients.NewExternalConnecter(
+			k.usage,
+			conn,
+			newExternal(mgr.GetClient()),
+		))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...),
+	}
+	if o.Features.Enabled(feature.EnableBetaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+	mo, err := clients.MetricRecorders(mgr, o, k.list)
 	if err != nil {
-		return nil, errors.Wrap(err, errGetCreds)
+		return err
 	}
+	opts = append(opts, mo...)
 
-	creds := struct {
-		Endpoint   string `json:"endpoint"`
-		AdminToken string `json:"adminToken"`
-	}{}
-	if len(data) > 0 {
-		if err := json.Unmarshal(data, &creds); err != nil {
-			return nil, errors.Wrap(err, errGetCreds)
-		}
-	}
+	r := managed.NewReconciler(mgr, resource.ManagedKind(k.gvk), opts...)
 
-	endpoint := creds.Endpoint
-	if pc.Spec.Endpoint != nil && *pc.Spec.Endpoint != "" {
-		endpoint = *pc.Spec.Endpoint
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(k.object)
+	for _, d := range k.dependents {
+		b = b.Watches(d.object, handler.EnqueueRequestsFromMapFunc(d.enqueue))
 	}
+	return b.Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
 
-	garageClient := garage.NewClient(endpoint, creds.AdminToken)
+// asBucket returns the supplied Bucket or ClusterBucket as a Bucket. A
+// ClusterBucket is converted in place, so changes to the returned Bucket are
+// made to it.
+func asBucket(mg resource.Managed) (*v1alpha1.Bucket, bool) {
+	switch cr := mg.(type) {
+	case *v1alpha1.Bucket:
+		return cr, true
+	case *v1alpha1.ClusterBucket:
+		return (*v1alpha1.Bucket)(cr), true
+	}
+	return nil, false
+}
 
-	return &external{client: garageClient}, nil
+// newExternal returns a function that builds the external client of a bucket.
+func newExternal(kube client.Client) clients.ExternalFn {
+	return func(_ resource.Managed, gc *garage.Client, pc *v1.ProviderConfigSpec) (managed.ExternalClient, error) {
+		ext := &external{client: gc, kube: kube}
+		if pc.S3Endpoint != nil {
+			ext.s3Endpoint = *pc.S3Endpoint
+		}
+		if pc.Region != nil {
+			ext.region = *pc.Region
+		}
+		if pc.WebEndpoint != nil {
+			ext.webEndpoint = *pc.WebEndpoint
+		}
+		return ext, nil
+	}
 }
 
 type external struct {
 	client *garage.Client
+	kube   client.Client
+
+	// s3Endpoint, region and webEndpoint are published in connection details.
+	s3Endpoint  string
+	region      string
+	webEndpoint string
 }
 
 func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
-	cr, ok := mg.(*v1alpha1.Bucket)
+	cr, ok := asBucket(mg)
 	if !ok {
 		return managed.ExternalObservation{}, errors.New(errNotBucket)
 	}
@@ -110,20 +185,33 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	// Try to find by ID first
 	if cr.Status.AtProvider.ID != "" {
 		bucket, err = e.client.GetBucket(ctx, cr.Status.AtProvider.ID)
+		if err != nil && !garage.IsNotFound(err) {
+			return managed.ExternalObservation{}, errors.Wrap(err, errGetBucket)
+		}
 		if err != nil {
 			// Bucket doesn't exist by ID, clear the ID and try by alias
 			cr.Status.AtProvider.ID = ""
 		}
 	}
 
+	// An external name other than the default (the resource's name) is the ID
+	// of an existing bucket to import, e.g. with the Observe management policy.
+	// Only a bucket that does not exist may be created; any other error must
+	// not be mistaken for it, or a duplicate bucket would be created.
+	if bucket == nil {
+		if en := meta.GetExternalName(cr); en != "" && en != cr.Name {
+			bucket, err = e.client.GetBucket(ctx, en)
+			if err != nil && !garage.IsNotFound(err) {
+				return managed.ExternalObservation{}, errors.Wrap(err, errGetBucket)
+			}
+		}
+	}
+
 	// If no ID or ID lookup failed, try by globalAlias
 	if bucket == nil && cr.Spec.ForProvider.GlobalAlias != nil && *cr.Spec.ForProvider.GlobalAlias != "" {
 		bucket, err = e.client.GetBucketByAlias(ctx, *cr.Spec.ForProvider.GlobalAlias)
-		if err != nil {
-			// Bucket doesn't exist
-			return managed.ExternalObservation{
-				ResourceExists: false,
-			}, nil
+		if err != nil && !garage.IsNotFound(err) {
+			return managed.ExternalObservation{}, errors.Wrap(err, errGetBucket)
 		}
 	}
 
@@ -138,13 +226,100 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	cr.SetConditions(xpv1.Available())
 
 	return managed.ExternalObservation{
-		ResourceExists:   true,
-		ResourceUpToDate: true,
+		ResourceExists:          true,
+		ResourceUpToDate:        bucketUpdate(cr.Spec.ForProvider, bucket) == nil,
+		ResourceLateInitialized: lateInitialize(&cr.Spec.ForProvider, bucket),
+		ConnectionDetails:       e.connectionDetails(cr, bucket),
 	}, nil
 }
 
+// lateInitialize fills the unset parameters of a bucket from the observed
+// bucket, so that the spec of an adopted bucket records its actual settings.
+// It returns true if any parameter was filled.
+func lateInitialize(p *v1alpha1.BucketParameters, b *garage.Bucket) bool {
+	li := false
+	if p.GlobalAlias == nil && len(b.GlobalAliases) > 0 {
+		alias := b.GlobalAliases[0]
+		p.GlobalAlias = &alias
+		li = true
+	}
+	if p.LocalAlias == nil && len(b.LocalAliases) > 0 {
+		// A bucket may have a local alias for several keys, but only one
+		// can be recorded; pick the same one every time.
+		ids := make([]string, 0, len(b.LocalAliases))
+		for id := range b.LocalAliases {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+		p.LocalAlias = &v1alpha1.LocalAlias{AccessKeyID: ids[0], Alias: b.LocalAliases[ids[0]]}
+		li = true
+	}
+	if p.Quotas == nil && b.Quotas != nil && (b.Quotas.MaxSize != nil || b.Quotas.MaxObjects != nil) {
+		p.Quotas = &v1alpha1.BucketQuotas{MaxSize: b.Quotas.MaxSize, MaxObjects: b.Quotas.MaxObjects}
+		li = true
+	}
+	if p.Website == nil && b.WebsiteAccess {
+		p.Website = &v1alpha1.BucketWebsite{Enabled: true}
+		if c := b.WebsiteConfig; c != nil {
+			if c.IndexDocument != "" {
+				index := c.IndexDocument
+				p.Website.IndexDocument = &index
+			}
+			p.Website.ErrorDocument = c.ErrorDocument
+		}
+		li = true
+	}
+	return li
+}
+
+// bucketUpdate returns the request that makes the quotas and website access
+// of the observed bucket match the supplied parameters, or nil if they do.
+// Unset parameters are left as they are.
+func bucketUpdate(p v1alpha1.BucketParameters, b *garage.Bucket) *garage.UpdateBucketRequest {
+	req := &garage.UpdateBucketRequest{ID: b.ID}
+	if q := p.Quotas; q != nil {
+		current := b.Quotas
+		if current == nil {
+			current = &garage.BucketQuotas{}
+		}
+		if !ptr.Equal(q.MaxSize, current.MaxSize) || !ptr.Equal(q.MaxObjects, current.MaxObjects) {
+			req.Quotas = &garage.BucketQuotas{MaxSize: q.MaxSize, MaxObjects: q.MaxObjects}
+		}
+	}
+	if w := p.Website; w != nil && !websiteUpToDate(w, b) {
+		req.WebsiteAccess = &garage.WebsiteAccess{Enabled: w.Enabled}
+		if w.Enabled {
+			req.WebsiteAccess.IndexDocument = w.IndexDocument
+			req.WebsiteAccess.ErrorDocument = w.ErrorDocument
+		}
+	}
+	if req.Quotas == nil && req.WebsiteAccess == nil {
+		return nil
+	}
+	return req
+}
+
+// websiteUpToDate returns true if the observed bucket is served as the
+// supplied website. Documents that are not set are left to Garage.
+func websiteUpToDate(w *v1alpha1.BucketWebsite, b *garage.Bucket) bool {
+	if w.Enabled != b.WebsiteAccess {
+		return false
+	}
+	if !w.Enabled {
+		return true
+	}
+	c := b.WebsiteConfig
+	if c == nil {
+		c = &garage.WebsiteConfig{}
+	}
+	if w.IndexDocument != nil && *w.IndexDocument != c.IndexDocument {
+		return false
+	}
+	return w.ErrorDocument == nil || ptr.Equal(w.ErrorDocument, c.ErrorDocument)
+}
+
 func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
-	cr, ok := mg.(*v1alpha1.Bucket)
+	cr, ok := asBucket(mg)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotBucket)
 	}
@@ -164,15 +339,32 @@ func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 	cr.Status.AtProvider.ID = bucket.ID
 	cr.Status.AtProvider.GlobalAliases = bucket.GlobalAliases
 
-	return managed.ExternalCreation{}, nil
+	return managed.ExternalCreation{ConnectionDetails: e.connectionDetails(cr, bucket)}, nil
 }
 
 func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
-	return managed.ExternalUpdate{}, nil
+	cr, ok := asBucket(mg)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotBucket)
+	}
+
+	// Aliases cannot be changed once set, so only quotas and website access
+	// are updated.
+	bucket, err := e.client.GetBucket(ctx, cr.Status.AtProvider.ID)
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errGetBucket)
+	}
+
+	req := bucketUpdate(cr.Spec.ForProvider, bucket)
+	if req == nil {
+		return managed.ExternalUpdate{}, nil
+	}
+	_, err = e.client.UpdateBucket(ctx, req)
+	return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateBucket)
 }
 
 func (e *external) Delete(ctx context.Context, mg resource.Managed) (managed.ExternalDelete, error) {
-	cr, ok := mg.(*v1alpha1.Bucket)
+	cr, ok := asBucket(mg)
 	if !ok {
 		return managed.ExternalDelete{}, errors.New(errNotBucket)
 	}
@@ -183,9 +375,74 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalDelete{}, nil
 	}
 
+	// Deleting a bucket would leave any KeyAccess, BucketAccessPolicy or Key
+	// granting access to it unable to revoke, so hold off until they are gone
+	// unless forced.
+	if !v1alpha1.ForceDelete(cr) {
+		deps, err := dependency.BucketDependents(ctx, e.kube, cr)
+		if err != nil {
+			return managed.ExternalDelete{}, errors.Wrap(err, errDependents)
+		}
+		if len(deps) > 0 {
+			msg := dependency.Message(kindOf(cr), deps)
+			cr.SetConditions(v1alpha1.DeletionBlocked(msg))
+			return managed.ExternalDelete{}, errors.New(msg)
+		}
+	}
+
 	return managed.ExternalDelete{}, errors.Wrap(e.client.DeleteBucket(ctx, cr.Status.AtProvider.ID), errDeleteBucket)
 }
 
+// kindOf returns the kind of the supplied bucket, which is a ClusterBucket if
+// it has no namespace.
+func kindOf(cr *v1alpha1.Bucket) string {
+	if cr.GetNamespace() == "" {
+		return v1alpha1.ClusterBucketKind
+	}
+	return v1alpha1.BucketKind
+}
+
 func (e *external) Disconnect(ctx context.Context) error {
 	return nil
 }
+
+// connectionDetails returns where a bucket can be reached: its ID, global
+// alias, S3 endpoint and region, and website endpoint when website access is
+// enabled. Values that are not known are omitted.
+func (e *external) connectionDetails(cr *v1alpha1.Bucket, b *garage.Bucket) managed.ConnectionDetails {
+	cd := managed.ConnectionDetails{
+		"bucketId": []byte(b.ID),
+	}
+
+	alias := ""
+	if cr.Spec.ForProvider.GlobalAlias != nil && *cr.Spec.ForProvider.GlobalAlias != "" {
+		alias = *cr.Spec.ForProvider.GlobalAlias
+	} else if len(b.GlobalAliases) > 0 {
+		alias = b.GlobalAliases[0]
+	}
+	if alias != "" {
+		cd["globalAlias"] = []byte(alias)
+	}
+	if e.s3Endpoint != "" {
+		cd["endpoint"] = []byte(e.s3Endpoint)
+	}
+	if e.region != "" {
+		cd["region"] = []byte(e.region)
+	}
+	if b.WebsiteAccess && alias != "" && e.webEndpoint != "" {
+		cd["websiteEndpoint"] = []byte(websiteEndpoint(e.webEndpoint, alias))
+	}
+	return cd
+}
+
+// websiteEndpoint returns the URL Garage serves the website of a bucket at,
+// which is its alias as a subdomain of the web root domain.
+func websiteEndpoint(root, alias string) string {
+	u, err := url.Parse(root)
+	if err != nil || u.Host == "" {
+		u = &url.URL{Scheme: "http", Host: root}
+	}
+	u.Host = alias + "." + u.Host
+	u.Path = ""
+	return u.String()
+}
@@ -2,14 +2,24 @@ package bucket
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/kikokikok/provider-garage/apis/v1alpha1"
@@ -429,3 +439,523 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestConnectionDetails(t *testing.T) {
+	alias := "my-site"
+
+	cases := map[string]struct {
+		reason string
+		e      *external
+		cr     *v1alpha1.Bucket
+		b      *garage.Bucket
+		want   managed.ConnectionDetails
+	}{
+		"IDOnly": {
+			reason: "Should publish only the bucket ID when nothing else is known",
+			e:      &external{},
+			cr:     &v1alpha1.Bucket{},
+			b:      &garage.Bucket{ID: "bucket-123"},
+			want:   managed.ConnectionDetails{"bucketId": []byte("bucket-123")},
+		},
+		"Everything": {
+			reason: "Should publish the alias, S3 endpoint, region and website endpoint",
+			e:      &external{s3Endpoint: "https://s3.example.com", region: "garage", webEndpoint: "https://web.example.com"},
+			cr:     &v1alpha1.Bucket{Spec: v1alpha1.BucketSpec{ForProvider: v1alpha1.BucketParameters{GlobalAlias: &alias}}},
+			b:      &garage.Bucket{ID: "bucket-123", GlobalAliases: []string{"my-site"}, WebsiteAccess: true},
+			want: managed.ConnectionDetails{
+				"bucketId":        []byte("bucket-123"),
+				"globalAlias":     []byte("my-site"),
+				"endpoint":        []byte("https://s3.example.com"),
+				"region":          []byte("garage"),
+				"websiteEndpoint": []byte("https://my-site.web.example.com"),
+			},
+		},
+		"WebsiteDisabled": {
+			reason: "Should not publish a website endpoint when website access is disabled",
+			e:      &external{webEndpoint: "web.example.com"},
+			cr:     &v1alpha1.Bucket{},
+			b:      &garage.Bucket{ID: "bucket-123", GlobalAliases: []string{"observed"}},
+			want: managed.ConnectionDetails{
+				"bucketId":    []byte("bucket-123"),
+				"globalAlias": []byte("observed"),
+			},
+		},
+		"BareWebRootDomain": {
+			reason: "Should default to http when the web endpoint has no scheme",
+			e:      &external{webEndpoint: "web.example.com"},
+			cr:     &v1alpha1.Bucket{},
+			b:      &garage.Bucket{ID: "bucket-123", GlobalAliases: []string{"observed"}, WebsiteAccess: true},
+			want: managed.ConnectionDetails{
+				"bucketId":        []byte("bucket-123"),
+				"globalAlias":     []byte("observed"),
+				"websiteEndpoint": []byte("http://observed.web.example.com"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.e.connectionDetails(tc.cr, tc.b)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nconnectionDetails(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLateInitialize(t *testing.T) {
+	alias := "my-site"
+	other := "other"
+	index := "index.html"
+	errorDoc := "404.html"
+	maxSize := int64(1 << 30)
+
+	type want struct {
+		p  v1alpha1.BucketParameters
+		li bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.BucketParameters
+		b      *garage.Bucket
+		want   want
+	}{
+		"NothingObserved": {
+			reason: "Should not late-initialize anything when the bucket has no settings",
+			b:      &garage.Bucket{ID: "bucket-123"},
+			want:   want{},
+		},
+		"FillUnset": {
+			reason: "Should fill the aliases, quotas and website configuration from the observed bucket",
+			b: &garage.Bucket{
+				ID:            "bucket-123",
+				GlobalAliases: []string{"my-site", "other"},
+				LocalAliases:  map[string]string{"GKb": "other", "GKa": "my-site"},
+				Quotas:        &garage.BucketQuotas{MaxSize: &maxSize},
+				WebsiteAccess: true,
+				WebsiteConfig: &garage.WebsiteConfig{IndexDocument: index, ErrorDocument: &errorDoc},
+			},
+			want: want{
+				p: v1alpha1.BucketParameters{
+					GlobalAlias: &alias,
+					LocalAlias:  &v1alpha1.LocalAlias{AccessKeyID: "GKa", Alias: alias},
+					Quotas:      &v1alpha1.BucketQuotas{MaxSize: &maxSize},
+					Website:     &v1alpha1.BucketWebsite{Enabled: true, IndexDocument: &index, ErrorDocument: &errorDoc},
+				},
+				li: true,
+			},
+		},
+		"KeepSet": {
+			reason: "Should not overwrite parameters that are already set",
+			p: v1alpha1.BucketParameters{
+				GlobalAlias: &other,
+				LocalAlias:  &v1alpha1.LocalAlias{AccessKeyID: "GKb", Alias: other},
+				Quotas:      &v1alpha1.BucketQuotas{},
+				Website:     &v1alpha1.BucketWebsite{},
+			},
+			b: &garage.Bucket{
+				ID:            "bucket-123",
+				GlobalAliases: []string{"my-site"},
+				LocalAliases:  map[string]string{"GKa": "my-site"},
+				Quotas:        &garage.BucketQuotas{MaxSize: &maxSize},
+				WebsiteAccess: true,
+			},
+			want: want{
+				p: v1alpha1.BucketParameters{
+					GlobalAlias: &other,
+					LocalAlias:  &v1alpha1.LocalAlias{AccessKeyID: "GKb", Alias: other},
+					Quotas:      &v1alpha1.BucketQuotas{},
+					Website:     &v1alpha1.BucketWebsite{},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			li := lateInitialize(&tc.p, tc.b)
+			if diff := cmp.Diff(tc.want.li, li); diff != "" {
+				t.Errorf("\n%s\nlateInitialize(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.p, tc.p); diff != "" {
+				t.Errorf("\n%s\nlateInitialize(...): -want parameters, +got parameters:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBucketUpdate(t *testing.T) {
+	index := "index.html"
+	home := "home.html"
+	errorDoc := "404.html"
+	maxSize := int64(1 << 30)
+
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.BucketParameters
+		b      *garage.Bucket
+		want   *garage.UpdateBucketRequest
+	}{
+		"Unset": {
+			reason: "Should leave the bucket alone if neither quotas nor website are set",
+			b:      &garage.Bucket{ID: "bucket-123", Quotas: &garage.BucketQuotas{MaxSize: &maxSize}, WebsiteAccess: true},
+		},
+		"UpToDate": {
+			reason: "Should not update a bucket whose quotas and website match the parameters",
+			p: v1alpha1.BucketParameters{
+				Quotas:  &v1alpha1.BucketQuotas{MaxSize: &maxSize},
+				Website: &v1alpha1.BucketWebsite{Enabled: true, IndexDocument: &index},
+			},
+			b: &garage.Bucket{
+				ID:            "bucket-123",
+				Quotas:        &garage.BucketQuotas{MaxSize: &maxSize},
+				WebsiteAccess: true,
+				WebsiteConfig: &garage.WebsiteConfig{IndexDocument: index, ErrorDocument: &errorDoc},
+			},
+		},
+		"QuotasChanged": {
+			reason: "Should set the quotas of the parameters",
+			p:      v1alpha1.BucketParameters{Quotas: &v1alpha1.BucketQuotas{MaxSize: &maxSize}},
+			b:      &garage.Bucket{ID: "bucket-123"},
+			want:   &garage.UpdateBucketRequest{ID: "bucket-123", Quotas: &garage.BucketQuotas{MaxSize: &maxSize}},
+		},
+		"QuotasRemoved": {
+			reason: "Should remove quotas that are not in the parameters",
+			p:      v1alpha1.BucketParameters{Quotas: &v1alpha1.BucketQuotas{}},
+			b:      &garage.Bucket{ID: "bucket-123", Quotas: &garage.BucketQuotas{MaxSize: &maxSize}},
+			want:   &garage.UpdateBucketRequest{ID: "bucket-123", Quotas: &garage.BucketQuotas{}},
+		},
+		"WebsiteEnabled": {
+			reason: "Should enable website access with the documents of the parameters",
+			p:      v1alpha1.BucketParameters{Website: &v1alpha1.BucketWebsite{Enabled: true, IndexDocument: &index, ErrorDocument: &errorDoc}},
+			b:      &garage.Bucket{ID: "bucket-123"},
+			want: &garage.UpdateBucketRequest{ID: "bucket-123", WebsiteAccess: &garage.WebsiteAccess{
+				Enabled: true, IndexDocument: &index, ErrorDocument: &errorDoc,
+			}},
+		},
+		"WebsiteDocumentChanged": {
+			reason: "Should update the documents of a website",
+			p:      v1alpha1.BucketParameters{Website: &v1alpha1.BucketWebsite{Enabled: true, IndexDocument: &home}},
+			b:      &garage.Bucket{ID: "bucket-123", WebsiteAccess: true, WebsiteConfig: &garage.WebsiteConfig{IndexDocument: index}},
+			want:   &garage.UpdateBucketRequest{ID: "bucket-123", WebsiteAccess: &garage.WebsiteAccess{Enabled: true, IndexDocument: &home}},
+		},
+		"WebsiteDisabled": {
+			reason: "Should disable website access",
+			p:      v1alpha1.BucketParameters{Website: &v1alpha1.BucketWebsite{IndexDocument: &home}},
+			b:      &garage.Bucket{ID: "bucket-123", WebsiteAccess: true, WebsiteConfig: &garage.WebsiteConfig{IndexDocument: index}},
+			want:   &garage.UpdateBucketRequest{ID: "bucket-123", WebsiteAccess: &garage.WebsiteAccess{}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := bucketUpdate(tc.p, tc.b)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nbucketUpdate(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	maxSize := int64(1 << 30)
+
+	type want struct {
+		req *garage.UpdateBucketRequest
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		status int
+		want   want
+	}{
+		"Updated": {
+			reason: "Should send the quotas and website access of the spec to Garage",
+			status: http.StatusOK,
+			want: want{req: &garage.UpdateBucketRequest{
+				ID:            "bucket-123",
+				Quotas:        &garage.BucketQuotas{MaxSize: &maxSize},
+				WebsiteAccess: &garage.WebsiteAccess{Enabled: true},
+			}},
+		},
+		"UpdateFailed": {
+			reason: "Should return an error if the bucket cannot be updated",
+			status: http.StatusInternalServerError,
+			want: want{
+				req: &garage.UpdateBucketRequest{
+					ID:            "bucket-123",
+					Quotas:        &garage.BucketQuotas{MaxSize: &maxSize},
+					WebsiteAccess: &garage.WebsiteAccess{Enabled: true},
+				},
+				err: errors.Wrap(&garage.APIError{StatusCode: http.StatusInternalServerError}, errUpdateBucket),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got *garage.UpdateBucketRequest
+			gc := newGarage(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					_ = json.NewEncoder(w).Encode(garage.Bucket{ID: "bucket-123"})
+					return
+				}
+				got = &garage.UpdateBucketRequest{}
+				_ = json.NewDecoder(r.Body).Decode(got)
+				if tc.status != http.StatusOK {
+					w.WriteHeader(tc.status)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(garage.Bucket{ID: "bucket-123"})
+			})
+			cr := &v1alpha1.Bucket{
+				Spec: v1alpha1.BucketSpec{ForProvider: v1alpha1.BucketParameters{
+					Quotas:  &v1alpha1.BucketQuotas{MaxSize: &maxSize},
+					Website: &v1alpha1.BucketWebsite{Enabled: true},
+				}},
+				Status: v1alpha1.BucketStatus{AtProvider: v1alpha1.BucketObservation{ID: "bucket-123"}},
+			}
+
+			e := &external{client: gc}
+			_, err := e.Update(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.req, got); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want request, +got request:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// newGarage returns a client of a Garage Admin API served by the supplied
+// handler. Requests are not retried.
+func newGarage(t *testing.T, h http.HandlerFunc) *garage.Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return garage.NewClient(srv.URL, "token", garage.WithRetryPolicy(garage.RetryPolicy{MaxAttempts: 1}))
+}
+
+func TestObserveImport(t *testing.T) {
+	type want struct {
+		o   managed.ExternalObservation
+		id  string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		want    want
+	}{
+		"Imported": {
+			reason: "Should observe the bucket whose ID is the external name",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("id") != "bucket-123" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(garage.Bucket{ID: "bucket-123"})
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  true,
+					ConnectionDetails: managed.ConnectionDetails{"bucketId": []byte("bucket-123")},
+				},
+				id: "bucket-123",
+			},
+		},
+		"NotFound": {
+			reason: "Should report that the bucket does not exist if Garage does not know the external name",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			want: want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"LookupFailed": {
+			reason: "Should return an error rather than report that the bucket does not exist if the lookup failed",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			want: want{err: errors.Wrap(&garage.APIError{StatusCode: http.StatusInternalServerError}, errGetBucket)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Bucket{ObjectMeta: metav1.ObjectMeta{Name: "imported"}}
+			meta.SetExternalName(cr, "bucket-123")
+
+			e := &external{client: newGarage(t, tc.handler)}
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.id, cr.Status.AtProvider.ID); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want ID, +got ID:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveLookup(t *testing.T) {
+	alias := "media"
+
+	type want struct {
+		o   managed.ExternalObservation
+		id  string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		want    want
+	}{
+		"Gone": {
+			reason: "Should report that the bucket does not exist if Garage knows neither its ID nor its alias",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			want: want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"IDLookupFailed": {
+			reason: "Should return an error rather than report that the bucket does not exist if the lookup by ID failed",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			want: want{
+				err: errors.Wrap(&garage.APIError{StatusCode: http.StatusServiceUnavailable}, errGetBucket),
+				id:  "bucket-123",
+			},
+		},
+		"AliasLookupFailed": {
+			reason: "Should return an error rather than report that the bucket does not exist if the lookup by alias failed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("id") != "" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			want: want{err: errors.Wrap(&garage.APIError{StatusCode: http.StatusServiceUnavailable}, errGetBucket)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Bucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "media"},
+				Spec:       v1alpha1.BucketSpec{ForProvider: v1alpha1.BucketParameters{GlobalAlias: &alias}},
+				Status:     v1alpha1.BucketStatus{AtProvider: v1alpha1.BucketObservation{ID: "bucket-123"}},
+			}
+
+			e := &external{client: newGarage(t, tc.handler)}
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.id, cr.Status.AtProvider.ID); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want ID, +got ID:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestManagementPolicies(t *testing.T) {
+	type want struct {
+		created bool
+		id      string
+	}
+
+	cases := map[string]struct {
+		reason       string
+		policies     xpv1.ManagementPolicies
+		externalName string
+		want         want
+	}{
+		"ObserveOnlyImport": {
+			reason:       "Should observe an existing bucket without creating one",
+			policies:     xpv1.ManagementPolicies{xpv1.ManagementActionObserve},
+			externalName: "bucket-123",
+			want:         want{id: "bucket-123"},
+		},
+		"ObserveOnlyMissing": {
+			reason:       "Should not create a bucket that does not exist",
+			policies:     xpv1.ManagementPolicies{xpv1.ManagementActionObserve},
+			externalName: "bucket-456",
+		},
+		"AllMissing": {
+			reason:       "Should create a bucket that does not exist if allowed to",
+			policies:     xpv1.ManagementPolicies{xpv1.ManagementActionAll},
+			externalName: "bucket-456",
+			want:         want{created: true, id: "bucket-789"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			created := false
+			gc := newGarage(t, func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodPost:
+					created = true
+					_ = json.NewEncoder(w).Encode(garage.Bucket{ID: "bucket-789"})
+				case r.URL.Query().Get("id") == "bucket-123":
+					_ = json.NewEncoder(w).Encode(garage.Bucket{ID: "bucket-123"})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+
+			cr := &v1alpha1.Bucket{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "imported"}}
+			cr.SetManagementPolicies(tc.policies)
+			meta.SetExternalName(cr, tc.externalName)
+
+			var got *v1alpha1.Bucket
+			kube := test.NewMockClient()
+			kube.MockGet = test.NewMockGetFn(nil, func(o client.Object) error {
+				cr.DeepCopyInto(o.(*v1alpha1.Bucket))
+				return nil
+			})
+			kube.MockStatusUpdate = func(_ context.Context, o client.Object, _ ...client.SubResourceUpdateOption) error {
+				got = o.(*v1alpha1.Bucket)
+				return nil
+			}
+
+			s := runtime.NewScheme()
+			if err := v1alpha1.SchemeBuilder.AddToScheme(s); err != nil {
+				t.Fatal(err)
+			}
+
+			r := managed.NewReconciler(&fake.Manager{Client: kube, Scheme: s},
+				resource.ManagedKind(v1alpha1.BucketGroupVersionKind),
+				managed.WithManagementPolicies(),
+				managed.WithExternalConnecter(managed.ExternalConnectorFn(func(context.Context, resource.Managed) (managed.ExternalClient, error) {
+					return &external{client: gc}, nil
+				})),
+				managed.WithReferenceResolver(managed.ReferenceResolverFn(func(context.Context, resource.Managed) error { return nil })),
+			)
+			if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "imported"}}); err != nil {
+				t.Fatalf("\n%s\nr.Reconcile(...): %v", tc.reason, err)
+			}
+
+			if diff := cmp.Diff(tc.want.created, created); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want created, +got created:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.id, got.Status.AtProvider.ID); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want ID, +got ID:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+// Package bucketaccesspolicy contains the controller for BucketAccessPolicy resources
+package bucketaccesspolicy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/internal/clients"
+	"github.com/kikokikok/provider-garage/internal/dependency"
+	"github.com/kikokikok/provider-garage/internal/features"
+	"github.com/kikokikok/provider-garage/internal/grant"
+	"github.com/kikokikok/provider-garage/internal/tracing"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+const (
+	errNotBucketAccessPolicy = "managed resource is not a BucketAccessPolicy custom resource"
+	errResolveBucket         = "cannot resolve bucket reference"
+	errNoBucket              = "one of bucketId, bucketIdRef or bucketIdSelector is required"
+	errGetBucket             = "cannot get bucket"
+	errGrantAccess           = "cannot grant key access"
+	errRevokeAccess          = "cannot revoke key access"
+	errNoKey                 = "one of accessKeyId, accessKeyIdRef, accessKeyIdSelector or keyName is required"
+	errUnresolvedGrants      = "cannot resolve grants"
+)
+
+// Setup adds a controller that reconciles BucketAccessPolicy managed resources, which
+// connect to Garage with the supplied Connector.
+func Setup(mgr ctrl.Manager, o controller.Options, conn *clients.Connector) error {
+	name := managed.ControllerName(v1alpha1.BucketAccessPolicyGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), v1alpha1.StoreConfigGroupVersionKind, connection.WithTLSConfig(o.ESSOptions.TLSConfig)))
+	}
+
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(v1alpha1.BucketAccessPolicyKind, clients.NewExternalConnecter(
+			clients.NewUsageTracker(mgr.GetClient()),
+			conn,
+			newExternal(mgr.GetClient()),
+		))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...),
+	}
+	if o.Features.Enabled(feature.EnableBetaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+	mo, err := clients.MetricRecorders(mgr, o, &v1alpha1.BucketAccessPolicyList{})
+	if err != nil {
+		return err
+	}
+	opts = append(opts, mo...)
+
+	r := managed.NewReconciler(mgr, resource.ManagedKind(v1alpha1.BucketAccessPolicyGroupVersionKind), opts...)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.BucketAccessPolicy{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// newExternal returns a function that builds the external client of a bucket access policy.
+func newExternal(kube client.Client) clients.ExternalFn {
+	return func(_ resource.Managed, gc *garage.Client, _ *v1.ProviderConfigSpec) (managed.ExternalClient, error) {
+		return &external{client: gc, kube: kube}, nil
+	}
+}
+
+type external struct {
+	client *garage.Client
+	kube   client.Client
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.BucketAccessPolicy)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotBucketAccessPolicy)
+	}
+
+	bucketID, err := e.resolveBucketID(ctx, cr)
+	if err != nil && meta.WasDeleted(cr) && cr.Status.AtProvider.BucketID != "" {
+		// The referenced Bucket may be gone already; fall back to the
+		// bucket we last observed so that the policy can be deleted.
+		bucketID, err = cr.Status.AtProvider.BucketID, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	bucket, err := e.client.GetBucket(ctx, bucketID)
+	if garage.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetBucket)
+	}
+
+	want := e.resolveGrants(ctx, cr)
+	current := permissions(bucket)
+
+	cr.Status.AtProvider.BucketID = bucketID
+	cr.Status.AtProvider.Grants = observe(current, want)
+	cr.SetConditions(xpv1.Available())
+
+	allow, deny := changes(bucketID, current, want, cr.Spec.ForProvider.Mode)
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: len(allow) == 0 && len(deny) == 0 && unresolved(want) == nil,
+	}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.BucketAccessPolicy)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotBucketAccessPolicy)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+
+	return managed.ExternalCreation{}, e.sync(ctx, cr)
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.BucketAccessPolicy)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotBucketAccessPolicy)
+	}
+
+	return managed.ExternalUpdate{}, e.sync(ctx, cr)
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) (managed.ExternalDelete, error) {
+	cr, ok := mg.(*v1alpha1.BucketAccessPolicy)
+	if !ok {
+		return managed.ExternalDelete{}, errors.New(errNotBucketAccessPolicy)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	bucketID := cr.Status.AtProvider.BucketID
+	if bucketID == "" {
+		return managed.ExternalDelete{}, nil
+	}
+
+	// Keys that can no longer be resolved are skipped; they were most likely
+	// deleted along with their grants.
+	for _, req := range revocations(bucketID, e.resolveGrants(ctx, cr), cr.Spec.ForProvider.Mode) {
+		if _, err := e.client.RevokeKeyAccess(ctx, req); err != nil && !garage.IsNotFound(err) {
+			return managed.ExternalDelete{}, errors.Wrap(err, errRevokeAccess)
+		}
+	}
+
+	return managed.ExternalDelete{}, nil
+}
+
+// revocations returns the requests that revoke the grants of a deleted
+// policy. In Authoritative mode the policy owned all access of the listed
+// keys, so all of it is revoked. In Additive mode only the permissions the
+// policy granted are, so that those granted by other means are kept.
+func revocations(bucketID string, want []resolvedGrant, mode v1alpha1.BucketAccessPolicyMode) []*garage.RevokeKeyAccessRequest {
+	d := desired(want)
+	deny := make([]*garage.RevokeKeyAccessRequest, 0, len(d))
+	for _, id := range sortedKeys(d) {
+		p := d[id]
+		if mode == v1alpha1.BucketAccessPolicyModeAuthoritative {
+			p = grant.All
+		}
+		if p == grant.None {
+			continue
+		}
+		deny = append(deny, grant.Deny(bucketID, id, p))
+	}
+	return deny
+}
+
+func (e *external) Disconnect(ctx context.Context) error {
+	return nil
+}
+
+// sync grants and revokes permissions until the bucket matches the policy.
+func (e *external) sync(ctx context.Context, cr *v1alpha1.BucketAccessPolicy) error {
+	bucketID, err := e.resolveBucketID(ctx, cr)
+	if err != nil {
+		return err
+	}
+
+	bucket, err := e.client.GetBucket(ctx, bucketID)
+	if err != nil {
+		return errors.Wrap(err, errGetBucket)
+	}
+
+	want := e.resolveGrants(ctx, cr)
+	allow, deny := changes(bucketID, permissions(bucket), want, cr.Spec.ForProvider.Mode)
+
+	for _, req := range allow {
+		if _, err := e.client.GrantKeyAccess(ctx, req); err != nil {
+			return errors.Wrapf(err, "%s %s", errGrantAccess, req.AccessKeyID)
+		}
+	}
+	for _, req := range deny {
+		if _, err := e.client.RevokeKeyAccess(ctx, req); err != nil {
+			return errors.Wrapf(err, "%s %s", errRevokeAccess, req.AccessKeyID)
+		}
+	}
+
+	cr.Status.AtProvider.BucketID = bucketID
+
+	// Grants that could be resolved have been applied; report the rest so
+	// that the policy is retried until they can be.
+	return unresolved(want)
+}
+
+func (e *external) resolveBucketID(ctx context.Context, cr *v1alpha1.BucketAccessPolicy) (string, error) {
+	p := cr.Spec.ForProvider
+	id, err := dependency.ResolveBucketID(ctx, e.kube, cr, p.BucketID, p.BucketIDRef, p.BucketIDSelector)
+	if err != nil {
+		return "", errors.Wrap(err, errResolveBucket)
+	}
+	if id == "" {
+		return "", errors.New(errNoBucket)
+	}
+	return id, nil
+}
+
+// resolvedGrant is a grant of the policy resolved to an access key ID.
+type resolvedGrant struct {
+	accessKeyID string
+	permissions v1alpha1.KeyAccessPermissions
+	err         error
+}
+
+// resolveGrants resolves the access key ID of each grant of the policy, in
+// the order of the spec. Grants that cannot be resolved carry an error.
+func (e *external) resolveGrants(ctx context.Context, cr *v1alpha1.BucketAccessPolicy) []resolvedGrant {
+	grants := make([]resolvedGrant, len(cr.Spec.ForProvider.Grants))
+	for i, g := range cr.Spec.ForProvider.Grants {
+		grants[i].permissions = g.Permissions
+		grants[i].accessKeyID, grants[i].err = e.resolveAccessKeyID(ctx, cr, g)
+	}
+	return grants
+}
+
+func (e *external) resolveAccessKeyID(ctx context.Context, cr *v1alpha1.BucketAccessPolicy, g v1alpha1.BucketAccessGrant) (string, error) {
+	id, err := dependency.ResolveAccessKeyID(ctx, e.kube, cr, g.AccessKeyID, g.AccessKeyIDRef, g.AccessKeyIDSelector)
+	if err != nil || id != "" {
+		return id, err
+	}
+	if g.KeyName != nil && *g.KeyName != "" {
+		key, err := e.client.GetKeyByName(ctx, *g.KeyName)
+		if err != nil {
+			return "", err
+		}
+		return key.AccessKeyID, nil
+	}
+	return "", errors.New(errNoKey)
+}
+
+// permissions returns the permissions of each key that has access to the bucket.
+func permissions(b *garage.Bucket) map[string]v1alpha1.KeyAccessPermissions {
+	p := make(map[string]v1alpha1.KeyAccessPermissions, len(b.Keys))
+	for _, k := range b.Keys {
+		p[k.AccessKeyID] = v1alpha1.KeyAccessPermissions{
+			Read:  k.Permissions.Read,
+			Write: k.Permissions.Write,
+			Owner: k.Permissions.Owner,
+		}
+	}
+	return p
+}
+
+// desired merges the resolved grants by access key ID. A key that is listed
+// more than once gets the union of its permissions.
+func desired(want []resolvedGrant) map[string]v1alpha1.KeyAccessPermissions {
+	d := make(map[string]v1alpha1.KeyAccessPermissions, len(want))
+	for _, g := range want {
+		if g.err != nil {
+			continue
+		}
+		d[g.accessKeyID] = grant.Union(d[g.accessKeyID], g.permissions)
+	}
+	return d
+}
+
+// observe returns the status of each grant, in the order of the spec.
+func observe(current map[string]v1alpha1.KeyAccessPermissions, want []resolvedGrant) []v1alpha1.BucketAccessGrantObservation {
+	d := desired(want)
+	obs := make([]v1alpha1.BucketAccessGrantObservation, len(want))
+	for i, g := range want {
+		if g.err != nil {
+			obs[i].Message = g.err.Error()
+			continue
+		}
+		obs[i].AccessKeyID = g.accessKeyID
+		obs[i].Permissions = current[g.accessKeyID]
+		obs[i].Synced = current[g.accessKeyID] == d[g.accessKeyID]
+		if !obs[i].Synced {
+			obs[i].Message = "permissions differ from the policy"
+		}
+	}
+	return obs
+}
+
+// changes returns the requests that grant and revoke permissions until the
+// bucket matches the policy. In Authoritative mode the permissions of keys
+// that are not listed are revoked too, unless some grants could not be
+// resolved: an unresolved grant may refer to any of those keys.
+func changes(bucketID string, current map[string]v1alpha1.KeyAccessPermissions, want []resolvedGrant, mode v1alpha1.BucketAccessPolicyMode) ([]*garage.GrantKeyAccessRequest, []*garage.RevokeKeyAccessRequest) {
+	d := desired(want)
+
+	var allow []*garage.GrantKeyAccessRequest
+	var deny []*garage.RevokeKeyAccessRequest
+
+	for _, id := range sortedKeys(d) {
+		a, r := grant.Diff(current[id], d[id])
+		if a != grant.None {
+			allow = append(allow, grant.Allow(bucketID, id, a))
+		}
+		if r != grant.None {
+			deny = append(deny, grant.Deny(bucketID, id, r))
+		}
+	}
+
+	if mode != v1alpha1.BucketAccessPolicyModeAuthoritative || unresolved(want) != nil {
+		return allow, deny
+	}
+
+	for _, id := range sortedKeys(current) {
+		c := current[id]
+		if _, listed := d[id]; listed || c == grant.None {
+			continue
+		}
+		deny = append(deny, grant.Deny(bucketID, id, c))
+	}
+
+	return allow, deny
+}
+
+// unresolved returns an error describing the grants that could not be
+// resolved, or nil if all of them were.
+func unresolved(want []resolvedGrant) error {
+	var msgs []string
+	for i, g := range want {
+		if g.err != nil {
+			msgs = append(msgs, fmt.Sprintf("grants[%d]: %s", i, g.err))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.Errorf("%s: %s", errUnresolvedGrants, strings.Join(msgs, "; "))
+}
+
+func sortedKeys(m map[string]v1alpha1.KeyAccessPermissions) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,220 @@
+package bucketaccesspolicy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+func grantReq(bucketID, accessKeyID string, read, write, owner bool) *garage.GrantKeyAccessRequest {
+	req := &garage.GrantKeyAccessRequest{BucketID: bucketID, AccessKeyID: accessKeyID}
+	req.Permissions.Read, req.Permissions.Write, req.Permissions.Owner = read, write, owner
+	return req
+}
+
+func revokeReq(bucketID, accessKeyID string, read, write, owner bool) *garage.RevokeKeyAccessRequest {
+	req := &garage.RevokeKeyAccessRequest{BucketID: bucketID, AccessKeyID: accessKeyID}
+	req.Permissions.Read, req.Permissions.Write, req.Permissions.Owner = read, write, owner
+	return req
+}
+
+func TestChanges(t *testing.T) {
+	rw := v1alpha1.KeyAccessPermissions{Read: true, Write: true}
+	ro := v1alpha1.KeyAccessPermissions{Read: true}
+
+	type args struct {
+		current map[string]v1alpha1.KeyAccessPermissions
+		want    []resolvedGrant
+		mode    v1alpha1.BucketAccessPolicyMode
+	}
+
+	type want struct {
+		allow []*garage.GrantKeyAccessRequest
+		deny  []*garage.RevokeKeyAccessRequest
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"UpToDate": {
+			reason: "Should not change anything when the bucket matches the policy",
+			args: args{
+				current: map[string]v1alpha1.KeyAccessPermissions{"GK1": rw},
+				want:    []resolvedGrant{{accessKeyID: "GK1", permissions: rw}},
+			},
+			want: want{},
+		},
+		"GrantMissing": {
+			reason: "Should grant only the permissions a key is missing",
+			args: args{
+				current: map[string]v1alpha1.KeyAccessPermissions{"GK1": ro},
+				want:    []resolvedGrant{{accessKeyID: "GK1", permissions: rw}, {accessKeyID: "GK2", permissions: ro}},
+			},
+			want: want{
+				allow: []*garage.GrantKeyAccessRequest{
+					grantReq("bucket", "GK1", false, true, false),
+					grantReq("bucket", "GK2", true, false, false),
+				},
+			},
+		},
+		"RevokeExcess": {
+			reason: "Should revoke permissions a listed key has beyond the policy",
+			args: args{
+				current: map[string]v1alpha1.KeyAccessPermissions{"GK1": rw},
+				want:    []resolvedGrant{{accessKeyID: "GK1", permissions: ro}},
+			},
+			want: want{
+				deny: []*garage.RevokeKeyAccessRequest{revokeReq("bucket", "GK1", false, true, false)},
+			},
+		},
+		"MergeDuplicates": {
+			reason: "Should grant the union of the permissions of a key listed twice",
+			args: args{
+				current: map[string]v1alpha1.KeyAccessPermissions{},
+				want: []resolvedGrant{
+					{accessKeyID: "GK1", permissions: ro},
+					{accessKeyID: "GK1", permissions: v1alpha1.KeyAccessPermissions{Owner: true}},
+				},
+			},
+			want: want{
+				allow: []*garage.GrantKeyAccessRequest{grantReq("bucket", "GK1", true, false, true)},
+			},
+		},
+		"AdditiveIgnoresUnlisted": {
+			reason: "Should leave grants of unlisted keys alone in Additive mode",
+			args: args{
+				current: map[string]v1alpha1.KeyAccessPermissions{"GK1": rw, "GK9": rw},
+				want:    []resolvedGrant{{accessKeyID: "GK1", permissions: rw}},
+				mode:    v1alpha1.BucketAccessPolicyModeAdditive,
+			},
+			want: want{},
+		},
+		"AuthoritativeRevokesUnlisted": {
+			reason: "Should revoke grants of unlisted keys in Authoritative mode",
+			args: args{
+				current: map[string]v1alpha1.KeyAccessPermissions{"GK1": rw, "GK9": rw},
+				want:    []resolvedGrant{{accessKeyID: "GK1", permissions: rw}},
+				mode:    v1alpha1.BucketAccessPolicyModeAuthoritative,
+			},
+			want: want{
+				deny: []*garage.RevokeKeyAccessRequest{revokeReq("bucket", "GK9", true, true, false)},
+			},
+		},
+		"AuthoritativeWithUnresolved": {
+			reason: "Should not revoke unlisted keys while some grants cannot be resolved",
+			args: args{
+				current: map[string]v1alpha1.KeyAccessPermissions{"GK9": rw},
+				want:    []resolvedGrant{{err: errors.New("boom"), permissions: rw}},
+				mode:    v1alpha1.BucketAccessPolicyModeAuthoritative,
+			},
+			want: want{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			allow, deny := changes("bucket", tc.args.current, tc.args.want, tc.args.mode)
+			if diff := cmp.Diff(tc.want.allow, allow); diff != "" {
+				t.Errorf("\n%s\nchanges(...): -want allow, +got allow:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.deny, deny); diff != "" {
+				t.Errorf("\n%s\nchanges(...): -want deny, +got deny:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	rw := v1alpha1.KeyAccessPermissions{Read: true, Write: true}
+	ro := v1alpha1.KeyAccessPermissions{Read: true}
+
+	cases := map[string]struct {
+		reason  string
+		current map[string]v1alpha1.KeyAccessPermissions
+		want    []resolvedGrant
+		o       []v1alpha1.BucketAccessGrantObservation
+	}{
+		"PerGrantStatus": {
+			reason:  "Should report the state of each grant in the order of the spec",
+			current: map[string]v1alpha1.KeyAccessPermissions{"GK1": rw, "GK2": rw},
+			want: []resolvedGrant{
+				{accessKeyID: "GK1", permissions: rw},
+				{accessKeyID: "GK2", permissions: ro},
+				{err: errors.New("referenced Key has not been reconciled yet (AccessKeyID is empty)"), permissions: ro},
+			},
+			o: []v1alpha1.BucketAccessGrantObservation{
+				{AccessKeyID: "GK1", Permissions: rw, Synced: true},
+				{AccessKeyID: "GK2", Permissions: rw, Message: "permissions differ from the policy"},
+				{Message: "referenced Key has not been reconciled yet (AccessKeyID is empty)"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := observe(tc.current, tc.want)
+			if diff := cmp.Diff(tc.o, got); diff != "" {
+				t.Errorf("\n%s\nobserve(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRevocations(t *testing.T) {
+	rw := v1alpha1.KeyAccessPermissions{Read: true, Write: true}
+	ro := v1alpha1.KeyAccessPermissions{Read: true}
+
+	cases := map[string]struct {
+		reason string
+		want   []resolvedGrant
+		mode   v1alpha1.BucketAccessPolicyMode
+		o      []*garage.RevokeKeyAccessRequest
+	}{
+		"Additive": {
+			reason: "Should revoke only the permissions the policy granted",
+			want: []resolvedGrant{
+				{accessKeyID: "GK2", permissions: ro},
+				{accessKeyID: "GK1", permissions: ro},
+				{accessKeyID: "GK1", permissions: v1alpha1.KeyAccessPermissions{Write: true}},
+			},
+			mode: v1alpha1.BucketAccessPolicyModeAdditive,
+			o: []*garage.RevokeKeyAccessRequest{
+				revokeReq("bucket", "GK1", true, true, false),
+				revokeReq("bucket", "GK2", true, false, false),
+			},
+		},
+		"AdditiveNoPermissions": {
+			reason: "Should not revoke anything from a key the policy granted no permissions",
+			want:   []resolvedGrant{{accessKeyID: "GK1"}},
+			mode:   v1alpha1.BucketAccessPolicyModeAdditive,
+			o:      []*garage.RevokeKeyAccessRequest{},
+		},
+		"Authoritative": {
+			reason: "Should revoke all access of the listed keys",
+			want:   []resolvedGrant{{accessKeyID: "GK1", permissions: rw}},
+			mode:   v1alpha1.BucketAccessPolicyModeAuthoritative,
+			o:      []*garage.RevokeKeyAccessRequest{revokeReq("bucket", "GK1", true, true, true)},
+		},
+		"Unresolved": {
+			reason: "Should skip grants that cannot be resolved",
+			want:   []resolvedGrant{{err: errors.New("boom"), permissions: rw}},
+			mode:   v1alpha1.BucketAccessPolicyModeAuthoritative,
+			o:      []*garage.RevokeKeyAccessRequest{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := revocations("bucket", tc.want, tc.mode)
+			if diff := cmp.Diff(tc.o, got); diff != "" {
+				t.Errorf("\n%s\nrevocations(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
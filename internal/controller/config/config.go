@@ -0,0 +1,76 @@
+// Package config contains the controllers for ProviderConfig and
+// ClusterProviderConfig resources
+package config
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/internal/clients"
+)
+
+// A configKind is one of the kinds of provider config managed resources can
+// reference.
+type configKind struct {
+	groupKind  string
+	namespaced bool
+
+	// of is the type metadata of the provider config and of the list of its
+	// usages.
+	of resource.ProviderConfigKinds
+
+	// usage is an empty usage of a provider config of this kind.
+	usage client.Object
+
+	// newConfig returns an empty provider config of this kind, along with its
+	// spec and status.
+	newConfig func() (resource.ProviderConfig, *v1.ProviderConfigSpec, *v1.ProviderConfigStatus)
+}
+
+var kinds = []configKind{
+	{
+		groupKind: v1.ClusterProviderConfigGroupKind,
+		of: resource.ProviderConfigKinds{
+			Config:    v1.ClusterProviderConfigGroupVersionKind,
+			UsageList: v1.ClusterProviderConfigUsageListGroupVersionKind,
+		},
+		usage: &v1.ClusterProviderConfigUsage{},
+		newConfig: func() (resource.ProviderConfig, *v1.ProviderConfigSpec, *v1.ProviderConfigStatus) {
+			pc := &v1.ClusterProviderConfig{}
+			return pc, &pc.Spec, &pc.Status
+		},
+	},
+	{
+		groupKind:  v1.ProviderConfigGroupKind,
+		namespaced: true,
+		of: resource.ProviderConfigKinds{
+			Config:    v1.ProviderConfigGroupVersionKind,
+			UsageList: v1.ProviderConfigUsageListGroupVersionKind,
+		},
+		usage: &v1.ProviderConfigUsage{},
+		newConfig: func() (resource.ProviderConfig, *v1.ProviderConfigSpec, *v1.ProviderConfigStatus) {
+			pc := &v1.ProviderConfig{}
+			return pc, &pc.Spec, &pc.Status
+		},
+	},
+}
+
+// Setup adds controllers that reconcile ProviderConfigs and
+// ClusterProviderConfigs by accounting for their current usage, blocking their
+// deletion while they are in use, and checking the health of the Garage
+// cluster they connect to with the supplied Connector.
+func Setup(mgr ctrl.Manager, o controller.Options, conn *clients.Connector) error {
+	for _, k := range kinds {
+		if err := setupUsage(mgr, o, k); err != nil {
+			return err
+		}
+		if err := setupHealth(mgr, o, conn, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
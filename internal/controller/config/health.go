@@ -0,0 +1,162 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/internal/clients"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+const (
+	errGetPC       = "cannot get provider config"
+	errPatchStatus = "cannot patch provider config status"
+
+	healthTimeout = 30 * time.Second
+)
+
+// healthClient is the part of the Garage Admin API used to check health.
+type healthClient interface {
+	GetClusterHealth(ctx context.Context) (*garage.ClusterHealth, error)
+	GetClusterStatus(ctx context.Context) (*garage.ClusterStatus, error)
+}
+
+// setupHealth adds a controller that periodically checks the Garage cluster
+// each provider config of the supplied kind connects to and reports its
+// health in status. The Connector's client of a provider config is evicted
+// once the provider config is gone.
+func setupHealth(mgr ctrl.Manager, o controller.Options, conn *clients.Connector, k configKind) error {
+	name := "health/" + k.groupKind
+
+	r := &healthReconciler{
+		kube: mgr.GetClient(),
+		log:  o.Logger.WithValues("controller", name),
+		newClient: func(ctx context.Context, pc metav1.Object, spec *v1.ProviderConfigSpec) (healthClient, error) {
+			return conn.Client(ctx, pc, spec)
+		},
+		evict:    conn.Evict,
+		interval: o.PollInterval,
+		kind:     k,
+	}
+
+	pc, _, _ := k.newConfig()
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		// Only spec changes warrant an early check; our own status patches
+		// must not trigger another one.
+		For(pc, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+type healthReconciler struct {
+	kube      client.Client
+	log       logging.Logger
+	newClient func(ctx context.Context, pc metav1.Object, spec *v1.ProviderConfigSpec) (healthClient, error)
+	evict     func(key types.NamespacedName)
+	interval  time.Duration
+	kind      configKind
+}
+
+// Reconcile checks the health of the Garage cluster a provider config
+// connects to, and checks it again after the poll interval.
+func (r *healthReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	pc, spec, status := r.kind.newConfig()
+	err := r.kube.Get(ctx, req.NamespacedName, pc)
+	if kerrors.IsNotFound(err) || (err == nil && meta.WasDeleted(pc)) {
+		r.evict(req.NamespacedName)
+		return reconcile.Result{}, nil
+	}
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errGetPC)
+	}
+
+	// Like those of the managed resources using it, the credentials of a
+	// ProviderConfig are read from its namespace.
+	if r.kind.namespaced {
+		spec = clients.InNamespace(spec, pc.GetNamespace())
+	}
+
+	// The usage controller updates the user count in the same status,
+	// so patch only the fields the check changes rather than update it all.
+	orig := pc.DeepCopyObject().(client.Object)
+	r.check(ctx, pc, spec, status)
+	log.Debug("Checked Garage cluster health", "ready", pc.GetCondition(xpv1.TypeReady).Status, "healthy", pc.GetCondition(v1.TypeHealthy).Status)
+
+	return reconcile.Result{RequeueAfter: r.interval}, errors.Wrap(r.kube.Status().Patch(ctx, pc, client.MergeFrom(orig)), errPatchStatus)
+}
+
+// check sets the Ready and Healthy conditions and the cluster observation of
+// the supplied provider config status.
+func (r *healthReconciler) check(ctx context.Context, pc metav1.Object, spec *v1.ProviderConfigSpec, status *v1.ProviderConfigStatus) {
+	gc, err := r.newClient(ctx, pc, spec)
+	if err != nil {
+		status.SetConditions(v1.Unready(v1.ReasonCredentialsUnavailable, err.Error()), v1.Unhealthy(v1.ReasonCredentialsUnavailable, err.Error()))
+		return
+	}
+
+	health, err := gc.GetClusterHealth(ctx)
+	if err != nil {
+		reason := v1.ReasonUnreachable
+		if garage.IsUnauthorized(err) {
+			reason = v1.ReasonUnauthorized
+		}
+		status.SetConditions(v1.Unready(reason, err.Error()), v1.Unhealthy(reason, err.Error()))
+		return
+	}
+
+	now := metav1.Now()
+	o := &v1.ClusterObservation{
+		KnownNodes:        health.KnownNodes,
+		ConnectedNodes:    health.ConnectedNodes,
+		HealthyPartitions: fmt.Sprintf("%d/%d", health.PartitionsAllOK, health.Partitions),
+		LastCheckTime:     &now,
+	}
+	// The status only adds details, so the cluster is usable without it. Keep
+	// those last observed until it can be read again.
+	if cs, err := gc.GetClusterStatus(ctx); err == nil {
+		o.GarageVersion = cs.GarageVersion
+		o.NodeID = cs.Node
+		o.LayoutVersion = cs.LayoutVersion
+	} else if prev := status.Cluster; prev != nil {
+		o.GarageVersion = prev.GarageVersion
+		o.NodeID = prev.NodeID
+		o.LayoutVersion = prev.LayoutVersion
+	}
+	status.Cluster = o
+
+	status.SetConditions(xpv1.Available())
+	switch health.Status {
+	case garage.ClusterHealthy:
+		status.SetConditions(v1.Healthy())
+	case garage.ClusterDegraded:
+		status.SetConditions(v1.Unhealthy(v1.ReasonDegraded, fmt.Sprintf("%d/%d storage nodes up, %d/%d partitions have quorum",
+			health.StorageNodesOK, health.StorageNodes, health.PartitionsQuorum, health.Partitions)))
+	default:
+		status.SetConditions(v1.Unhealthy(v1.ReasonUnavailable, fmt.Sprintf("cluster is %s, %d/%d partitions have quorum",
+			health.Status, health.PartitionsQuorum, health.Partitions)))
+	}
+}
@@ -0,0 +1,299 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+type mockHealthClient struct {
+	health    *garage.ClusterHealth
+	status    *garage.ClusterStatus
+	err       error
+	statusErr error
+}
+
+func (m *mockHealthClient) GetClusterHealth(_ context.Context) (*garage.ClusterHealth, error) {
+	return m.health, m.err
+}
+
+func (m *mockHealthClient) GetClusterStatus(_ context.Context) (*garage.ClusterStatus, error) {
+	return m.status, m.statusErr
+}
+
+func TestCheck(t *testing.T) {
+	errBoom := errors.New("boom")
+	errDenied := &garage.APIError{StatusCode: 403, Body: "invalid token"}
+
+	healthy := &garage.ClusterHealth{Status: garage.ClusterHealthy, KnownNodes: 3, ConnectedNodes: 3, Partitions: 256, PartitionsAllOK: 256}
+	degraded := &garage.ClusterHealth{Status: garage.ClusterDegraded, KnownNodes: 3, ConnectedNodes: 2, StorageNodes: 3, StorageNodesOK: 2, Partitions: 256, PartitionsQuorum: 256}
+	status := &garage.ClusterStatus{Node: "n1", GarageVersion: "v1.0.1", LayoutVersion: 4}
+
+	type want struct {
+		conditions []xpv1.Condition
+		cluster    *v1.ClusterObservation
+	}
+
+	cases := map[string]struct {
+		reason    string
+		client    healthClient
+		clientErr error
+		prev      *v1.ClusterObservation
+		want      want
+	}{
+		"CredentialsUnavailable": {
+			reason:    "Should report unavailable credentials when no client can be built",
+			clientErr: errBoom,
+			want: want{conditions: []xpv1.Condition{
+				v1.Unready(v1.ReasonCredentialsUnavailable, "boom"),
+				v1.Unhealthy(v1.ReasonCredentialsUnavailable, "boom"),
+			}},
+		},
+		"Unauthorized": {
+			reason: "Should report a rejected admin token as unauthorized",
+			client: &mockHealthClient{err: errDenied},
+			want: want{conditions: []xpv1.Condition{
+				v1.Unready(v1.ReasonUnauthorized, errDenied.Error()),
+				v1.Unhealthy(v1.ReasonUnauthorized, errDenied.Error()),
+			}},
+		},
+		"Unreachable": {
+			reason: "Should report other errors as unreachable",
+			client: &mockHealthClient{err: errBoom},
+			want: want{conditions: []xpv1.Condition{
+				v1.Unready(v1.ReasonUnreachable, "boom"),
+				v1.Unhealthy(v1.ReasonUnreachable, "boom"),
+			}},
+		},
+		"Healthy": {
+			reason: "Should report a healthy cluster and its details",
+			client: &mockHealthClient{health: healthy, status: status},
+			want: want{
+				conditions: []xpv1.Condition{xpv1.Available(), v1.Healthy()},
+				cluster: &v1.ClusterObservation{
+					GarageVersion:     "v1.0.1",
+					NodeID:            "n1",
+					LayoutVersion:     4,
+					KnownNodes:        3,
+					ConnectedNodes:    3,
+					HealthyPartitions: "256/256",
+				},
+			},
+		},
+		"StatusUnavailable": {
+			reason: "Should report a healthy cluster without details if its status cannot be read",
+			client: &mockHealthClient{health: healthy, statusErr: errBoom},
+			want: want{
+				conditions: []xpv1.Condition{xpv1.Available(), v1.Healthy()},
+				cluster: &v1.ClusterObservation{
+					KnownNodes:        3,
+					ConnectedNodes:    3,
+					HealthyPartitions: "256/256",
+				},
+			},
+		},
+		"StatusUnavailableKeepsDetails": {
+			reason: "Should keep the details last observed if the cluster status cannot be read",
+			client: &mockHealthClient{health: healthy, statusErr: errBoom},
+			prev:   &v1.ClusterObservation{GarageVersion: "v1.0.0", NodeID: "n1", LayoutVersion: 3, KnownNodes: 2},
+			want: want{
+				conditions: []xpv1.Condition{xpv1.Available(), v1.Healthy()},
+				cluster: &v1.ClusterObservation{
+					GarageVersion:     "v1.0.0",
+					NodeID:            "n1",
+					LayoutVersion:     3,
+					KnownNodes:        3,
+					ConnectedNodes:    3,
+					HealthyPartitions: "256/256",
+				},
+			},
+		},
+		"Degraded": {
+			reason: "Should report a degraded cluster as ready but not healthy",
+			client: &mockHealthClient{health: degraded, status: status},
+			want: want{
+				conditions: []xpv1.Condition{
+					xpv1.Available(),
+					v1.Unhealthy(v1.ReasonDegraded, "2/3 storage nodes up, 256/256 partitions have quorum"),
+				},
+				cluster: &v1.ClusterObservation{
+					GarageVersion:     "v1.0.1",
+					NodeID:            "n1",
+					LayoutVersion:     4,
+					KnownNodes:        3,
+					ConnectedNodes:    2,
+					HealthyPartitions: "0/256",
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &healthReconciler{newClient: func(_ context.Context, _ metav1.Object, _ *v1.ProviderConfigSpec) (healthClient, error) {
+				return tc.client, tc.clientErr
+			}}
+			pc := &v1.ProviderConfig{Status: v1.ProviderConfigStatus{Cluster: tc.prev}}
+			r.check(context.Background(), pc, &pc.Spec, &pc.Status)
+
+			ignoreTime := cmpopts.IgnoreTypes(metav1.Time{}, &metav1.Time{})
+			if diff := cmp.Diff(tc.want.conditions, pc.Status.Conditions, test.EquateConditions(), ignoreTime); diff != "" {
+				t.Errorf("\n%s\ncheck(...): -want conditions, +got conditions:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cluster, pc.Status.Cluster, ignoreTime); diff != "" {
+				t.Errorf("\n%s\ncheck(...): -want cluster, +got cluster:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReconcileEvict(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := metav1.Now()
+
+	type want struct {
+		evicted bool
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		get    test.MockGetFn
+		want   want
+	}{
+		"NotFound": {
+			reason: "Should evict the client of a provider config that is gone",
+			get:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "default")),
+			want:   want{evicted: true},
+		},
+		"Deleted": {
+			reason: "Should evict the client of a provider config that is being deleted",
+			get: test.NewMockGetFn(nil, func(o client.Object) error {
+				o.SetDeletionTimestamp(&now)
+				return nil
+			}),
+			want: want{evicted: true},
+		},
+		"GetError": {
+			reason: "Should keep the client if the provider config cannot be read",
+			get:    test.NewMockGetFn(errBoom),
+			want:   want{err: errors.Wrap(errBoom, errGetPC)},
+		},
+		"Exists": {
+			reason: "Should keep the client of a provider config that exists",
+			get:    test.NewMockGetFn(nil),
+			want:   want{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			evicted := false
+			r := &healthReconciler{
+				kube: &test.MockClient{MockGet: tc.get, MockStatusPatch: test.NewMockSubResourcePatchFn(nil)},
+				log:  logging.NewNopLogger(),
+				newClient: func(_ context.Context, _ metav1.Object, _ *v1.ProviderConfigSpec) (healthClient, error) {
+					return nil, errBoom
+				},
+				evict: func(key types.NamespacedName) {
+					evicted = key == types.NamespacedName{Name: "default"}
+				},
+				kind: kinds[0],
+			}
+			_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "default"}})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.evicted, evicted); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want evicted, +got evicted:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReconcilePatch(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		fields []string
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		patch  error
+		want   want
+	}{
+		"PatchError": {
+			reason: "Should return an error if the status cannot be patched",
+			patch:  errBoom,
+			want:   want{err: errors.Wrap(errBoom, errPatchStatus)},
+		},
+		"Patched": {
+			reason: "Should patch only the conditions it changed, leaving the user count to the usage controller",
+			want:   want{fields: []string{"conditions"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var fields []string
+			r := &healthReconciler{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+						o.(*v1.ClusterProviderConfig).Status.Users = 3
+						return nil
+					}),
+					MockStatusPatch: func(_ context.Context, obj client.Object, p client.Patch, _ ...client.SubResourcePatchOption) error {
+						data, err := p.Data(obj)
+						if err != nil {
+							return err
+						}
+						patch := map[string]map[string]any{}
+						if err := json.Unmarshal(data, &patch); err != nil {
+							return err
+						}
+						for f := range patch["status"] {
+							fields = append(fields, f)
+						}
+						sort.Strings(fields)
+						return tc.patch
+					},
+				},
+				log: logging.NewNopLogger(),
+				newClient: func(_ context.Context, _ metav1.Object, _ *v1.ProviderConfigSpec) (healthClient, error) {
+					return nil, errBoom
+				},
+				kind: kinds[0],
+			}
+			_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "default"}})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if tc.want.err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.fields, fields); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want patched status fields, +got patched status fields:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
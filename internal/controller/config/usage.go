@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/providerconfig"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// setupUsage adds a controller that accounts for the managed resources using
+// each provider config of the supplied kind, and blocks the deletion of those
+// in use.
+func setupUsage(mgr ctrl.Manager, o controller.Options, k configKind) error {
+	name := providerconfig.ControllerName(k.groupKind)
+
+	opts := []providerconfig.ReconcilerOption{
+		providerconfig.WithLogger(o.Logger.WithValues("controller", name)),
+		providerconfig.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+	}
+
+	var r reconcile.Reconciler = providerconfig.NewReconciler(mgr, k.of, opts...)
+	var h handler.EventHandler = &resource.EnqueueRequestForProviderConfig{}
+	if k.namespaced {
+		r = &namespacedReconciler{mgr: mgr, of: k.of, opts: opts}
+		h = handler.EnqueueRequestsFromMapFunc(usedConfig)
+	}
+
+	pc, _, _ := k.newConfig()
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(pc).
+		Watches(k.usage, h).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// usedConfig returns a request for the ProviderConfig a
+// ProviderConfigUsage records the use of, which is in the namespace
+// of the usage.
+func usedConfig(_ context.Context, o client.Object) []reconcile.Request {
+	pcu, ok := o.(resource.ProviderConfigUsage)
+	if !ok {
+		return nil
+	}
+	nn := types.NamespacedName{Namespace: pcu.GetNamespace(), Name: pcu.GetProviderConfigReference().Name}
+	return []reconcile.Request{{NamespacedName: nn}}
+}
+
+// A namespacedReconciler reconciles ProviderConfigs with the
+// crossplane-runtime providerconfig reconciler. That reconciler lists usages
+// by provider config name alone, so each request is reconciled with a client
+// scoped to the namespace of the provider config, lest the usages of a
+// same-named provider config in another namespace be counted.
+type namespacedReconciler struct {
+	mgr  ctrl.Manager
+	of   resource.ProviderConfigKinds
+	opts []providerconfig.ReconcilerOption
+}
+
+func (r *namespacedReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	m := &namespacedManager{Manager: r.mgr, client: client.NewNamespacedClient(r.mgr.GetClient(), req.Namespace)}
+	return providerconfig.NewReconciler(m, r.of, r.opts...).Reconcile(ctx, req)
+}
+
+// A namespacedManager is a manager whose client is scoped to a namespace.
+type namespacedManager struct {
+	ctrl.Manager
+	client client.Client
+}
+
+func (m *namespacedManager) GetClient() client.Client {
+	return m.client
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+)
+
+func TestUsedConfig(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		o      client.Object
+		want   []reconcile.Request
+	}{
+		"ProviderConfigUsage": {
+			reason: "Should enqueue the ProviderConfig in the namespace of the usage",
+			o: &v1.ProviderConfigUsage{
+				ObjectMeta:          metav1.ObjectMeta{Namespace: "team-a", Name: "uid"},
+				ProviderConfigUsage: xpv1.ProviderConfigUsage{ProviderConfigReference: xpv1.Reference{Name: "default"}},
+			},
+			want: []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "default"}}},
+		},
+		"NotAUsage": {
+			reason: "Should not enqueue anything for an object that is not a usage",
+			o:      &v1.ProviderConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "default"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := usedConfig(context.Background(), tc.o)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nusedConfig(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
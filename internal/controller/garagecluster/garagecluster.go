@@ -0,0 +1,219 @@
+// Package garagecluster contains the controller for GarageCluster resources
+package garagecluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/internal/clients"
+	"github.com/kikokikok/provider-garage/internal/features"
+	"github.com/kikokikok/provider-garage/internal/tracing"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+const (
+	errNotGarageCluster = "managed resource is not a GarageCluster custom resource"
+	errGetHealth        = "cannot get cluster health"
+	errGetStatus        = "cannot get cluster status"
+)
+
+// clusterClient is the part of the Garage Admin API used to observe a cluster.
+type clusterClient interface {
+	GetClusterHealth(ctx context.Context) (*garage.ClusterHealth, error)
+	GetClusterStatus(ctx context.Context) (*garage.ClusterStatus, error)
+}
+
+// Setup adds a controller that reconciles GarageCluster managed resources, which
+// connect to Garage with the supplied Connector.
+func Setup(mgr ctrl.Manager, o controller.Options, conn *clients.Connector) error {
+	name := managed.ControllerName(v1alpha1.GarageClusterGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), v1alpha1.StoreConfigGroupVersionKind, connection.WithTLSConfig(o.ESSOptions.TLSConfig)))
+	}
+
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(v1alpha1.GarageClusterKind, clients.NewExternalConnecter(
+			clients.NewUsageTracker(mgr.GetClient()),
+			conn,
+			newExternal(),
+		))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...),
+		// Like the health of provider configs, the cluster is checked at the
+		// poll interval.
+		managed.WithPollInterval(o.PollInterval),
+	}
+	if o.Features.Enabled(feature.EnableBetaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+	mo, err := clients.MetricRecorders(mgr, o, &v1alpha1.GarageClusterList{})
+	if err != nil {
+		return err
+	}
+	opts = append(opts, mo...)
+
+	r := managed.NewReconciler(mgr, resource.ManagedKind(v1alpha1.GarageClusterGroupVersionKind), opts...)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.GarageCluster{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// newExternal returns a function that builds the external client of a Garage cluster.
+func newExternal() clients.ExternalFn {
+	return func(_ resource.Managed, gc *garage.Client, _ *v1.ProviderConfigSpec) (managed.ExternalClient, error) {
+		return &external{client: gc}, nil
+	}
+}
+
+type external struct {
+	client clusterClient
+}
+
+// Observe reports the health and status of the cluster. The cluster always
+// exists and a GarageCluster has nothing to update, so the resource is only
+// ever Ready or not.
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.GarageCluster)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotGarageCluster)
+	}
+
+	// There is nothing to delete, so let the finalizer go.
+	if meta.WasDeleted(cr) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	health, err := e.client.GetClusterHealth(ctx)
+	if err != nil {
+		reason := v1.ReasonUnreachable
+		if garage.IsUnauthorized(err) {
+			reason = v1.ReasonUnauthorized
+		}
+		cr.SetConditions(v1.Unready(reason, err.Error()))
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetHealth)
+	}
+
+	// The status only adds details, so the health is reported without it. The
+	// nodes last observed are kept rather than wiped by a transient error,
+	// which is returned once the health is recorded.
+	status, serr := e.client.GetClusterStatus(ctx)
+	cr.Status.AtProvider = observe(health, status, cr.Status.AtProvider)
+
+	switch health.Status {
+	case garage.ClusterHealthy:
+		cr.SetConditions(xpv1.Available())
+	case garage.ClusterDegraded:
+		cr.SetConditions(v1.Unready(v1.ReasonDegraded, fmt.Sprintf("%d/%d storage nodes up, %d/%d partitions have quorum",
+			health.StorageNodesOK, health.StorageNodes, health.PartitionsQuorum, health.Partitions)))
+	default:
+		cr.SetConditions(v1.Unready(v1.ReasonUnavailable, fmt.Sprintf("cluster is %s, %d/%d partitions have quorum",
+			health.Status, health.PartitionsQuorum, health.Partitions)))
+	}
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: true,
+	}, errors.Wrap(serr, errGetStatus)
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	return managed.ExternalCreation{}, nil
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	return managed.ExternalUpdate{}, nil
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) (managed.ExternalDelete, error) {
+	return managed.ExternalDelete{}, nil
+}
+
+func (e *external) Disconnect(ctx context.Context) error {
+	return nil
+}
+
+// observe returns the observation of a cluster from its health and, if it
+// could be read, its status. If not, the details of the previous observation
+// are kept.
+func observe(h *garage.ClusterHealth, s *garage.ClusterStatus, prev v1alpha1.GarageClusterObservation) v1alpha1.GarageClusterObservation {
+	o := v1alpha1.GarageClusterObservation{
+		Status:           h.Status,
+		KnownNodes:       h.KnownNodes,
+		ConnectedNodes:   h.ConnectedNodes,
+		StorageNodes:     h.StorageNodes,
+		StorageNodesOK:   h.StorageNodesOK,
+		Partitions:       h.Partitions,
+		PartitionsQuorum: h.PartitionsQuorum,
+		PartitionsAllOK:  h.PartitionsAllOK,
+	}
+	if s == nil {
+		o.GarageVersion = prev.GarageVersion
+		o.LayoutVersion = prev.LayoutVersion
+		o.Nodes = prev.Nodes
+		return o
+	}
+
+	o.GarageVersion = s.GarageVersion
+	o.LayoutVersion = s.LayoutVersion
+	for _, n := range s.Nodes {
+		o.Nodes = append(o.Nodes, observeNode(n))
+	}
+	return o
+}
+
+func observeNode(n garage.NodeStatus) v1alpha1.GarageNodeObservation {
+	o := v1alpha1.GarageNodeObservation{
+		ID:                n.ID,
+		Hostname:          stringValue(n.Hostname),
+		Address:           stringValue(n.Addr),
+		Up:                n.IsUp,
+		LastSeenSecsAgo:   n.LastSeenSecsAgo,
+		GarageVersion:     stringValue(n.GarageVersion),
+		Draining:          n.Draining,
+		DataPartition:     diskSpace(n.DataPartition),
+		MetadataPartition: diskSpace(n.MetadataPartition),
+	}
+	if n.Role != nil {
+		o.Role = &v1alpha1.GarageNodeRole{
+			Zone:     n.Role.Zone,
+			Capacity: n.Role.Capacity,
+			Tags:     n.Role.Tags,
+		}
+	}
+	return o
+}
+
+func diskSpace(d *garage.DiskSpace) *v1alpha1.GarageDiskSpace {
+	if d == nil {
+		return nil
+	}
+	return &v1alpha1.GarageDiskSpace{Available: d.Available, Total: d.Total}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,217 @@
+package garagecluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+type mockClusterClient struct {
+	health    *garage.ClusterHealth
+	status    *garage.ClusterStatus
+	err       error
+	statusErr error
+}
+
+func (m *mockClusterClient) GetClusterHealth(_ context.Context) (*garage.ClusterHealth, error) {
+	return m.health, m.err
+}
+
+func (m *mockClusterClient) GetClusterStatus(_ context.Context) (*garage.ClusterStatus, error) {
+	return m.status, m.statusErr
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+	errDenied := &garage.APIError{StatusCode: 403, Body: "invalid token"}
+
+	capacity := int64(1000)
+	lastSeen := int64(42)
+	addr := "10.0.0.1:3901"
+
+	healthy := &garage.ClusterHealth{Status: garage.ClusterHealthy, KnownNodes: 2, ConnectedNodes: 2, StorageNodes: 1, StorageNodesOK: 1, Partitions: 256, PartitionsQuorum: 256, PartitionsAllOK: 256}
+	degraded := &garage.ClusterHealth{Status: garage.ClusterDegraded, KnownNodes: 3, ConnectedNodes: 2, StorageNodes: 3, StorageNodesOK: 2, Partitions: 256, PartitionsQuorum: 256}
+	unavailable := &garage.ClusterHealth{Status: garage.ClusterUnavailable, KnownNodes: 3, ConnectedNodes: 1, StorageNodes: 3, StorageNodesOK: 1, Partitions: 256}
+	status := &garage.ClusterStatus{
+		Node:          "n1",
+		GarageVersion: "v1.0.1",
+		LayoutVersion: 4,
+		Nodes: []garage.NodeStatus{
+			{
+				ID:            "n1",
+				Addr:          &addr,
+				IsUp:          true,
+				Role:          &garage.NodeRole{Zone: "dc1", Capacity: &capacity, Tags: []string{"ssd"}},
+				DataPartition: &garage.DiskSpace{Available: 600, Total: 1000},
+			},
+			{ID: "n2", LastSeenSecsAgo: &lastSeen, Draining: true},
+		},
+	}
+
+	type want struct {
+		o          managed.ExternalObservation
+		err        error
+		conditions []xpv1.Condition
+		atProvider v1alpha1.GarageClusterObservation
+	}
+
+	cases := map[string]struct {
+		reason  string
+		client  clusterClient
+		deleted bool
+		prev    v1alpha1.GarageClusterObservation
+		want    want
+	}{
+		"Deleted": {
+			reason:  "Should report a deleted GarageCluster as gone without contacting the cluster",
+			client:  &mockClusterClient{err: errBoom},
+			deleted: true,
+			want:    want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"Unauthorized": {
+			reason: "Should report a rejected admin token as unauthorized",
+			client: &mockClusterClient{err: errDenied},
+			want: want{
+				err:        errors.Wrap(errDenied, errGetHealth),
+				conditions: []xpv1.Condition{v1.Unready(v1.ReasonUnauthorized, errDenied.Error())},
+			},
+		},
+		"Unreachable": {
+			reason: "Should report other errors as unreachable",
+			client: &mockClusterClient{err: errBoom},
+			want: want{
+				err:        errors.Wrap(errBoom, errGetHealth),
+				conditions: []xpv1.Condition{v1.Unready(v1.ReasonUnreachable, "boom")},
+			},
+		},
+		"Healthy": {
+			reason: "Should report a healthy cluster as ready, along with its nodes",
+			client: &mockClusterClient{health: healthy, status: status},
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				conditions: []xpv1.Condition{xpv1.Available()},
+				atProvider: v1alpha1.GarageClusterObservation{
+					Status:           garage.ClusterHealthy,
+					KnownNodes:       2,
+					ConnectedNodes:   2,
+					StorageNodes:     1,
+					StorageNodesOK:   1,
+					Partitions:       256,
+					PartitionsQuorum: 256,
+					PartitionsAllOK:  256,
+					GarageVersion:    "v1.0.1",
+					LayoutVersion:    4,
+					Nodes: []v1alpha1.GarageNodeObservation{
+						{
+							ID:            "n1",
+							Address:       addr,
+							Up:            true,
+							Role:          &v1alpha1.GarageNodeRole{Zone: "dc1", Capacity: &capacity, Tags: []string{"ssd"}},
+							DataPartition: &v1alpha1.GarageDiskSpace{Available: 600, Total: 1000},
+						},
+						{ID: "n2", LastSeenSecsAgo: &lastSeen, Draining: true},
+					},
+				},
+			},
+		},
+		"Degraded": {
+			reason: "Should report a degraded cluster as not ready",
+			client: &mockClusterClient{health: degraded, status: &garage.ClusterStatus{GarageVersion: "v1.0.1", LayoutVersion: 4}},
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				conditions: []xpv1.Condition{v1.Unready(v1.ReasonDegraded, "2/3 storage nodes up, 256/256 partitions have quorum")},
+				atProvider: v1alpha1.GarageClusterObservation{
+					Status:           garage.ClusterDegraded,
+					KnownNodes:       3,
+					ConnectedNodes:   2,
+					StorageNodes:     3,
+					StorageNodesOK:   2,
+					Partitions:       256,
+					PartitionsQuorum: 256,
+					GarageVersion:    "v1.0.1",
+					LayoutVersion:    4,
+				},
+			},
+		},
+		"Unavailable": {
+			reason: "Should report an unavailable cluster as not ready",
+			client: &mockClusterClient{health: unavailable, status: &garage.ClusterStatus{}},
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				conditions: []xpv1.Condition{v1.Unready(v1.ReasonUnavailable, "cluster is unavailable, 0/256 partitions have quorum")},
+				atProvider: v1alpha1.GarageClusterObservation{
+					Status:         garage.ClusterUnavailable,
+					KnownNodes:     3,
+					ConnectedNodes: 1,
+					StorageNodes:   3,
+					StorageNodesOK: 1,
+					Partitions:     256,
+				},
+			},
+		},
+		"StatusFailed": {
+			reason: "Should report the health, keep the nodes last observed and return the error if the status cannot be read",
+			client: &mockClusterClient{health: degraded, statusErr: errBoom},
+			prev: v1alpha1.GarageClusterObservation{
+				Status:        garage.ClusterHealthy,
+				GarageVersion: "v1.0.1",
+				LayoutVersion: 4,
+				Nodes:         []v1alpha1.GarageNodeObservation{{ID: "n1", Up: true}},
+			},
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				err:        errors.Wrap(errBoom, errGetStatus),
+				conditions: []xpv1.Condition{v1.Unready(v1.ReasonDegraded, "2/3 storage nodes up, 256/256 partitions have quorum")},
+				atProvider: v1alpha1.GarageClusterObservation{
+					Status:           garage.ClusterDegraded,
+					KnownNodes:       3,
+					ConnectedNodes:   2,
+					StorageNodes:     3,
+					StorageNodesOK:   2,
+					Partitions:       256,
+					PartitionsQuorum: 256,
+					GarageVersion:    "v1.0.1",
+					LayoutVersion:    4,
+					Nodes:            []v1alpha1.GarageNodeObservation{{ID: "n1", Up: true}},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.GarageCluster{Status: v1alpha1.GarageClusterStatus{AtProvider: tc.prev}}
+			if tc.deleted {
+				now := metav1.Now()
+				cr.SetDeletionTimestamp(&now)
+			}
+
+			e := &external{client: tc.client}
+			o, err := e.Observe(context.Background(), cr)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, o); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want observation, +got observation:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.conditions, cr.Status.Conditions, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want conditions, +got conditions:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want atProvider, +got atProvider:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
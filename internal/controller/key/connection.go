@@ -0,0 +1,200 @@
+package key
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+)
+
+const (
+	errUnknownFormat  = "unknown connection secret format"
+	errParseTemplate  = "cannot parse connection secret template"
+	errRenderTemplate = "cannot render connection secret template"
+)
+
+// credentials are the values available to connection secret formats and
+// templates.
+type credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	Name            string
+	Endpoint        string
+	Region          string
+}
+
+// The connection secret keys that hold the credentials of a key, whatever its
+// format.
+const (
+	keyAccessKeyID     = "accessKeyId"
+	keySecretAccessKey = "secretAccessKey"
+)
+
+// connectionDetails returns the connection details of a key: its access key
+// ID and secret, plus the keys of any configured formats and templates.
+func connectionDetails(f *v1alpha1.ConnectionSecretFormat, c credentials) (managed.ConnectionDetails, error) {
+	cd := managed.ConnectionDetails{
+		keyAccessKeyID:     []byte(c.AccessKeyID),
+		keySecretAccessKey: []byte(c.SecretAccessKey),
+	}
+	if f == nil {
+		return cd, nil
+	}
+
+	for _, t := range f.Formats {
+		fn, ok := formats[t]
+		if !ok {
+			return nil, errors.Errorf("%s %q", errUnknownFormat, t)
+		}
+		for k, v := range fn(c) {
+			cd[k] = []byte(v)
+		}
+	}
+
+	for k, text := range f.Templates {
+		tmpl, err := template.New(k).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s %q", errParseTemplate, k)
+		}
+		buf := &bytes.Buffer{}
+		if err := tmpl.Execute(buf, c); err != nil {
+			return nil, errors.Wrapf(err, "%s %q", errRenderTemplate, k)
+		}
+		cd[k] = buf.Bytes()
+	}
+
+	return cd, nil
+}
+
+// formatHash returns a hash of a connection secret format and the endpoint and
+// region it is rendered with.
+func formatHash(f *v1alpha1.ConnectionSecretFormat, endpoint, region string) string {
+	// Marshalling a format cannot fail, and sorts the keys of its templates.
+	b, _ := json.Marshal(struct {
+		Format   *v1alpha1.ConnectionSecretFormat `json:"format"`
+		Endpoint string                           `json:"endpoint"`
+		Region   string                           `json:"region"`
+	}{Format: f, Endpoint: endpoint, Region: region})
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// pruneConnectionSecret removes the keys that are neither credentials nor in
+// the supplied connection details from the connection secret of a key.
+// Connection details are merged into the secret when they are published, so
+// the keys of a format that is no longer configured would otherwise be left
+// behind. Secrets that are missing or not controlled by the key are left
+// alone.
+func pruneConnectionSecret(ctx context.Context, kube client.Client, cr *v1alpha1.Key, keep managed.ConnectionDetails) error {
+	ref := cr.GetWriteConnectionSecretToReference()
+	if ref == nil {
+		return nil
+	}
+	s := &corev1.Secret{}
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if c := metav1.GetControllerOf(s); c == nil || c.UID != cr.GetUID() {
+		return nil
+	}
+
+	pruned := false
+	for k := range s.Data {
+		if _, ok := keep[k]; ok || k == keyAccessKeyID || k == keySecretAccessKey {
+			continue
+		}
+		delete(s.Data, k)
+		pruned = true
+	}
+	if !pruned {
+		return nil
+	}
+	return kube.Update(ctx, s)
+}
+
+// formats render the keys of each built-in connection secret format.
+var formats = map[v1alpha1.ConnectionSecretFormatType]func(credentials) map[string]string{
+	v1alpha1.ConnectionSecretFormatAWSEnv:             awsEnv,
+	v1alpha1.ConnectionSecretFormatAWSCredentialsFile: awsCredentialsFile,
+	v1alpha1.ConnectionSecretFormatRclone:             rclone,
+	v1alpha1.ConnectionSecretFormatS3cmd:              s3cmd,
+}
+
+func awsEnv(c credentials) map[string]string {
+	env := map[string]string{
+		"AWS_ACCESS_KEY_ID":     c.AccessKeyID,
+		"AWS_SECRET_ACCESS_KEY": c.SecretAccessKey,
+	}
+	if c.Endpoint != "" {
+		env["AWS_ENDPOINT_URL"] = c.Endpoint
+	}
+	if c.Region != "" {
+		env["AWS_REGION"] = c.Region
+	}
+	return env
+}
+
+func awsCredentialsFile(c credentials) map[string]string {
+	creds := fmt.Sprintf("[default]\naws_access_key_id = %s\naws_secret_access_key = %s\n", c.AccessKeyID, c.SecretAccessKey)
+
+	config := &strings.Builder{}
+	config.WriteString("[default]\n")
+	if c.Region != "" {
+		fmt.Fprintf(config, "region = %s\n", c.Region)
+	}
+	if c.Endpoint != "" {
+		fmt.Fprintf(config, "endpoint_url = %s\n", c.Endpoint)
+	}
+
+	return map[string]string{"credentials": creds, "config": config.String()}
+}
+
+func rclone(c credentials) map[string]string {
+	conf := &strings.Builder{}
+	conf.WriteString("[garage]\ntype = s3\nprovider = Other\n")
+	fmt.Fprintf(conf, "access_key_id = %s\nsecret_access_key = %s\n", c.AccessKeyID, c.SecretAccessKey)
+	if c.Endpoint != "" {
+		fmt.Fprintf(conf, "endpoint = %s\n", c.Endpoint)
+	}
+	if c.Region != "" {
+		fmt.Fprintf(conf, "region = %s\n", c.Region)
+	}
+	return map[string]string{"rclone.conf": conf.String()}
+}
+
+func s3cmd(c credentials) map[string]string {
+	conf := &strings.Builder{}
+	conf.WriteString("[default]\n")
+	fmt.Fprintf(conf, "access_key = %s\nsecret_key = %s\n", c.AccessKeyID, c.SecretAccessKey)
+	if c.Endpoint != "" {
+		host, https := c.Endpoint, true
+		if u, err := url.Parse(c.Endpoint); err == nil && u.Host != "" {
+			host, https = u.Host, u.Scheme != "http"
+		}
+		fmt.Fprintf(conf, "host_base = %s\nhost_bucket = %s\n", host, host)
+		if https {
+			conf.WriteString("use_https = True\n")
+		} else {
+			conf.WriteString("use_https = False\n")
+		}
+	}
+	if c.Region != "" {
+		fmt.Fprintf(conf, "bucket_location = %s\n", c.Region)
+	}
+	return map[string]string{".s3cfg": conf.String()}
+}
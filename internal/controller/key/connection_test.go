@@ -0,0 +1,359 @@
+package key
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+)
+
+func TestConnectionDetails(t *testing.T) {
+	c := credentials{
+		AccessKeyID:     "GK123",
+		SecretAccessKey: "secret",
+		Name:            "my-key",
+		Endpoint:        "http://s3.example.com:3900",
+		Region:          "garage",
+	}
+
+	type want struct {
+		cd  managed.ConnectionDetails
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		f      *v1alpha1.ConnectionSecretFormat
+		want   want
+	}{
+		"NoFormat": {
+			reason: "Should return only the access key ID and secret without a format",
+			want: want{cd: managed.ConnectionDetails{
+				"accessKeyId":     []byte("GK123"),
+				"secretAccessKey": []byte("secret"),
+			}},
+		},
+		"AWSEnv": {
+			reason: "Should add the AWS environment variables",
+			f:      &v1alpha1.ConnectionSecretFormat{Formats: []v1alpha1.ConnectionSecretFormatType{v1alpha1.ConnectionSecretFormatAWSEnv}},
+			want: want{cd: managed.ConnectionDetails{
+				"accessKeyId":           []byte("GK123"),
+				"secretAccessKey":       []byte("secret"),
+				"AWS_ACCESS_KEY_ID":     []byte("GK123"),
+				"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+				"AWS_ENDPOINT_URL":      []byte("http://s3.example.com:3900"),
+				"AWS_REGION":            []byte("garage"),
+			}},
+		},
+		"S3cmd": {
+			reason: "Should render an s3cmd configuration using the endpoint host and scheme",
+			f:      &v1alpha1.ConnectionSecretFormat{Formats: []v1alpha1.ConnectionSecretFormatType{v1alpha1.ConnectionSecretFormatS3cmd}},
+			want: want{cd: managed.ConnectionDetails{
+				"accessKeyId":     []byte("GK123"),
+				"secretAccessKey": []byte("secret"),
+				".s3cfg": []byte("[default]\naccess_key = GK123\nsecret_key = secret\n" +
+					"host_base = s3.example.com:3900\nhost_bucket = s3.example.com:3900\nuse_https = False\n" +
+					"bucket_location = garage\n"),
+			}},
+		},
+		"Template": {
+			reason: "Should render templates under their key",
+			f:      &v1alpha1.ConnectionSecretFormat{Templates: map[string]string{"url": "{{ .Endpoint }}/{{ .Name }}"}},
+			want: want{cd: managed.ConnectionDetails{
+				"accessKeyId":     []byte("GK123"),
+				"secretAccessKey": []byte("secret"),
+				"url":             []byte("http://s3.example.com:3900/my-key"),
+			}},
+		},
+		"UnknownFormat": {
+			reason: "Should return an error for an unknown format",
+			f:      &v1alpha1.ConnectionSecretFormat{Formats: []v1alpha1.ConnectionSecretFormatType{"boto"}},
+			want:   want{err: errors.Errorf("%s %q", errUnknownFormat, "boto")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := connectionDetails(tc.f, c)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nconnectionDetails(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cd, got); diff != "" {
+				t.Errorf("\n%s\nconnectionDetails(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// memoryStore is an in-memory External Secret Store, standing in for a
+// secret store plugin.
+type memoryStore map[store.ScopedName]store.KeyValues
+
+func (m memoryStore) ReadKeyValues(_ context.Context, n store.ScopedName, s *store.Secret) error {
+	s.ScopedName = n
+	s.Data = m[n]
+	return nil
+}
+
+func (m memoryStore) WriteKeyValues(_ context.Context, s *store.Secret, _ ...store.WriteOption) (bool, error) {
+	m[s.ScopedName] = s.Data
+	return true, nil
+}
+
+func (m memoryStore) DeleteKeyValues(_ context.Context, s *store.Secret, _ ...store.DeleteOption) error {
+	delete(m, s.ScopedName)
+	return nil
+}
+
+func TestPublishToStore(t *testing.T) {
+	errBoom := errors.New("boom")
+	c := credentials{AccessKeyID: "GK123", SecretAccessKey: "secret"}
+
+	key := func() *v1alpha1.Key {
+		cr := &v1alpha1.Key{ObjectMeta: metav1.ObjectMeta{Name: "my-key", Namespace: "team-a", UID: "uid"}}
+		cr.SetPublishConnectionDetailsTo(&xpv1.PublishConnectionDetailsTo{
+			Name:                 "my-key-credentials",
+			SecretStoreConfigRef: &xpv1.Reference{Name: "memory"},
+		})
+		return cr
+	}
+
+	type want struct {
+		store memoryStore
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		get    test.MockGetFn
+		cr     *v1alpha1.Key
+		want   want
+	}{
+		"NoPublishConnectionDetailsTo": {
+			reason: "Should not publish anything when publishConnectionDetailsTo is unset",
+			cr:     &v1alpha1.Key{ObjectMeta: metav1.ObjectMeta{Name: "my-key", Namespace: "team-a"}},
+			want:   want{store: memoryStore{}},
+		},
+		"Published": {
+			reason: "Should publish the key credentials to the store of the referenced StoreConfig",
+			get:    test.NewMockGetFn(nil),
+			cr:     key(),
+			want: want{store: memoryStore{
+				store.ScopedName{Name: "my-key-credentials", Scope: "team-a"}: store.KeyValues{
+					"accessKeyId":     []byte("GK123"),
+					"secretAccessKey": []byte("secret"),
+				},
+			}},
+		},
+		"StoreConfigNotFound": {
+			reason: "Should return an error when the StoreConfig cannot be found",
+			get:    test.NewMockGetFn(errBoom),
+			cr:     key(),
+			want: want{
+				store: memoryStore{},
+				err:   fmt.Errorf("cannot connect to secret store: %w", fmt.Errorf("cannot get store config: %w", errBoom)),
+			},
+		},
+	}
+
+	s := runtime.NewScheme()
+	if err := v1alpha1.SchemeBuilder.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ms := memoryStore{}
+			kube := &test.MockClient{MockGet: tc.get, MockScheme: test.NewMockSchemeFn(s)}
+			m := connection.NewDetailsManager(kube, v1alpha1.StoreConfigGroupVersionKind,
+				connection.WithStoreBuilder(func(_ context.Context, _ client.Client, _ *tls.Config, _ xpv1.SecretStoreConfig) (connection.Store, error) {
+					return ms, nil
+				}))
+
+			cd, err := connectionDetails(nil, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = m.PublishConnection(context.Background(), tc.cr, cd)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPublishConnection(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.store, ms); diff != "" {
+				t.Errorf("\n%s\nPublishConnection(...): -want store, +got store:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFormatHash(t *testing.T) {
+	f := &v1alpha1.ConnectionSecretFormat{
+		Formats:   []v1alpha1.ConnectionSecretFormatType{v1alpha1.ConnectionSecretFormatAWSEnv},
+		Templates: map[string]string{"a": "{{ .AccessKeyID }}", "b": "{{ .Name }}"},
+	}
+	h := formatHash(f, "http://s3.example.com", "garage")
+
+	cases := map[string]struct {
+		reason   string
+		f        *v1alpha1.ConnectionSecretFormat
+		endpoint string
+		region   string
+		same     bool
+	}{
+		"Same": {
+			reason:   "Should return the same hash for the same format, endpoint and region",
+			f:        f.DeepCopy(),
+			endpoint: "http://s3.example.com",
+			region:   "garage",
+			same:     true,
+		},
+		"TemplateChanged": {
+			reason:   "Should return another hash if a template changed",
+			f:        &v1alpha1.ConnectionSecretFormat{Formats: f.Formats, Templates: map[string]string{"a": "{{ .AccessKeyID }}"}},
+			endpoint: "http://s3.example.com",
+			region:   "garage",
+		},
+		"EndpointChanged": {
+			reason:   "Should return another hash if the endpoint changed",
+			f:        f.DeepCopy(),
+			endpoint: "http://s3.other.example.com",
+			region:   "garage",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.same, formatHash(tc.f, tc.endpoint, tc.region) == h); diff != "" {
+				t.Errorf("\n%s\nformatHash(...) == hash: -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPruneConnectionSecret(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cr := &v1alpha1.Key{ObjectMeta: metav1.ObjectMeta{Name: "my-key", Namespace: "team-a", UID: "uid"}}
+	cr.SetWriteConnectionSecretToReference(&xpv1.SecretReference{Name: "my-key-credentials", Namespace: "team-a"})
+
+	secret := func(uid types.UID, data map[string][]byte) *corev1.Secret {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-key-credentials", Namespace: "team-a"}, Data: data}
+		meta.AddControllerReference(s, metav1.OwnerReference{UID: uid, Controller: ptr.To(true)})
+		return s
+	}
+	data := map[string][]byte{
+		"accessKeyId":       []byte("GK123"),
+		"secretAccessKey":   []byte("secret"),
+		"AWS_ACCESS_KEY_ID": []byte("GK123"),
+		"rclone.conf":       []byte("[garage]"),
+	}
+
+	type want struct {
+		updated *corev1.Secret
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Key
+		get    test.MockGetFn
+		keep   managed.ConnectionDetails
+		want   want
+	}{
+		"NoWriteConnectionSecretToRef": {
+			reason: "Should do nothing if the key writes no connection secret",
+			cr:     &v1alpha1.Key{},
+		},
+		"NotFound": {
+			reason: "Should do nothing if the connection secret does not exist",
+			cr:     cr,
+			get:    test.NewMockGetFn(kerrors.NewNotFound(corev1.Resource("secrets"), "my-key-credentials")),
+		},
+		"GetFailed": {
+			reason: "Should return an error if the connection secret cannot be read",
+			cr:     cr,
+			get:    test.NewMockGetFn(errBoom),
+			want:   want{err: errBoom},
+		},
+		"NotControlled": {
+			reason: "Should leave a secret the key does not control alone",
+			cr:     cr,
+			get: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+				secret("other", data).DeepCopyInto(obj.(*corev1.Secret))
+				return nil
+			},
+		},
+		"Pruned": {
+			reason: "Should remove the keys of formats that are no longer configured",
+			cr:     cr,
+			get: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+				secret("uid", data).DeepCopyInto(obj.(*corev1.Secret))
+				return nil
+			},
+			keep: managed.ConnectionDetails{"accessKeyId": nil, "secretAccessKey": nil, "AWS_ACCESS_KEY_ID": nil},
+			want: want{updated: secret("uid", map[string][]byte{
+				"accessKeyId":       []byte("GK123"),
+				"secretAccessKey":   []byte("secret"),
+				"AWS_ACCESS_KEY_ID": []byte("GK123"),
+			})},
+		},
+		"KeepCredentials": {
+			reason: "Should keep the credentials when no format is configured",
+			cr:     cr,
+			get: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+				secret("uid", data).DeepCopyInto(obj.(*corev1.Secret))
+				return nil
+			},
+			want: want{updated: secret("uid", map[string][]byte{
+				"accessKeyId":     []byte("GK123"),
+				"secretAccessKey": []byte("secret"),
+			})},
+		},
+		"NothingToPrune": {
+			reason: "Should not update a secret that only holds current keys",
+			cr:     cr,
+			get: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+				secret("uid", map[string][]byte{"accessKeyId": []byte("GK123")}).DeepCopyInto(obj.(*corev1.Secret))
+				return nil
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var updated *corev1.Secret
+			kube := &test.MockClient{
+				MockGet: tc.get,
+				MockUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					updated = obj.(*corev1.Secret)
+					return nil
+				},
+			}
+			err := pruneConnectionSecret(context.Background(), kube, tc.cr, tc.keep)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\npruneConnectionSecret(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.updated, updated); diff != "" {
+				t.Errorf("\n%s\npruneConnectionSecret(...): -want updated secret, +got updated secret:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
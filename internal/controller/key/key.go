@@ -3,16 +3,24 @@ package key
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
-	"k8s.io/apimachinery/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
@@ -20,89 +28,164 @@ import (
 
 	v1 "github.com/kikokikok/provider-garage/apis/v1"
 	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/internal/clients"
+	"github.com/kikokikok/provider-garage/internal/dependency"
+	"github.com/kikokikok/provider-garage/internal/features"
+	"github.com/kikokikok/provider-garage/internal/grant"
+	"github.com/kikokikok/provider-garage/internal/tracing"
 	"github.com/kikokikok/provider-garage/pkg/garage"
 )
 
 const (
-	errNotKey       = "managed resource is not a Key custom resource"
-	errTrackPCUsage = "cannot track ProviderConfig usage"
-	errGetPC        = "cannot get ProviderConfig"
-	errGetCreds     = "cannot get credentials"
+	errNotKey       = "managed resource is not a Key or ClusterKey custom resource"
 	errCreateKey    = "cannot create key"
+	errUpdateKey    = "cannot update key"
+	errPruneSecret  = "cannot remove unused keys from the connection secret"
 	errDeleteKey    = "cannot delete key"
 	errGetKey       = "cannot get key"
+	errDependents   = "cannot determine resources granting access to key"
+	errGrantAccess  = "cannot grant key access"
+	errRevokeAccess = "cannot revoke key access"
+	errNoBucket     = "one of bucketId, bucketIdRef, bucketIdSelector or bucketAlias is required"
+	errUnresolved   = "cannot resolve bucketAccess"
+	errGrantedBy    = "cannot determine buckets granted by other resources"
 )
 
-// Setup adds a controller that reconciles Key managed resources.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
-	name := managed.ControllerName(v1alpha1.KeyGroupKind)
-
-	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+// Setup adds a controller that reconciles Key managed resources, which
+// connect to Garage with the supplied Connector.
+func Setup(mgr ctrl.Manager, o controller.Options, conn *clients.Connector) error {
+	return setup(mgr, o, conn, kind{
+		groupKind: v1alpha1.KeyGroupKind,
+		gvk:       v1alpha1.KeyGroupVersionKind,
+		object:    &v1alpha1.Key{},
+		list:      &v1alpha1.KeyList{},
+		usage:     clients.NewUsageTracker(mgr.GetClient()),
+		dependents: []dependents{
+			{object: &v1alpha1.KeyAccess{}, enqueue: dependency.EnqueueDeletingKeys(mgr.GetClient())},
+			{object: &v1alpha1.BucketAccessPolicy{}, enqueue: dependency.EnqueueDeletingKeys(mgr.GetClient())},
+		},
+	})
+}
 
-	r := managed.NewReconciler(mgr,
-		resource.ManagedKind(v1alpha1.KeyGroupVersionKind),
-		managed.WithExternalConnecter(&connector{
-			kube: mgr.GetClient(),
-		}),
-		managed.WithLogger(o.Logger.WithValues("controller", name)),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
-		managed.WithConnectionPublishers(cps...))
+// SetupCluster adds a controller that reconciles ClusterKey managed
+// resources. They connect to Garage with the supplied Connector, and their
+// ProviderConfigUsages are created in the supplied namespace.
+func SetupCluster(mgr ctrl.Manager, o controller.Options, conn *clients.Connector, namespace string) error {
+	return setup(mgr, o, conn, kind{
+		groupKind: v1alpha1.ClusterKeyGroupKind,
+		gvk:       v1alpha1.ClusterKeyGroupVersionKind,
+		object:    &v1alpha1.ClusterKey{},
+		list:      &v1alpha1.ClusterKeyList{},
+		usage:     clients.NewUsageTracker(mgr.GetClient(), clients.WithUsageNamespace(namespace)),
+		dependents: []dependents{
+			{object: &v1alpha1.KeyAccess{}, enqueue: dependency.EnqueueDeletingClusterKeys(mgr.GetClient())},
+			{object: &v1alpha1.ClusterKeyAccess{}, enqueue: dependency.EnqueueDeletingClusterKeys(mgr.GetClient())},
+			{object: &v1alpha1.BucketAccessPolicy{}, enqueue: dependency.EnqueueDeletingClusterKeys(mgr.GetClient())},
+		},
+	})
+}
 
-	return ctrl.NewControllerManagedBy(mgr).
-		Named(name).
-		WithOptions(o.ForControllerRuntime()).
-		For(&v1alpha1.Key{}).
-		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+// A kind of key reconciled by this controller.
+type kind struct {
+	groupKind  string
+	gvk        schema.GroupVersionKind
+	object     client.Object
+	list       resource.ManagedList
+	usage      resource.Tracker
+	dependents []dependents
 }
 
-type connector struct {
-	kube client.Client
+// dependents of a key, and how to map them to the key they depend on.
+type dependents struct {
+	object  client.Object
+	enqueue handler.MapFunc
 }
 
-func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
-	cr, ok := mg.(*v1alpha1.Key)
-	if !ok {
-		return nil, errors.New(errNotKey)
-	}
+func setup(mgr ctrl.Manager, o controller.Options, conn *clients.Connector, k kind) error {
+	name := managed.ControllerName(k.groupKind)
 
-	pc := &v1.ProviderConfig{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
-		return nil, errors.Wrap(err, errGetPC)
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), v1alpha1.StoreConfigGroupVersionKind, connection.WithTLSConfig(o.ESSOptions.TLSConfig)))
 	}
 
-	cd := pc.Spec.Credentials
-	data, err := resource.CommonCredentialExtractor(ctx, cd.Source, c.kube, cd.CommonCredentialSelectors)
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(k.gvk.Kind, clients.NewExternalConnecter(
+			k.usage,
+			conn,
+			newExternal(mgr.GetClient()),
+		))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...),
+	}
+	if o.Features.Enabled(feature.EnableBetaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+	mo, err := clients.MetricRecorders(mgr, o, k.list)
 	if err != nil {
-		return nil, errors.Wrap(err, errGetCreds)
+		return err
 	}
+	opts = append(opts, mo...)
 
-	creds := struct {
-		Endpoint   string `json:"endpoint"`
-		AdminToken string `json:"adminToken"`
-	}{}
-	if len(data) > 0 {
-		if err := json.Unmarshal(data, &creds); err != nil {
-			return nil, errors.Wrap(err, errGetCreds)
-		}
+	r := managed.NewReconciler(mgr, resource.ManagedKind(k.gvk), opts...)
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(k.object)
+	for _, d := range k.dependents {
+		b = b.Watches(d.object, handler.EnqueueRequestsFromMapFunc(d.enqueue))
 	}
+	return b.Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
 
-	endpoint := creds.Endpoint
-	if pc.Spec.Endpoint != nil && *pc.Spec.Endpoint != "" {
-		endpoint = *pc.Spec.Endpoint
+// asKey returns the supplied Key or ClusterKey as a Key. A ClusterKey is
+// converted in place, so changes to the returned Key are made to it.
+func asKey(mg resource.Managed) (*v1alpha1.Key, bool) {
+	switch cr := mg.(type) {
+	case *v1alpha1.Key:
+		return cr, true
+	case *v1alpha1.ClusterKey:
+		return (*v1alpha1.Key)(cr), true
 	}
+	return nil, false
+}
 
-	garageClient := garage.NewClient(endpoint, creds.AdminToken)
+// kindOf returns the kind of the supplied key, which is a ClusterKey if it has
+// no namespace.
+func kindOf(cr *v1alpha1.Key) string {
+	if cr.GetNamespace() == "" {
+		return v1alpha1.ClusterKeyKind
+	}
+	return v1alpha1.KeyKind
+}
 
-	return &external{client: garageClient, kube: c.kube}, nil
+// newExternal returns a function that builds the external client of a key.
+func newExternal(kube client.Client) clients.ExternalFn {
+	return func(_ resource.Managed, gc *garage.Client, pc *v1.ProviderConfigSpec) (managed.ExternalClient, error) {
+		ext := &external{client: gc, kube: kube}
+		if pc.S3Endpoint != nil {
+			ext.s3Endpoint = *pc.S3Endpoint
+		}
+		if pc.Region != nil {
+			ext.region = *pc.Region
+		}
+		return ext, nil
+	}
 }
 
 type external struct {
 	client *garage.Client
 	kube   client.Client
+
+	// s3Endpoint and region are published in connection secret formats.
+	s3Endpoint string
+	region     string
 }
 
 func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
-	cr, ok := mg.(*v1alpha1.Key)
+	cr, ok := asKey(mg)
 	if !ok {
 		return managed.ExternalObservation{}, errors.New(errNotKey)
 	}
@@ -113,15 +196,25 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	// and also in Status.AtProvider.AccessKeyID (which may be stale due to Crossplane's behavior)
 	externalName := meta.GetExternalName(cr)
 
-	// Try to find by external-name annotation first (this is the most reliable after Create)
+	// Try to find by external-name annotation first (this is the most reliable after Create).
+	// It is also how an existing key is imported. Only a key that does not
+	// exist may be created; any other error must not be mistaken for it.
 	if externalName != "" && externalName != cr.Name {
 		// external-name is set to the AccessKeyID
-		key, _ = e.client.GetKey(ctx, externalName)
+		var err error
+		key, err = e.client.GetKey(ctx, externalName)
+		if err != nil && !garage.IsNotFound(err) {
+			return managed.ExternalObservation{}, errors.Wrap(err, errGetKey)
+		}
 	}
 
 	// Fallback to Status.AtProvider.AccessKeyID
 	if key == nil && cr.Status.AtProvider.AccessKeyID != "" {
-		key, _ = e.client.GetKey(ctx, cr.Status.AtProvider.AccessKeyID)
+		var err error
+		key, err = e.client.GetKey(ctx, cr.Status.AtProvider.AccessKeyID)
+		if err != nil && !garage.IsNotFound(err) {
+			return managed.ExternalObservation{}, errors.Wrap(err, errGetKey)
+		}
 		if key == nil {
 			// Key doesn't exist by ID - it was deleted externally
 			cr.Status.AtProvider.AccessKeyID = ""
@@ -132,7 +225,11 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	// This handles the case where the key was created in Garage but the controller
 	// crashed before the external-name annotation could be saved.
 	if key == nil && cr.Spec.ForProvider.Name != "" {
-		key, _ = e.client.GetKeyByName(ctx, cr.Spec.ForProvider.Name)
+		var err error
+		key, err = e.client.GetKeyByName(ctx, cr.Spec.ForProvider.Name)
+		if err != nil && !garage.IsNotFound(err) {
+			return managed.ExternalObservation{}, errors.Wrap(err, errGetKey)
+		}
 		if key != nil {
 			// Update external name to the found ID so future lookups are faster
 			meta.SetExternalName(cr, key.AccessKeyID)
@@ -146,27 +243,52 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		}, nil
 	}
 
-	cr.Status.AtProvider.AccessKeyID = key.AccessKeyID
+	owned := ownedPermissions(cr.Status.AtProvider.Buckets)
+	published := cr.Status.AtProvider.ConnectionSecretFormatHash
+	cr.Status.AtProvider = observe(key)
+	cr.Status.AtProvider.ConnectionSecretFormatHash = published
 	cr.SetConditions(xpv1.Available())
 
+	want := e.resolveBucketAccess(ctx, cr)
+	allow, deny := bucketChanges(key.AccessKeyID, bucketPermissions(key), owned, want)
+	markOwned(cr.Status.AtProvider.Buckets, owned, want)
+
+	// The access of an adopted key is copied into its bucketAccess, except for
+	// the buckets other resources grant it access to.
+	var grantedBy []string
+	if adopted(cr) && cr.Spec.ForProvider.BucketAccess == nil {
+		var err error
+		if grantedBy, err = dependency.GrantedBuckets(ctx, e.kube, cr); err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errGrantedBy)
+		}
+	}
+
+	// Do NOT return connection details here. The secret key is not returned
+	// by the API on GET, only on CREATE, and returning partial details (ID
+	// only) causes Crossplane to overwrite the existing secret (which has the
+	// secret key), effectively deleting it. A changed connection secret
+	// format is published by Update instead.
 	return managed.ExternalObservation{
-		ResourceExists:   true,
-		ResourceUpToDate: true,
-		// Do NOT return connection details here.
-		// The secret key is not returned by the API on GET, only on CREATE.
-		// Returning partial details (ID only) causes Crossplane to overwrite
-		// the existing secret (which has the secret key), effectively deleting it.
+		ResourceExists:          true,
+		ResourceUpToDate:        keyUpdate(cr.Spec.ForProvider, key) == nil && len(allow) == 0 && len(deny) == 0 && unresolved(want) == nil && published == e.formatHash(cr),
+		ResourceLateInitialized: lateInitialize(&cr.Spec.ForProvider, key, adopted(cr), grantedBy),
 	}, nil
 }
 
 func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
-	cr, ok := mg.(*v1alpha1.Key)
+	cr, ok := asKey(mg)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotKey)
 	}
 
 	cr.SetConditions(xpv1.Creating())
 
+	// The secret key is returned only once, so make sure the connection
+	// secret format can be rendered before creating the key.
+	if _, err := connectionDetails(cr.Spec.ConnectionSecretFormat, credentials{}); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
 	req := &garage.CreateKeyRequest{
 		Name: cr.Spec.ForProvider.Name,
 	}
@@ -183,10 +305,21 @@ func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 	// Also set in status (will be overwritten during next Observe)
 	cr.Status.AtProvider.AccessKeyID = key.AccessKeyID
 
-	// Return connection details (access key ID and secret)
-	connDetails := managed.ConnectionDetails{
-		"accessKeyId":     []byte(key.AccessKeyID),
-		"secretAccessKey": []byte(key.SecretAccessKey),
+	// Return connection details (access key ID and secret, plus any formats).
+	connDetails, err := connectionDetails(cr.Spec.ConnectionSecretFormat, e.credentials(key))
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	// Record the format the connection details were rendered with, lest the
+	// first Observe publish them again. Crossplane reverts other changes made
+	// here when it persists the external name, so the status is written
+	// first; should that fail, the format is merely published again.
+	cr.Status.AtProvider.ConnectionSecretFormatHash = e.formatHash(cr)
+	if cr.Status.AtProvider.ConnectionSecretFormatHash != "" {
+		_ = e.kube.Status().Update(ctx, cr)
+		// The update returns the persisted metadata, without the external name.
+		meta.SetExternalName(cr, key.AccessKeyID)
 	}
 
 	return managed.ExternalCreation{
@@ -195,12 +328,97 @@ func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 }
 
 func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
-	// Keys are immutable for now
-	return managed.ExternalUpdate{}, nil
+	cr, ok := asKey(mg)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotKey)
+	}
+
+	// The name of a key cannot be changed; its permissions, expiration and
+	// bucket grants are updated.
+	key, err := e.client.GetKey(ctx, cr.Status.AtProvider.AccessKeyID)
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errGetKey)
+	}
+
+	if req := keyUpdate(cr.Spec.ForProvider, key); req != nil {
+		if _, err := e.client.UpdateKey(ctx, req); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateKey)
+		}
+	}
+
+	owned := ownedPermissions(cr.Status.AtProvider.Buckets)
+	want := e.resolveBucketAccess(ctx, cr)
+	allow, deny := bucketChanges(key.AccessKeyID, bucketPermissions(key), owned, want)
+
+	for _, req := range allow {
+		if _, err := e.client.GrantKeyAccess(ctx, req); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrapf(err, "%s on bucket %s", errGrantAccess, req.BucketID)
+		}
+	}
+	for _, req := range deny {
+		if _, err := e.client.RevokeKeyAccess(ctx, req); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrapf(err, "%s on bucket %s", errRevokeAccess, req.BucketID)
+		}
+	}
+
+	// Permissions no longer listed have been revoked, unless an entry that
+	// could not be resolved might still list them.
+	err = unresolved(want)
+	if err == nil {
+		owned = nil
+	}
+	markOwned(cr.Status.AtProvider.Buckets, owned, want)
+
+	// Grants that could be resolved have been applied; report the rest so
+	// that the key is retried until they can be. Connection details are not
+	// published when an error is returned, so neither is the format.
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	h := e.formatHash(cr)
+	if h == cr.Status.AtProvider.ConnectionSecretFormatHash {
+		return managed.ExternalUpdate{}, nil
+	}
+	cd, err := e.publishFormat(ctx, cr, key.AccessKeyID)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	cr.Status.AtProvider.ConnectionSecretFormatHash = h
+	return managed.ExternalUpdate{ConnectionDetails: cd}, nil
+}
+
+// formatHash returns the hash of the connection secret format of a key and of
+// the values it is rendered with other than the credentials, or an empty
+// string if the key has no format.
+func (e *external) formatHash(cr *v1alpha1.Key) string {
+	if cr.Spec.ConnectionSecretFormat == nil {
+		return ""
+	}
+	return formatHash(cr.Spec.ConnectionSecretFormat, e.s3Endpoint, e.region)
+}
+
+// publishFormat returns the connection details of a key rendered with its
+// connection secret format, and removes the keys of formats that are no
+// longer configured from its connection secret. The secret key is fetched
+// explicitly, as it is not otherwise returned once the key was created.
+func (e *external) publishFormat(ctx context.Context, cr *v1alpha1.Key, accessKeyID string) (managed.ConnectionDetails, error) {
+	var cd managed.ConnectionDetails
+	if cr.Spec.ConnectionSecretFormat != nil {
+		key, err := e.client.GetKeyWithSecret(ctx, accessKeyID)
+		if err != nil {
+			return nil, errors.Wrap(err, errGetKey)
+		}
+		cd, err = connectionDetails(cr.Spec.ConnectionSecretFormat, e.credentials(key))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cd, errors.Wrap(pruneConnectionSecret(ctx, e.kube, cr, cd), errPruneSecret)
 }
 
 func (e *external) Delete(ctx context.Context, mg resource.Managed) (managed.ExternalDelete, error) {
-	cr, ok := mg.(*v1alpha1.Key)
+	cr, ok := asKey(mg)
 	if !ok {
 		return managed.ExternalDelete{}, errors.New(errNotKey)
 	}
@@ -220,9 +438,274 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalDelete{}, nil
 	}
 
+	// Garage silently drops a key's grants when the key is deleted, so hold
+	// off while KeyAccess or BucketAccessPolicy resources still depend on it
+	// unless forced.
+	if !v1alpha1.ForceDelete(cr) {
+		deps, err := dependency.KeyDependents(ctx, e.kube, cr)
+		if err != nil {
+			return managed.ExternalDelete{}, errors.Wrap(err, errDependents)
+		}
+		if len(deps) > 0 {
+			msg := dependency.Message(kindOf(cr), deps)
+			cr.SetConditions(v1alpha1.DeletionBlocked(msg))
+			return managed.ExternalDelete{}, errors.New(msg)
+		}
+	}
+
 	return managed.ExternalDelete{}, errors.Wrap(e.client.DeleteKey(ctx, accessKeyID), errDeleteKey)
 }
 
 func (e *external) Disconnect(ctx context.Context) error {
 	return nil
 }
+
+// credentials returns the values available to connection secret formats.
+func (e *external) credentials(k *garage.Key) credentials {
+	return credentials{
+		AccessKeyID:     k.AccessKeyID,
+		SecretAccessKey: k.SecretAccessKey,
+		Name:            k.Name,
+		Endpoint:        e.s3Endpoint,
+		Region:          e.region,
+	}
+}
+
+// resolvedBucket is an entry of bucketAccess resolved to a bucket ID.
+type resolvedBucket struct {
+	bucketID    string
+	permissions v1alpha1.KeyAccessPermissions
+	err         error
+}
+
+// resolveBucketAccess resolves the bucket ID of each entry of bucketAccess,
+// in the order of the spec. Entries that cannot be resolved carry an error.
+func (e *external) resolveBucketAccess(ctx context.Context, cr *v1alpha1.Key) []resolvedBucket {
+	rb := make([]resolvedBucket, len(cr.Spec.ForProvider.BucketAccess))
+	for i, a := range cr.Spec.ForProvider.BucketAccess {
+		rb[i].permissions = a.Permissions
+		rb[i].bucketID, rb[i].err = e.resolveBucketID(ctx, cr, a)
+	}
+	return rb
+}
+
+func (e *external) resolveBucketID(ctx context.Context, cr *v1alpha1.Key, a v1alpha1.KeyBucketAccess) (string, error) {
+	id, err := dependency.ResolveBucketID(ctx, e.kube, cr, a.BucketID, a.BucketIDRef, a.BucketIDSelector)
+	if err != nil || id != "" {
+		return id, err
+	}
+	if a.BucketAlias != nil && *a.BucketAlias != "" {
+		bucket, err := e.client.GetBucketByAlias(ctx, *a.BucketAlias)
+		if err != nil {
+			return "", err
+		}
+		return bucket.ID, nil
+	}
+	return "", errors.New(errNoBucket)
+}
+
+// observe returns the observable fields of the key.
+func observe(k *garage.Key) v1alpha1.KeyObservation {
+	obs := v1alpha1.KeyObservation{
+		AccessKeyID:  k.AccessKeyID,
+		Name:         k.Name,
+		CreateBucket: k.Permissions.CreateBucket,
+		Expired:      k.Expired,
+		Buckets:      observeBuckets(k),
+	}
+	if k.Created != nil {
+		t := metav1.NewTime(*k.Created)
+		obs.CreationTime = &t
+	}
+	if k.Expiration != nil {
+		t := metav1.NewTime(*k.Expiration)
+		obs.Expiration = &t
+	}
+	return obs
+}
+
+// adopted returns true if the key was not created by the provider, i.e. it
+// was imported.
+func adopted(cr *v1alpha1.Key) bool {
+	return meta.GetExternalCreatePending(cr).IsZero() && meta.GetExternalCreateSucceeded(cr).IsZero()
+}
+
+// lateInitialize fills the unset parameters of a key from the observed key, so
+// that the spec of an adopted key records its actual settings. Bucket access is
+// only filled for an adopted key, and not for the supplied buckets granted by
+// KeyAccess or BucketAccessPolicy resources, which manage that access. The
+// access of a key the provider created was granted by such resources too. It
+// returns true if any parameter was filled.
+func lateInitialize(p *v1alpha1.KeyParameters, k *garage.Key, adopted bool, grantedBy []string) bool {
+	li := false
+	if p.Permissions == nil {
+		p.Permissions = &v1alpha1.KeyPermissions{CreateBucket: k.Permissions.CreateBucket}
+		li = true
+	}
+	if p.Expiration == nil && k.Expiration != nil {
+		t := metav1.NewTime(*k.Expiration)
+		p.Expiration = &t
+		li = true
+	}
+	if adopted && p.BucketAccess == nil {
+		for _, b := range k.Buckets {
+			id := b.ID
+			perms := v1alpha1.KeyAccessPermissions{Read: b.Permissions.Read, Write: b.Permissions.Write, Owner: b.Permissions.Owner}
+			if perms == grant.None || slices.Contains(grantedBy, id) {
+				continue
+			}
+			p.BucketAccess = append(p.BucketAccess, v1alpha1.KeyBucketAccess{BucketID: &id, Permissions: perms})
+			li = true
+		}
+	}
+	return li
+}
+
+// keyUpdate returns the request that makes the permissions and expiration of
+// the observed key match the supplied parameters, or nil if they do. Unset
+// parameters are left as they are.
+func keyUpdate(p v1alpha1.KeyParameters, k *garage.Key) *garage.UpdateKeyRequest {
+	req := &garage.UpdateKeyRequest{AccessKeyID: k.AccessKeyID}
+	changed := false
+	if p.Permissions != nil && p.Permissions.CreateBucket != k.Permissions.CreateBucket {
+		perms := &garage.KeyPermissions{CreateBucket: true}
+		if p.Permissions.CreateBucket {
+			req.Allow = perms
+		} else {
+			req.Deny = perms
+		}
+		changed = true
+	}
+	// Expiration is stored with a precision of a second.
+	if e := p.Expiration; e != nil && (k.Expiration == nil || !e.Time.Truncate(time.Second).Equal(k.Expiration.Truncate(time.Second))) {
+		t := e.Time
+		req.Expiration = &t
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return req
+}
+
+// observeBuckets returns the buckets the key has access to.
+func observeBuckets(k *garage.Key) []v1alpha1.KeyBucketObservation {
+	if len(k.Buckets) == 0 {
+		return nil
+	}
+	obs := make([]v1alpha1.KeyBucketObservation, len(k.Buckets))
+	for i, b := range k.Buckets {
+		obs[i] = v1alpha1.KeyBucketObservation{
+			ID:            b.ID,
+			GlobalAliases: b.GlobalAliases,
+			LocalAliases:  b.LocalAliases,
+			Permissions: v1alpha1.KeyAccessPermissions{
+				Read:  b.Permissions.Read,
+				Write: b.Permissions.Write,
+				Owner: b.Permissions.Owner,
+			},
+		}
+	}
+	return obs
+}
+
+// bucketPermissions returns the permissions of the key on each bucket it has
+// access to.
+func bucketPermissions(k *garage.Key) map[string]v1alpha1.KeyAccessPermissions {
+	p := make(map[string]v1alpha1.KeyAccessPermissions, len(k.Buckets))
+	for _, b := range k.Buckets {
+		p[b.ID] = v1alpha1.KeyAccessPermissions{
+			Read:  b.Permissions.Read,
+			Write: b.Permissions.Write,
+			Owner: b.Permissions.Owner,
+		}
+	}
+	return p
+}
+
+// ownedPermissions returns the permissions the key granted itself on each
+// observed bucket with bucketAccess.
+func ownedPermissions(obs []v1alpha1.KeyBucketObservation) map[string]v1alpha1.KeyAccessPermissions {
+	owned := map[string]v1alpha1.KeyAccessPermissions{}
+	for _, b := range obs {
+		if b.BucketAccessPermissions != nil {
+			owned[b.ID] = *b.BucketAccessPermissions
+		}
+	}
+	return owned
+}
+
+// markOwned records the permissions the key grants itself on each observed
+// bucket with bucketAccess: the listed permissions, and those of the supplied
+// permissions it still has.
+func markOwned(obs []v1alpha1.KeyBucketObservation, owned map[string]v1alpha1.KeyAccessPermissions, want []resolvedBucket) {
+	desired, _ := desiredBuckets(want)
+	for i := range obs {
+		p := grant.Union(desired[obs[i].ID], grant.Intersection(owned[obs[i].ID], obs[i].Permissions))
+		obs[i].BucketAccessPermissions = nil
+		if p != grant.None {
+			obs[i].BucketAccessPermissions = &p
+		}
+	}
+}
+
+// desiredBuckets returns the permissions the key should have on each listed
+// bucket that could be resolved, and the buckets in the order of the spec. A
+// bucket listed more than once gets the union of its permissions.
+func desiredBuckets(want []resolvedBucket) (map[string]v1alpha1.KeyAccessPermissions, []string) {
+	desired := map[string]v1alpha1.KeyAccessPermissions{}
+	var order []string
+	for _, b := range want {
+		if b.err != nil {
+			continue
+		}
+		if _, ok := desired[b.bucketID]; !ok {
+			order = append(order, b.bucketID)
+		}
+		desired[b.bucketID] = grant.Union(desired[b.bucketID], b.permissions)
+	}
+	return desired, order
+}
+
+// bucketChanges returns the requests that grant the listed permissions the key
+// lacks on each listed bucket, and revoke the owned permissions that are no
+// longer listed. Other permissions are left alone, as they were granted by
+// KeyAccess or BucketAccessPolicy resources. Nothing is revoked while an entry
+// cannot be resolved, as it might still list the permissions.
+func bucketChanges(accessKeyID string, current, owned map[string]v1alpha1.KeyAccessPermissions, want []resolvedBucket) ([]*garage.GrantKeyAccessRequest, []*garage.RevokeKeyAccessRequest) {
+	desired, order := desiredBuckets(want)
+
+	var allow []*garage.GrantKeyAccessRequest
+	for _, id := range order {
+		if a, _ := grant.Diff(current[id], desired[id]); a != grant.None {
+			allow = append(allow, grant.Allow(id, accessKeyID, a))
+		}
+	}
+
+	if unresolved(want) != nil {
+		return allow, nil
+	}
+	var deny []*garage.RevokeKeyAccessRequest
+	for _, id := range slices.Sorted(maps.Keys(owned)) {
+		_, unlisted := grant.Diff(owned[id], desired[id])
+		if r := grant.Intersection(unlisted, current[id]); r != grant.None {
+			deny = append(deny, grant.Deny(id, accessKeyID, r))
+		}
+	}
+	return allow, deny
+}
+
+// unresolved returns an error describing the bucketAccess entries that could
+// not be resolved, or nil if all of them were.
+func unresolved(want []resolvedBucket) error {
+	var msgs []string
+	for i, b := range want {
+		if b.err != nil {
+			msgs = append(msgs, fmt.Sprintf("bucketAccess[%d]: %s", i, b.err))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.Errorf("%s: %s", errUnresolved, strings.Join(msgs, "; "))
+}
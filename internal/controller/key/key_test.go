@@ -2,11 +2,17 @@ package key
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
@@ -15,6 +21,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/internal/grant"
 	"github.com/kikokikok/provider-garage/pkg/garage"
 )
 
@@ -655,3 +662,733 @@ func TestKeyDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestBucketChanges(t *testing.T) {
+	rw := v1alpha1.KeyAccessPermissions{Read: true, Write: true}
+	ro := v1alpha1.KeyAccessPermissions{Read: true}
+
+	allowReq := func(bucketID string, p v1alpha1.KeyAccessPermissions) *garage.GrantKeyAccessRequest {
+		req := &garage.GrantKeyAccessRequest{BucketID: bucketID, AccessKeyID: "GK123456"}
+		req.Permissions.Read, req.Permissions.Write, req.Permissions.Owner = p.Read, p.Write, p.Owner
+		return req
+	}
+	denyReq := func(bucketID string, p v1alpha1.KeyAccessPermissions) *garage.RevokeKeyAccessRequest {
+		req := &garage.RevokeKeyAccessRequest{BucketID: bucketID, AccessKeyID: "GK123456"}
+		req.Permissions.Read, req.Permissions.Write, req.Permissions.Owner = p.Read, p.Write, p.Owner
+		return req
+	}
+
+	type want struct {
+		allow []*garage.GrantKeyAccessRequest
+		deny  []*garage.RevokeKeyAccessRequest
+	}
+
+	cases := map[string]struct {
+		reason  string
+		current map[string]v1alpha1.KeyAccessPermissions
+		owned   map[string]v1alpha1.KeyAccessPermissions
+		want    []resolvedBucket
+		o       want
+	}{
+		"UpToDate": {
+			reason:  "Should not change anything when the key has the listed permissions",
+			current: map[string]v1alpha1.KeyAccessPermissions{"bucket-1": rw},
+			owned:   map[string]v1alpha1.KeyAccessPermissions{"bucket-1": rw},
+			want:    []resolvedBucket{{bucketID: "bucket-1", permissions: rw}},
+			o:       want{},
+		},
+		"AllowAndDeny": {
+			reason:  "Should allow missing permissions on listed buckets and deny owned permissions that are no longer listed",
+			current: map[string]v1alpha1.KeyAccessPermissions{"bucket-1": rw},
+			owned:   map[string]v1alpha1.KeyAccessPermissions{"bucket-1": rw},
+			want: []resolvedBucket{
+				{bucketID: "bucket-1", permissions: ro},
+				{bucketID: "bucket-2", permissions: rw},
+			},
+			o: want{
+				allow: []*garage.GrantKeyAccessRequest{allowReq("bucket-2", rw)},
+				deny:  []*garage.RevokeKeyAccessRequest{denyReq("bucket-1", v1alpha1.KeyAccessPermissions{Write: true})},
+			},
+		},
+		"GrantedByOthers": {
+			reason:  "Should leave permissions the key does not own on a listed bucket alone",
+			current: map[string]v1alpha1.KeyAccessPermissions{"bucket-1": grant.All},
+			owned:   map[string]v1alpha1.KeyAccessPermissions{"bucket-1": ro},
+			want:    []resolvedBucket{{bucketID: "bucket-1", permissions: ro}},
+			o:       want{},
+		},
+		"UnlistedAndUnresolved": {
+			reason:  "Should leave unlisted buckets alone and skip unresolved entries",
+			current: map[string]v1alpha1.KeyAccessPermissions{"bucket-9": rw},
+			want:    []resolvedBucket{{err: errors.New("boom"), permissions: rw}},
+			o:       want{},
+		},
+		"EntryRemoved": {
+			reason:  "Should revoke the owned permissions on a bucket that is no longer listed",
+			current: map[string]v1alpha1.KeyAccessPermissions{"bucket-1": rw, "bucket-2": grant.All, "bucket-9": rw},
+			owned:   map[string]v1alpha1.KeyAccessPermissions{"bucket-1": rw, "bucket-2": ro},
+			want:    []resolvedBucket{{bucketID: "bucket-1", permissions: rw}},
+			o: want{
+				deny: []*garage.RevokeKeyAccessRequest{denyReq("bucket-2", ro)},
+			},
+		},
+		"AllEntriesRemoved": {
+			reason:  "Should revoke all owned permissions when no bucket is listed",
+			current: map[string]v1alpha1.KeyAccessPermissions{"bucket-1": rw, "bucket-2": ro},
+			owned:   map[string]v1alpha1.KeyAccessPermissions{"bucket-1": rw, "bucket-2": ro},
+			o: want{
+				deny: []*garage.RevokeKeyAccessRequest{denyReq("bucket-1", rw), denyReq("bucket-2", ro)},
+			},
+		},
+		"EntryRemovedAlreadyRevoked": {
+			reason:  "Should not revoke owned permissions the key no longer has",
+			current: map[string]v1alpha1.KeyAccessPermissions{"bucket-2": {Write: true}},
+			owned:   map[string]v1alpha1.KeyAccessPermissions{"bucket-2": ro},
+			o:       want{},
+		},
+		"EntryChangedWhileUnresolved": {
+			reason:  "Should not revoke anything while an entry that might list the permissions cannot be resolved",
+			current: map[string]v1alpha1.KeyAccessPermissions{"bucket-1": rw, "bucket-2": ro},
+			owned:   map[string]v1alpha1.KeyAccessPermissions{"bucket-1": rw, "bucket-2": ro},
+			want:    []resolvedBucket{{bucketID: "bucket-1", permissions: ro}, {err: errors.New("boom"), permissions: ro}},
+			o:       want{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			allow, deny := bucketChanges("GK123456", tc.current, tc.owned, tc.want)
+			if diff := cmp.Diff(tc.o.allow, allow); diff != "" {
+				t.Errorf("\n%s\nbucketChanges(...): -want allow, +got allow:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.o.deny, deny); diff != "" {
+				t.Errorf("\n%s\nbucketChanges(...): -want deny, +got deny:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMarkOwned(t *testing.T) {
+	ro := v1alpha1.KeyAccessPermissions{Read: true}
+	rw := v1alpha1.KeyAccessPermissions{Read: true, Write: true}
+
+	cases := map[string]struct {
+		reason string
+		owned  map[string]v1alpha1.KeyAccessPermissions
+		want   []resolvedBucket
+		o      []*v1alpha1.KeyAccessPermissions
+	}{
+		"Listed": {
+			reason: "Should record the listed permissions of the listed buckets only",
+			want:   []resolvedBucket{{bucketID: "bucket-1", permissions: ro}},
+			o:      []*v1alpha1.KeyAccessPermissions{&ro, nil, nil},
+		},
+		"OwnedBefore": {
+			reason: "Should keep recording the permissions owned before that the key still has until they are revoked",
+			owned:  map[string]v1alpha1.KeyAccessPermissions{"bucket-1": rw, "bucket-2": grant.All},
+			want:   []resolvedBucket{{bucketID: "bucket-1", permissions: ro}, {err: errors.New("boom")}},
+			o:      []*v1alpha1.KeyAccessPermissions{&rw, &rw, nil},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			obs := []v1alpha1.KeyBucketObservation{
+				{ID: "bucket-1", Permissions: grant.All},
+				{ID: "bucket-2", Permissions: rw},
+				{ID: "bucket-9", Permissions: grant.All},
+			}
+			markOwned(obs, tc.owned, tc.want)
+			got := make([]*v1alpha1.KeyAccessPermissions, len(obs))
+			for i, b := range obs {
+				got[i] = b.BucketAccessPermissions
+			}
+			if diff := cmp.Diff(tc.o, got); diff != "" {
+				t.Errorf("\n%s\nmarkOwned(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveFields(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	expiration := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	createdTime := metav1.NewTime(created)
+	expirationTime := metav1.NewTime(expiration)
+
+	aliased := bucketPerms("bucket-1", true, false, false)
+	aliased.GlobalAliases = []string{"assets"}
+	aliased.LocalAliases = []string{"my-assets"}
+
+	cases := map[string]struct {
+		reason string
+		k      *garage.Key
+		want   v1alpha1.KeyObservation
+	}{
+		"Minimal": {
+			reason: "Should leave the times and buckets of a key that has none unset",
+			k:      &garage.Key{AccessKeyID: "GK123456", Name: "test-key"},
+			want:   v1alpha1.KeyObservation{AccessKeyID: "GK123456", Name: "test-key"},
+		},
+		"Full": {
+			reason: "Should observe the times, permissions and buckets of a key",
+			k: &garage.Key{
+				AccessKeyID: "GK123456",
+				Name:        "test-key",
+				Created:     &created,
+				Expiration:  &expiration,
+				Permissions: garage.KeyPermissions{CreateBucket: true},
+				Buckets:     []garage.KeyBucketPerms{aliased},
+			},
+			want: v1alpha1.KeyObservation{
+				AccessKeyID:  "GK123456",
+				Name:         "test-key",
+				CreationTime: &createdTime,
+				CreateBucket: true,
+				Expiration:   &expirationTime,
+				Buckets: []v1alpha1.KeyBucketObservation{{
+					ID:            "bucket-1",
+					GlobalAliases: []string{"assets"},
+					LocalAliases:  []string{"my-assets"},
+					Permissions:   v1alpha1.KeyAccessPermissions{Read: true},
+				}},
+			},
+		},
+		"Expired": {
+			reason: "Should report that a key has expired",
+			k:      &garage.Key{AccessKeyID: "GK123456", Name: "test-key", Expiration: &expiration, Expired: true},
+			want:   v1alpha1.KeyObservation{AccessKeyID: "GK123456", Name: "test-key", Expiration: &expirationTime, Expired: true},
+		},
+		"BucketPermissions": {
+			reason: "Should observe the permissions of a key on each bucket, in order",
+			k: &garage.Key{
+				AccessKeyID: "GK123456",
+				Buckets: []garage.KeyBucketPerms{
+					bucketPerms("bucket-1", true, true, true),
+					bucketPerms("bucket-2", false, true, false),
+					bucketPerms("bucket-3", false, false, false),
+				},
+			},
+			want: v1alpha1.KeyObservation{
+				AccessKeyID: "GK123456",
+				Buckets: []v1alpha1.KeyBucketObservation{
+					{ID: "bucket-1", Permissions: v1alpha1.KeyAccessPermissions{Read: true, Write: true, Owner: true}},
+					{ID: "bucket-2", Permissions: v1alpha1.KeyAccessPermissions{Write: true}},
+					{ID: "bucket-3"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, observe(tc.k)); diff != "" {
+				t.Errorf("\n%s\nobserve(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// bucketPerms returns the supplied permissions of a key on a bucket.
+func bucketPerms(id string, read, write, owner bool) garage.KeyBucketPerms {
+	b := garage.KeyBucketPerms{ID: id}
+	b.Permissions.Read = read
+	b.Permissions.Write = write
+	b.Permissions.Owner = owner
+	return b
+}
+
+func TestLateInitialize(t *testing.T) {
+	expiration := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	expirationTime := metav1.NewTime(expiration)
+	later := metav1.NewTime(expiration.Add(time.Hour))
+
+	type want struct {
+		p  v1alpha1.KeyParameters
+		li bool
+	}
+
+	buckets := []garage.KeyBucketPerms{
+		bucketPerms("bucket-1", true, false, false),
+		bucketPerms("bucket-2", false, false, false),
+		bucketPerms("bucket-3", true, true, true),
+	}
+
+	cases := map[string]struct {
+		reason    string
+		p         v1alpha1.KeyParameters
+		k         *garage.Key
+		adopted   bool
+		grantedBy []string
+		want      want
+	}{
+		"FillUnset": {
+			reason: "Should fill the permissions and expiration from the observed key",
+			p:      v1alpha1.KeyParameters{Name: "test-key"},
+			k:      &garage.Key{Name: "test-key", Expiration: &expiration, Permissions: garage.KeyPermissions{CreateBucket: true}, Buckets: buckets},
+			want: want{
+				p: v1alpha1.KeyParameters{
+					Name:        "test-key",
+					Permissions: &v1alpha1.KeyPermissions{CreateBucket: true},
+					Expiration:  &expirationTime,
+				},
+				li: true,
+			},
+		},
+		"FillBucketAccessOfAdopted": {
+			reason:  "Should fill bucket access from the buckets an adopted key has permissions on",
+			p:       v1alpha1.KeyParameters{Name: "test-key", Permissions: &v1alpha1.KeyPermissions{}},
+			k:       &garage.Key{Name: "test-key", Buckets: buckets},
+			adopted: true,
+			want: want{
+				p: v1alpha1.KeyParameters{
+					Name:        "test-key",
+					Permissions: &v1alpha1.KeyPermissions{},
+					BucketAccess: []v1alpha1.KeyBucketAccess{
+						{BucketID: ptr.To("bucket-1"), Permissions: v1alpha1.KeyAccessPermissions{Read: true}},
+						{BucketID: ptr.To("bucket-3"), Permissions: v1alpha1.KeyAccessPermissions{Read: true, Write: true, Owner: true}},
+					},
+				},
+				li: true,
+			},
+		},
+		"SkipBucketsGrantedByOthers": {
+			reason:    "Should not fill bucket access of an adopted key from the buckets other resources grant it access to",
+			p:         v1alpha1.KeyParameters{Name: "test-key", Permissions: &v1alpha1.KeyPermissions{}},
+			k:         &garage.Key{Name: "test-key", Buckets: buckets},
+			adopted:   true,
+			grantedBy: []string{"bucket-3"},
+			want: want{
+				p: v1alpha1.KeyParameters{
+					Name:        "test-key",
+					Permissions: &v1alpha1.KeyPermissions{},
+					BucketAccess: []v1alpha1.KeyBucketAccess{
+						{BucketID: ptr.To("bucket-1"), Permissions: v1alpha1.KeyAccessPermissions{Read: true}},
+					},
+				},
+				li: true,
+			},
+		},
+		"KeepBucketAccessOfAdopted": {
+			reason:  "Should not fill bucket access of an adopted key that lists buckets",
+			p:       v1alpha1.KeyParameters{Name: "test-key", Permissions: &v1alpha1.KeyPermissions{}, BucketAccess: []v1alpha1.KeyBucketAccess{{BucketAlias: ptr.To("assets")}}},
+			k:       &garage.Key{Name: "test-key", Buckets: buckets},
+			adopted: true,
+			want: want{
+				p: v1alpha1.KeyParameters{Name: "test-key", Permissions: &v1alpha1.KeyPermissions{}, BucketAccess: []v1alpha1.KeyBucketAccess{{BucketAlias: ptr.To("assets")}}},
+			},
+		},
+		"KeepSet": {
+			reason: "Should not overwrite parameters that are already set",
+			p: v1alpha1.KeyParameters{
+				Name:        "test-key",
+				Permissions: &v1alpha1.KeyPermissions{},
+				Expiration:  &later,
+			},
+			k: &garage.Key{Name: "test-key", Expiration: &expiration, Permissions: garage.KeyPermissions{CreateBucket: true}},
+			want: want{
+				p: v1alpha1.KeyParameters{
+					Name:        "test-key",
+					Permissions: &v1alpha1.KeyPermissions{},
+					Expiration:  &later,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			li := lateInitialize(&tc.p, tc.k, tc.adopted, tc.grantedBy)
+			if diff := cmp.Diff(tc.want.li, li); diff != "" {
+				t.Errorf("\n%s\nlateInitialize(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.p, tc.p); diff != "" {
+				t.Errorf("\n%s\nlateInitialize(...): -want parameters, +got parameters:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestKeyUpdate(t *testing.T) {
+	expiration := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	later := expiration.Add(time.Hour)
+
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.KeyParameters
+		k      *garage.Key
+		want   *garage.UpdateKeyRequest
+	}{
+		"Unset": {
+			reason: "Should leave the key alone if neither permissions nor expiration are set",
+			k:      &garage.Key{AccessKeyID: "GK123", Expiration: &expiration, Permissions: garage.KeyPermissions{CreateBucket: true}},
+		},
+		"UpToDate": {
+			reason: "Should not update a key whose permissions and expiration match the parameters",
+			p: v1alpha1.KeyParameters{
+				Permissions: &v1alpha1.KeyPermissions{CreateBucket: true},
+				Expiration:  &metav1.Time{Time: expiration},
+			},
+			k: &garage.Key{AccessKeyID: "GK123", Expiration: ptr.To(expiration.Add(time.Millisecond)), Permissions: garage.KeyPermissions{CreateBucket: true}},
+		},
+		"AllowCreateBucket": {
+			reason: "Should allow the key to create buckets",
+			p:      v1alpha1.KeyParameters{Permissions: &v1alpha1.KeyPermissions{CreateBucket: true}},
+			k:      &garage.Key{AccessKeyID: "GK123"},
+			want:   &garage.UpdateKeyRequest{AccessKeyID: "GK123", Allow: &garage.KeyPermissions{CreateBucket: true}},
+		},
+		"DenyCreateBucket": {
+			reason: "Should deny the key to create buckets",
+			p:      v1alpha1.KeyParameters{Permissions: &v1alpha1.KeyPermissions{}},
+			k:      &garage.Key{AccessKeyID: "GK123", Permissions: garage.KeyPermissions{CreateBucket: true}},
+			want:   &garage.UpdateKeyRequest{AccessKeyID: "GK123", Deny: &garage.KeyPermissions{CreateBucket: true}},
+		},
+		"SetExpiration": {
+			reason: "Should set the expiration of a key that never expires",
+			p:      v1alpha1.KeyParameters{Expiration: &metav1.Time{Time: expiration}},
+			k:      &garage.Key{AccessKeyID: "GK123"},
+			want:   &garage.UpdateKeyRequest{AccessKeyID: "GK123", Expiration: &expiration},
+		},
+		"ChangeExpiration": {
+			reason: "Should change the expiration of a key",
+			p:      v1alpha1.KeyParameters{Expiration: &metav1.Time{Time: later}},
+			k:      &garage.Key{AccessKeyID: "GK123", Expiration: &expiration},
+			want:   &garage.UpdateKeyRequest{AccessKeyID: "GK123", Expiration: &later},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := keyUpdate(tc.p, tc.k)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nkeyUpdate(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveImport(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		o           managed.ExternalObservation
+		accessKeyID string
+		err         error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		list    error
+		want    want
+	}{
+		"Imported": {
+			reason: "Should observe the key whose access key ID is the external name",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("id") != "GK123" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(garage.Key{AccessKeyID: "GK123", Name: "legacy"})
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: true,
+				},
+				accessKeyID: "GK123",
+			},
+		},
+		"ListGrantsFailed": {
+			reason: "Should return an error if the buckets other resources grant the imported key access to cannot be determined",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_ = json.NewEncoder(w).Encode(garage.Key{AccessKeyID: "GK123", Name: "legacy"})
+			},
+			list: errBoom,
+			want: want{
+				err:         errors.Wrap(errors.Wrap(errBoom, "cannot list KeyAccess resources"), errGrantedBy),
+				accessKeyID: "GK123",
+			},
+		},
+		"NotFound": {
+			reason: "Should report that the key does not exist if Garage does not know the external name",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			want: want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"LookupFailed": {
+			reason: "Should return an error rather than report that the key does not exist if the lookup failed",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			want: want{err: errors.Wrap(&garage.APIError{StatusCode: http.StatusInternalServerError}, errGetKey)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(tc.handler)
+			defer srv.Close()
+
+			cr := &v1alpha1.Key{ObjectMeta: metav1.ObjectMeta{Name: "legacy"}}
+			meta.SetExternalName(cr, "GK123")
+
+			e := &external{
+				client: garage.NewClient(srv.URL, "token", garage.WithRetryPolicy(garage.RetryPolicy{MaxAttempts: 1})),
+				kube:   &test.MockClient{MockList: test.NewMockListFn(tc.list)},
+			}
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.accessKeyID, cr.Status.AtProvider.AccessKeyID); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want access key ID, +got access key ID:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveLookup(t *testing.T) {
+	type want struct {
+		o           managed.ExternalObservation
+		accessKeyID string
+		err         error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		want    want
+	}{
+		"Gone": {
+			reason: "Should report that the key does not exist if Garage knows neither its access key ID nor its name",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Has("search") {
+					_ = json.NewEncoder(w).Encode([]garage.KeyInfo{})
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+			},
+			want: want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"IDLookupFailed": {
+			reason: "Should return an error rather than report that the key does not exist if the lookup by access key ID failed",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			want: want{
+				err:         errors.Wrap(&garage.APIError{StatusCode: http.StatusServiceUnavailable}, errGetKey),
+				accessKeyID: "GK123",
+			},
+		},
+		"NameLookupFailed": {
+			reason: "Should return an error rather than report that the key does not exist if the lookup by name failed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Has("search") {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+			},
+			want: want{err: errors.Wrap(&garage.APIError{StatusCode: http.StatusServiceUnavailable}, errGetKey)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(tc.handler)
+			defer srv.Close()
+
+			cr := &v1alpha1.Key{
+				ObjectMeta: metav1.ObjectMeta{Name: "app"},
+				Spec:       v1alpha1.KeySpec{ForProvider: v1alpha1.KeyParameters{Name: "app"}},
+				Status:     v1alpha1.KeyStatus{AtProvider: v1alpha1.KeyObservation{AccessKeyID: "GK123"}},
+			}
+
+			e := &external{client: garage.NewClient(srv.URL, "token", garage.WithRetryPolicy(garage.RetryPolicy{MaxAttempts: 1}))}
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.accessKeyID, cr.Status.AtProvider.AccessKeyID); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want access key ID, +got access key ID:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreateFormat(t *testing.T) {
+	bad := &v1alpha1.ConnectionSecretFormat{Templates: map[string]string{"config": "{{ .AccessKeyID "}}
+	_, errRender := connectionDetails(bad, credentials{})
+
+	format := &v1alpha1.ConnectionSecretFormat{Templates: map[string]string{"config": "id={{ .AccessKeyID }}"}}
+
+	type want struct {
+		o       managed.ExternalCreation
+		created bool
+		hash    string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		format *v1alpha1.ConnectionSecretFormat
+		want   want
+	}{
+		"Rendered": {
+			reason: "Should return the credentials rendered with the format, and record the format in the persisted status",
+			format: format,
+			want: want{
+				o: managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{
+					"accessKeyId":     []byte("GK123"),
+					"secretAccessKey": []byte("secret"),
+					"config":          []byte("id=GK123"),
+				}},
+				created: true,
+				hash:    formatHash(format, "", ""),
+			},
+		},
+		"NoFormat": {
+			reason: "Should return the credentials without writing the status if the key has no format",
+			want: want{
+				o: managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{
+					"accessKeyId":     []byte("GK123"),
+					"secretAccessKey": []byte("secret"),
+				}},
+				created: true,
+			},
+		},
+		"RenderFailed": {
+			reason: "Should return the error, without creating a key whose secret could not be published, if the format cannot be rendered",
+			format: bad,
+			want:   want{err: errRender},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			created := false
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				created = true
+				_ = json.NewEncoder(w).Encode(garage.Key{AccessKeyID: "GK123", SecretAccessKey: "secret"})
+			}))
+			defer srv.Close()
+
+			// The status is persisted before the external name, which a
+			// status update does not persist.
+			var persisted string
+			kube := &test.MockClient{MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+				persisted = obj.(*v1alpha1.Key).Status.AtProvider.ConnectionSecretFormatHash
+				obj.SetAnnotations(nil)
+				return nil
+			}}
+
+			cr := &v1alpha1.Key{Spec: v1alpha1.KeySpec{ForProvider: v1alpha1.KeyParameters{Name: "my-key"}, ConnectionSecretFormat: tc.format}}
+			e := &external{client: garage.NewClient(srv.URL, "token", garage.WithRetryPolicy(garage.RetryPolicy{MaxAttempts: 1})), kube: kube}
+			got, err := e.Create(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.created, created); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want created, +got created:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.hash, persisted); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want persisted hash, +got persisted hash:\n%s\n", tc.reason, diff)
+			}
+			if tc.want.created {
+				if diff := cmp.Diff("GK123", meta.GetExternalName(cr)); diff != "" {
+					t.Errorf("\n%s\ne.Create(...): -want external name, +got external name:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateFormat(t *testing.T) {
+	format := &v1alpha1.ConnectionSecretFormat{Templates: map[string]string{"config": "id={{ .AccessKeyID }}"}}
+	hash := formatHash(format, "", "")
+
+	type want struct {
+		o          managed.ExternalUpdate
+		hash       string
+		showSecret bool
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		format *v1alpha1.ConnectionSecretFormat
+		hash   string
+		want   want
+	}{
+		"Unchanged": {
+			reason: "Should not fetch the secret key again if the format was published",
+			format: format,
+			hash:   hash,
+			want:   want{hash: hash},
+		},
+		"Changed": {
+			reason: "Should publish the credentials rendered with a changed format",
+			format: format,
+			hash:   "stale",
+			want: want{
+				o: managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{
+					"accessKeyId":     []byte("GK123"),
+					"secretAccessKey": []byte("secret"),
+					"config":          []byte("id=GK123"),
+				}},
+				hash:       hash,
+				showSecret: true,
+			},
+		},
+		"Removed": {
+			reason: "Should not fetch the secret key once the format was removed",
+			hash:   hash,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			showSecret := false
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				k := garage.Key{AccessKeyID: "GK123"}
+				if r.URL.Query().Get("showSecretKey") == "true" {
+					showSecret = true
+					k.SecretAccessKey = "secret"
+				}
+				_ = json.NewEncoder(w).Encode(k)
+			}))
+			defer srv.Close()
+
+			cr := &v1alpha1.Key{
+				Spec:   v1alpha1.KeySpec{ForProvider: v1alpha1.KeyParameters{Name: "my-key"}, ConnectionSecretFormat: tc.format},
+				Status: v1alpha1.KeyStatus{AtProvider: v1alpha1.KeyObservation{AccessKeyID: "GK123", ConnectionSecretFormatHash: tc.hash}},
+			}
+			e := &external{client: garage.NewClient(srv.URL, "token", garage.WithRetryPolicy(garage.RetryPolicy{MaxAttempts: 1}))}
+			got, err := e.Update(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.hash, cr.Status.AtProvider.ConnectionSecretFormatHash); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want hash, +got hash:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.showSecret, showSecret); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want secret key fetched, +got secret key fetched:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package keyaccess
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/internal/dependency"
+)
+
+const (
+	// bucketRefIndex indexes KeyAccess resources by the Bucket, and
+	// ClusterKeyAccess resources by the ClusterBucket, they reference.
+	bucketRefIndex = "spec.forProvider.bucketIdRef.name"
+	// keyRefIndex indexes KeyAccess resources by the Key, and ClusterKeyAccess
+	// resources by the ClusterKey, they reference.
+	keyRefIndex = "spec.forProvider.accessKeyIdRef.name"
+	// clusterBucketRefIndex indexes KeyAccess resources by the ClusterBucket
+	// they reference.
+	clusterBucketRefIndex = "spec.forProvider.clusterBucketIdRef.name"
+	// clusterKeyRefIndex indexes KeyAccess resources by the ClusterKey they
+	// reference.
+	clusterKeyRefIndex = "spec.forProvider.clusterAccessKeyIdRef.name"
+
+	// selectorIndexValue is indexed for KeyAccess resources that select their
+	// Bucket or Key by labels. It is not a valid object name, so it never
+	// collides with a referenced name.
+	selectorIndexValue = "<selector>"
+)
+
+// indexBucketRef returns the index values of a KeyAccess or ClusterKeyAccess
+// for bucketRefIndex. Both references of a ClusterKeyAccess are to
+// ClusterBuckets.
+func indexBucketRef(o client.Object) []string {
+	switch ka := o.(type) {
+	case *v1alpha1.KeyAccess:
+		return indexValues(ka.Spec.ForProvider.BucketIDSelector, ka.Spec.ForProvider.BucketIDRef)
+	case *v1alpha1.ClusterKeyAccess:
+		return indexValues(ka.Spec.ForProvider.BucketIDSelector, ka.Spec.ForProvider.BucketIDRef, ka.Spec.ForProvider.ClusterBucketIDRef)
+	}
+	return nil
+}
+
+// indexKeyRef returns the index values of a KeyAccess or ClusterKeyAccess for
+// keyRefIndex. Both references of a ClusterKeyAccess are to ClusterKeys.
+func indexKeyRef(o client.Object) []string {
+	switch ka := o.(type) {
+	case *v1alpha1.KeyAccess:
+		return indexValues(ka.Spec.ForProvider.AccessKeyIDSelector, ka.Spec.ForProvider.AccessKeyIDRef)
+	case *v1alpha1.ClusterKeyAccess:
+		return indexValues(ka.Spec.ForProvider.AccessKeyIDSelector, ka.Spec.ForProvider.AccessKeyIDRef, ka.Spec.ForProvider.ClusterAccessKeyIDRef)
+	}
+	return nil
+}
+
+// indexClusterBucketRef returns the index values of a KeyAccess for
+// clusterBucketRefIndex.
+func indexClusterBucketRef(o client.Object) []string {
+	ka, ok := o.(*v1alpha1.KeyAccess)
+	if !ok {
+		return nil
+	}
+	return indexValues(nil, ka.Spec.ForProvider.ClusterBucketIDRef)
+}
+
+// indexClusterKeyRef returns the index values of a KeyAccess for
+// clusterKeyRefIndex.
+func indexClusterKeyRef(o client.Object) []string {
+	ka, ok := o.(*v1alpha1.KeyAccess)
+	if !ok {
+		return nil
+	}
+	return indexValues(nil, ka.Spec.ForProvider.ClusterAccessKeyIDRef)
+}
+
+func indexValues(sel *xpv1.Selector, refs ...*xpv1.Reference) []string {
+	var v []string
+	for _, ref := range refs {
+		if ref != nil && ref.Name != "" {
+			v = append(v, ref.Name)
+		}
+	}
+	if sel != nil {
+		v = append(v, selectorIndexValue)
+	}
+	return v
+}
+
+// A lister lists KeyAccess resources, or ClusterKeyAccess resources converted
+// to KeyAccess resources.
+type lister func(ctx context.Context, kube client.Client, opts ...client.ListOption) ([]*v1alpha1.KeyAccess, error)
+
+func listKeyAccess(ctx context.Context, kube client.Client, opts ...client.ListOption) ([]*v1alpha1.KeyAccess, error) {
+	l := &v1alpha1.KeyAccessList{}
+	if err := kube.List(ctx, l, opts...); err != nil {
+		return nil, err
+	}
+	items := make([]*v1alpha1.KeyAccess, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items, nil
+}
+
+func listClusterKeyAccess(ctx context.Context, kube client.Client, opts ...client.ListOption) ([]*v1alpha1.KeyAccess, error) {
+	l := &v1alpha1.ClusterKeyAccessList{}
+	if err := kube.List(ctx, l, opts...); err != nil {
+		return nil, err
+	}
+	items := make([]*v1alpha1.KeyAccess, len(l.Items))
+	for i := range l.Items {
+		items[i] = (*v1alpha1.KeyAccess)(&l.Items[i])
+	}
+	return items, nil
+}
+
+// dependents returns a map function that enqueues every KeyAccess listed by
+// list in the namespace of the watched object, or in any namespace if it is
+// cluster-scoped, that references it by name or selects it by labels.
+// selector returns the selector of a KeyAccess for the watched kind, and is
+// nil if the watched kind cannot be selected.
+func dependents(kube client.Client, log logging.Logger, list lister, index string, selector func(*v1alpha1.KeyAccess) *xpv1.Selector) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		byName, err := list(ctx, kube, client.InNamespace(o.GetNamespace()), client.MatchingFields{index: o.GetName()})
+		if err != nil {
+			log.Debug("Cannot list KeyAccess resources referencing object", "error", err, "name", o.GetName(), "namespace", o.GetNamespace())
+			return nil
+		}
+		var bySelector []*v1alpha1.KeyAccess
+		if selector != nil {
+			bySelector, err = list(ctx, kube, client.InNamespace(o.GetNamespace()), client.MatchingFields{index: selectorIndexValue})
+			if err != nil {
+				log.Debug("Cannot list KeyAccess resources selecting object", "error", err, "name", o.GetName(), "namespace", o.GetNamespace())
+				return nil
+			}
+		}
+
+		seen := map[types.NamespacedName]bool{}
+		reqs := make([]reconcile.Request, 0, len(byName))
+		add := func(ka *v1alpha1.KeyAccess) {
+			nn := types.NamespacedName{Namespace: ka.GetNamespace(), Name: ka.GetName()}
+			if seen[nn] {
+				return
+			}
+			seen[nn] = true
+			reqs = append(reqs, reconcile.Request{NamespacedName: nn})
+		}
+		for _, ka := range byName {
+			add(ka)
+		}
+		for _, ka := range bySelector {
+			if dependency.Selects(selector(ka), ka, o) {
+				add(ka)
+			}
+		}
+		return reqs
+	}
+}
+
+func bucketSelector(ka *v1alpha1.KeyAccess) *xpv1.Selector {
+	return ka.Spec.ForProvider.BucketIDSelector
+}
+
+func keySelector(ka *v1alpha1.KeyAccess) *xpv1.Selector {
+	return ka.Spec.ForProvider.AccessKeyIDSelector
+}
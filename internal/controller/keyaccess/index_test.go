@@ -0,0 +1,268 @@
+package keyaccess
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+)
+
+func TestIndexBucketRef(t *testing.T) {
+	bucketID := "bucket-123"
+
+	cases := map[string]struct {
+		reason string
+		o      client.Object
+		want   []string
+	}{
+		"NotKeyAccess": {
+			reason: "Should not index objects that are not KeyAccess resources",
+			o:      &v1alpha1.Bucket{},
+			want:   nil,
+		},
+		"NoReference": {
+			reason: "Should not index a KeyAccess with a literal bucket ID",
+			o: &v1alpha1.KeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				BucketID: &bucketID,
+			}}},
+			want: nil,
+		},
+		"Reference": {
+			reason: "Should index a KeyAccess by the name of the referenced Bucket",
+			o: &v1alpha1.KeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				BucketIDRef: &xpv1.Reference{Name: "my-bucket"},
+			}}},
+			want: []string{"my-bucket"},
+		},
+		"Selector": {
+			reason: "Should index a KeyAccess that selects its Bucket with the selector value",
+			o: &v1alpha1.KeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				BucketIDSelector: &xpv1.Selector{MatchLabels: map[string]string{"app": "web"}},
+			}}},
+			want: []string{selectorIndexValue},
+		},
+		"ClusterReference": {
+			reason: "Should not index a KeyAccess by the name of a referenced ClusterBucket",
+			o: &v1alpha1.KeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				ClusterBucketIDRef: &xpv1.Reference{Name: "platform"},
+			}}},
+			want: nil,
+		},
+		"ClusterKeyAccess": {
+			reason: "Should index a ClusterKeyAccess by the name of the ClusterBucket either of its references is to",
+			o: &v1alpha1.ClusterKeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				ClusterBucketIDRef: &xpv1.Reference{Name: "platform"},
+			}}},
+			want: []string{"platform"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := indexBucketRef(tc.o)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nindexBucketRef(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIndexKeyRef(t *testing.T) {
+	accessKeyID := "GK123"
+
+	cases := map[string]struct {
+		reason string
+		o      client.Object
+		want   []string
+	}{
+		"NotKeyAccess": {
+			reason: "Should not index objects that are not KeyAccess resources",
+			o:      &v1alpha1.Key{},
+			want:   nil,
+		},
+		"NoReference": {
+			reason: "Should not index a KeyAccess with a literal access key ID",
+			o: &v1alpha1.KeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				AccessKeyID: &accessKeyID,
+			}}},
+			want: nil,
+		},
+		"ReferenceAndSelector": {
+			reason: "Should index a KeyAccess by the name of the referenced Key and the selector value",
+			o: &v1alpha1.KeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				AccessKeyIDRef:      &xpv1.Reference{Name: "my-key"},
+				AccessKeyIDSelector: &xpv1.Selector{MatchLabels: map[string]string{"app": "web"}},
+			}}},
+			want: []string{"my-key", selectorIndexValue},
+		},
+		"ClusterReference": {
+			reason: "Should not index a KeyAccess by the name of a referenced ClusterKey",
+			o: &v1alpha1.KeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				ClusterAccessKeyIDRef: &xpv1.Reference{Name: "platform"},
+			}}},
+			want: nil,
+		},
+		"ClusterKeyAccess": {
+			reason: "Should index a ClusterKeyAccess by the names of the ClusterKeys both of its references are to",
+			o: &v1alpha1.ClusterKeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				AccessKeyIDRef:        &xpv1.Reference{Name: "platform"},
+				ClusterAccessKeyIDRef: &xpv1.Reference{Name: "shared"},
+			}}},
+			want: []string{"platform", "shared"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := indexKeyRef(tc.o)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nindexKeyRef(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIndexClusterRef(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		o      client.Object
+		index  func(client.Object) []string
+		want   []string
+	}{
+		"ClusterBucket": {
+			reason: "Should index a KeyAccess by the name of the referenced ClusterBucket",
+			o: &v1alpha1.KeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				BucketIDRef:        &xpv1.Reference{Name: "my-bucket"},
+				ClusterBucketIDRef: &xpv1.Reference{Name: "platform"},
+			}}},
+			index: indexClusterBucketRef,
+			want:  []string{"platform"},
+		},
+		"ClusterBucketSelector": {
+			reason: "Should not index a KeyAccess that selects a namespaced Bucket",
+			o: &v1alpha1.KeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				BucketIDSelector: &xpv1.Selector{MatchLabels: map[string]string{"app": "web"}},
+			}}},
+			index: indexClusterBucketRef,
+			want:  nil,
+		},
+		"ClusterBucketClusterKeyAccess": {
+			reason: "Should not index a ClusterKeyAccess, whose references are indexed by bucketRefIndex",
+			o: &v1alpha1.ClusterKeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				ClusterBucketIDRef: &xpv1.Reference{Name: "platform"},
+			}}},
+			index: indexClusterBucketRef,
+			want:  nil,
+		},
+		"ClusterKey": {
+			reason: "Should index a KeyAccess by the name of the referenced ClusterKey",
+			o: &v1alpha1.KeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				AccessKeyIDRef:        &xpv1.Reference{Name: "my-key"},
+				ClusterAccessKeyIDRef: &xpv1.Reference{Name: "platform"},
+			}}},
+			index: indexClusterKeyRef,
+			want:  []string{"platform"},
+		},
+		"ClusterKeyClusterKeyAccess": {
+			reason: "Should not index a ClusterKeyAccess, whose references are indexed by keyRefIndex",
+			o: &v1alpha1.ClusterKeyAccess{Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+				ClusterAccessKeyIDRef: &xpv1.Reference{Name: "platform"},
+			}}},
+			index: indexClusterKeyRef,
+			want:  nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.index(tc.o)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nindex(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDependents(t *testing.T) {
+	byName := v1alpha1.KeyAccess{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "by-name"}}
+	matching := v1alpha1.KeyAccess{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "matching"},
+		Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+			BucketIDSelector: &xpv1.Selector{MatchLabels: map[string]string{"app": "web"}},
+		}},
+	}
+	other := v1alpha1.KeyAccess{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"},
+		Spec: v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{
+			BucketIDSelector: &xpv1.Selector{MatchLabels: map[string]string{"app": "db"}},
+		}},
+	}
+
+	list := func(items ...v1alpha1.KeyAccess) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+			lo := &client.ListOptions{}
+			lo.ApplyOptions(opts)
+			l := obj.(*v1alpha1.KeyAccessList)
+			if lo.FieldSelector.String() == bucketRefIndex+"="+selectorIndexValue {
+				for _, ka := range items {
+					if ka.Spec.ForProvider.BucketIDSelector != nil {
+						l.Items = append(l.Items, ka)
+					}
+				}
+				return nil
+			}
+			for _, ka := range items {
+				if ka.Spec.ForProvider.BucketIDSelector == nil {
+					l.Items = append(l.Items, ka)
+				}
+			}
+			return nil
+		}
+	}
+
+	bucket := &v1alpha1.Bucket{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      "my-bucket",
+		Labels:    map[string]string{"app": "web"},
+	}}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Client
+		want   []reconcile.Request
+	}{
+		"ListError": {
+			reason: "Should enqueue nothing if KeyAccess resources cannot be listed",
+			kube:   &test.MockClient{MockList: test.NewMockListFn(errors.New("boom"))},
+			want:   nil,
+		},
+		"ReferencesAndSelectors": {
+			reason: "Should enqueue KeyAccess resources that reference or select the Bucket",
+			kube:   &test.MockClient{MockList: list(byName, matching, other)},
+			want: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "default", Name: "by-name"}},
+				{NamespacedName: types.NamespacedName{Namespace: "default", Name: "matching"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := dependents(tc.kube, logging.NewNopLogger(), listKeyAccess, bucketRefIndex, bucketSelector)(context.Background(), bucket)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ndependents(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -3,97 +3,174 @@ package keyaccess
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/pkg/errors"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	v1 "github.com/kikokikok/provider-garage/apis/v1"
 	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/internal/clients"
+	"github.com/kikokikok/provider-garage/internal/dependency"
+	"github.com/kikokikok/provider-garage/internal/features"
+	"github.com/kikokikok/provider-garage/internal/grant"
+	"github.com/kikokikok/provider-garage/internal/tracing"
 	"github.com/kikokikok/provider-garage/pkg/garage"
 )
 
 const (
-	errNotKeyAccess  = "managed resource is not a KeyAccess custom resource"
-	errTrackPCUsage  = "cannot track ProviderConfig usage"
-	errGetPC         = "cannot get ProviderConfig"
-	errGetCreds      = "cannot get credentials"
+	errNotKeyAccess  = "managed resource is not a KeyAccess or ClusterKeyAccess custom resource"
 	errGrantAccess   = "cannot grant key access"
 	errRevokeAccess  = "cannot revoke key access"
 	errResolveBucket = "cannot resolve bucket reference"
 	errResolveKey    = "cannot resolve key reference"
+	errIndexRef      = "cannot index KeyAccess references"
 )
 
-// Setup adds a controller that reconciles KeyAccess managed resources.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
-	name := managed.ControllerName(v1alpha1.KeyAccessGroupKind)
-
-	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+// Setup adds a controller that reconciles KeyAccess managed resources, which
+// connect to Garage with the supplied Connector.
+func Setup(mgr ctrl.Manager, o controller.Options, conn *clients.Connector) error {
+	kube := mgr.GetClient()
+	return setup(mgr, o, conn, kind{
+		groupKind: v1alpha1.KeyAccessGroupKind,
+		gvk:       v1alpha1.KeyAccessGroupVersionKind,
+		object:    &v1alpha1.KeyAccess{},
+		list:      &v1alpha1.KeyAccessList{},
+		usage:     clients.NewUsageTracker(kube),
+		indexes: map[string]client.IndexerFunc{
+			bucketRefIndex:        indexBucketRef,
+			keyRefIndex:           indexKeyRef,
+			clusterBucketRefIndex: indexClusterBucketRef,
+			clusterKeyRefIndex:    indexClusterKeyRef,
+		},
+		dependencies: []dependencies{
+			{object: &v1alpha1.Bucket{}, enqueue: dependents(kube, o.Logger, listKeyAccess, bucketRefIndex, bucketSelector)},
+			{object: &v1alpha1.Key{}, enqueue: dependents(kube, o.Logger, listKeyAccess, keyRefIndex, keySelector)},
+			{object: &v1alpha1.ClusterBucket{}, enqueue: dependents(kube, o.Logger, listKeyAccess, clusterBucketRefIndex, nil)},
+			{object: &v1alpha1.ClusterKey{}, enqueue: dependents(kube, o.Logger, listKeyAccess, clusterKeyRefIndex, nil)},
+		},
+	})
+}
 
-	r := managed.NewReconciler(mgr,
-		resource.ManagedKind(v1alpha1.KeyAccessGroupVersionKind),
-		managed.WithExternalConnecter(&connector{
-			kube: mgr.GetClient(),
-		}),
-		managed.WithLogger(o.Logger.WithValues("controller", name)),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
-		managed.WithConnectionPublishers(cps...))
+// SetupCluster adds a controller that reconciles ClusterKeyAccess managed
+// resources. They connect to Garage with the supplied Connector, and their
+// ProviderConfigUsages are created in the supplied namespace.
+func SetupCluster(mgr ctrl.Manager, o controller.Options, conn *clients.Connector, namespace string) error {
+	kube := mgr.GetClient()
+	return setup(mgr, o, conn, kind{
+		groupKind: v1alpha1.ClusterKeyAccessGroupKind,
+		gvk:       v1alpha1.ClusterKeyAccessGroupVersionKind,
+		object:    &v1alpha1.ClusterKeyAccess{},
+		list:      &v1alpha1.ClusterKeyAccessList{},
+		usage:     clients.NewUsageTracker(kube, clients.WithUsageNamespace(namespace)),
+		indexes: map[string]client.IndexerFunc{
+			bucketRefIndex: indexBucketRef,
+			keyRefIndex:    indexKeyRef,
+		},
+		dependencies: []dependencies{
+			{object: &v1alpha1.ClusterBucket{}, enqueue: dependents(kube, o.Logger, listClusterKeyAccess, bucketRefIndex, bucketSelector)},
+			{object: &v1alpha1.ClusterKey{}, enqueue: dependents(kube, o.Logger, listClusterKeyAccess, keyRefIndex, keySelector)},
+		},
+	})
+}
 
-	return ctrl.NewControllerManagedBy(mgr).
-		Named(name).
-		WithOptions(o.ForControllerRuntime()).
-		For(&v1alpha1.KeyAccess{}).
-		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+// A kind of key access reconciled by this controller.
+type kind struct {
+	groupKind    string
+	gvk          schema.GroupVersionKind
+	object       client.Object
+	list         resource.ManagedList
+	usage        resource.Tracker
+	indexes      map[string]client.IndexerFunc
+	dependencies []dependencies
 }
 
-type connector struct {
-	kube client.Client
+// dependencies of a key access, and how to map them to the key accesses that
+// depend on them.
+type dependencies struct {
+	object  client.Object
+	enqueue handler.MapFunc
 }
 
-func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
-	cr, ok := mg.(*v1alpha1.KeyAccess)
-	if !ok {
-		return nil, errors.New(errNotKeyAccess)
+func setup(mgr ctrl.Manager, o controller.Options, conn *clients.Connector, k kind) error {
+	name := managed.ControllerName(k.groupKind)
+
+	// Index key accesses by the buckets and keys they reference so that they
+	// can be requeued as soon as a dependency changes, rather than waiting for
+	// the next poll.
+	fi := mgr.GetFieldIndexer()
+	for field, fn := range k.indexes {
+		if err := fi.IndexField(context.Background(), k.object, field, fn); err != nil {
+			return errors.Wrap(err, errIndexRef)
+		}
 	}
 
-	pc := &v1.ProviderConfig{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
-		return nil, errors.Wrap(err, errGetPC)
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), v1alpha1.StoreConfigGroupVersionKind, connection.WithTLSConfig(o.ESSOptions.TLSConfig)))
 	}
 
-	cd := pc.Spec.Credentials
-	data, err := resource.CommonCredentialExtractor(ctx, cd.Source, c.kube, cd.CommonCredentialSelectors)
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(k.gvk.Kind, clients.NewExternalConnecter(
+			k.usage,
+			conn,
+			newExternal(mgr.GetClient()),
+		))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...),
+	}
+	if o.Features.Enabled(feature.EnableBetaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+	mo, err := clients.MetricRecorders(mgr, o, k.list)
 	if err != nil {
-		return nil, errors.Wrap(err, errGetCreds)
+		return err
 	}
+	opts = append(opts, mo...)
 
-	creds := struct {
-		Endpoint   string `json:"endpoint"`
-		AdminToken string `json:"adminToken"`
-	}{}
-	if len(data) > 0 {
-		if err := json.Unmarshal(data, &creds); err != nil {
-			return nil, errors.Wrap(err, errGetCreds)
-		}
-	}
+	r := managed.NewReconciler(mgr, resource.ManagedKind(k.gvk), opts...)
 
-	endpoint := creds.Endpoint
-	if pc.Spec.Endpoint != nil && *pc.Spec.Endpoint != "" {
-		endpoint = *pc.Spec.Endpoint
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(k.object)
+	for _, d := range k.dependencies {
+		b = b.Watches(d.object, handler.EnqueueRequestsFromMapFunc(d.enqueue))
 	}
+	return b.Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
 
-	garageClient := garage.NewClient(endpoint, creds.AdminToken)
+// asKeyAccess returns the supplied KeyAccess or ClusterKeyAccess as a
+// KeyAccess. A ClusterKeyAccess is converted in place, so changes to the
+// returned KeyAccess are made to it.
+func asKeyAccess(mg resource.Managed) (*v1alpha1.KeyAccess, bool) {
+	switch cr := mg.(type) {
+	case *v1alpha1.KeyAccess:
+		return cr, true
+	case *v1alpha1.ClusterKeyAccess:
+		return (*v1alpha1.KeyAccess)(cr), true
+	}
+	return nil, false
+}
 
-	return &external{client: garageClient, kube: c.kube}, nil
+// newExternal returns a function that builds the external client of a key access.
+func newExternal(kube client.Client) clients.ExternalFn {
+	return func(_ resource.Managed, gc *garage.Client, _ *v1.ProviderConfigSpec) (managed.ExternalClient, error) {
+		return &external{client: gc, kube: kube}, nil
+	}
 }
 
 type external struct {
@@ -102,19 +179,28 @@ type external struct {
 }
 
 func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
-	cr, ok := mg.(*v1alpha1.KeyAccess)
+	cr, ok := asKeyAccess(mg)
 	if !ok {
 		return managed.ExternalObservation{}, errors.New(errNotKeyAccess)
 	}
 
 	// Resolve bucket ID
 	bucketID, err := e.resolveBucketID(ctx, cr)
+	if err != nil && meta.WasDeleted(cr) && cr.Status.AtProvider.BucketID != "" {
+		// The bucket may be gone, or a ClusterBucket may no longer allow this
+		// namespace; fall back to the grant we last observed so that it is
+		// revoked and the KeyAccess can be deleted.
+		bucketID, err = cr.Status.AtProvider.BucketID, nil
+	}
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, errResolveBucket)
 	}
 
 	// Resolve access key ID
 	accessKeyID, err := e.resolveAccessKeyID(ctx, cr)
+	if err != nil && meta.WasDeleted(cr) && cr.Status.AtProvider.AccessKeyID != "" {
+		accessKeyID, err = cr.Status.AtProvider.AccessKeyID, nil
+	}
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, errResolveKey)
 	}
@@ -159,7 +245,7 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 }
 
 func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
-	cr, ok := mg.(*v1alpha1.KeyAccess)
+	cr, ok := asKeyAccess(mg)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotKeyAccess)
 	}
@@ -203,7 +289,7 @@ func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 }
 
 func (e *external) Delete(ctx context.Context, mg resource.Managed) (managed.ExternalDelete, error) {
-	cr, ok := mg.(*v1alpha1.KeyAccess)
+	cr, ok := asKeyAccess(mg)
 	if !ok {
 		return managed.ExternalDelete{}, errors.New(errNotKeyAccess)
 	}
@@ -214,12 +300,11 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalDelete{}, nil
 	}
 
-	req := &garage.RevokeKeyAccessRequest{
-		BucketID:    cr.Status.AtProvider.BucketID,
-		AccessKeyID: cr.Status.AtProvider.AccessKeyID,
+	_, err := e.client.RevokeKeyAccess(ctx, grant.Deny(cr.Status.AtProvider.BucketID, cr.Status.AtProvider.AccessKeyID, grant.All))
+	if garage.IsNotFound(err) {
+		// The bucket or key is already gone, and its grants with it.
+		return managed.ExternalDelete{}, nil
 	}
-
-	_, err := e.client.RevokeKeyAccess(ctx, req)
 	return managed.ExternalDelete{}, errors.Wrap(err, errRevokeAccess)
 }
 
@@ -227,52 +312,20 @@ func (e *external) Disconnect(ctx context.Context) error {
 	return nil
 }
 
-// resolveBucketID resolves the bucket ID from direct value or reference
+// resolveBucketID resolves the bucket ID from direct value, reference or selector
 func (e *external) resolveBucketID(ctx context.Context, cr *v1alpha1.KeyAccess) (string, error) {
-	if cr.Spec.ForProvider.BucketID != nil && *cr.Spec.ForProvider.BucketID != "" {
-		return *cr.Spec.ForProvider.BucketID, nil
+	p := cr.Spec.ForProvider
+	if p.ClusterBucketIDRef != nil {
+		return dependency.ResolveClusterBucketID(ctx, e.kube, cr, p.ClusterBucketIDRef)
 	}
-
-	if cr.Spec.ForProvider.BucketIDRef != nil {
-		bucket := &v1alpha1.Bucket{}
-		err := e.kube.Get(ctx, types.NamespacedName{
-			Name:      cr.Spec.ForProvider.BucketIDRef.Name,
-			Namespace: cr.Namespace,
-		}, bucket)
-		if err != nil {
-			return "", err
-		}
-		// Check if the Bucket has been reconciled and has an ID
-		if bucket.Status.AtProvider.ID == "" {
-			return "", errors.New("referenced Bucket has not been reconciled yet (ID is empty)")
-		}
-		return bucket.Status.AtProvider.ID, nil
-	}
-
-	return "", nil
+	return dependency.ResolveBucketID(ctx, e.kube, cr, p.BucketID, p.BucketIDRef, p.BucketIDSelector)
 }
 
-// resolveAccessKeyID resolves the access key ID from direct value or reference
+// resolveAccessKeyID resolves the access key ID from direct value, reference or selector
 func (e *external) resolveAccessKeyID(ctx context.Context, cr *v1alpha1.KeyAccess) (string, error) {
-	if cr.Spec.ForProvider.AccessKeyID != nil && *cr.Spec.ForProvider.AccessKeyID != "" {
-		return *cr.Spec.ForProvider.AccessKeyID, nil
+	p := cr.Spec.ForProvider
+	if p.ClusterAccessKeyIDRef != nil {
+		return dependency.ResolveClusterAccessKeyID(ctx, e.kube, p.ClusterAccessKeyIDRef)
 	}
-
-	if cr.Spec.ForProvider.AccessKeyIDRef != nil {
-		key := &v1alpha1.Key{}
-		err := e.kube.Get(ctx, types.NamespacedName{
-			Name:      cr.Spec.ForProvider.AccessKeyIDRef.Name,
-			Namespace: cr.Namespace,
-		}, key)
-		if err != nil {
-			return "", err
-		}
-		// Check if the Key has been reconciled and has an AccessKeyID
-		if key.Status.AtProvider.AccessKeyID == "" {
-			return "", errors.New("referenced Key has not been reconciled yet (AccessKeyID is empty)")
-		}
-		return key.Status.AtProvider.AccessKeyID, nil
-	}
-
-	return "", nil
+	return dependency.ResolveAccessKeyID(ctx, e.kube, cr, p.AccessKeyID, p.AccessKeyIDRef, p.AccessKeyIDSelector)
 }
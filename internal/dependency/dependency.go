@@ -0,0 +1,727 @@
+// Package dependency resolves references between Garage resources and finds
+// the resources that depend on a Bucket or Key. Cluster-scoped
+// resources are handled as their namespaced counterparts without a namespace.
+package dependency
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+)
+
+const (
+	errListKeyAccess        = "cannot list KeyAccess resources"
+	errListClusterKeyAccess = "cannot list ClusterKeyAccess resources"
+	errListPolicies         = "cannot list BucketAccessPolicy resources"
+	errListKeyResources     = "cannot list Key resources"
+	errListClusterKeys      = "cannot list ClusterKey resources"
+	errListBuckets          = "cannot list Buckets matching bucketIdSelector"
+	errListKeys             = "cannot list Keys matching accessKeyIdSelector"
+	errNoBucketMatch        = "no Bucket matches bucketIdSelector"
+	errNoKeyMatch           = "no Key matches accessKeyIdSelector"
+	errManyBucketsMatch     = "more than one Bucket matches bucketIdSelector: %s"
+	errManyKeysMatch        = "more than one Key matches accessKeyIdSelector: %s"
+	errNamespaceNotAllowed  = "ClusterBucket %q does not list namespace %q in allowedNamespaces"
+)
+
+// Selects returns true if the supplied selector of the selecting object
+// matches the candidate object.
+func Selects(sel *xpv1.Selector, from, candidate metav1.Object) bool {
+	if sel == nil {
+		return false
+	}
+	if !labels.SelectorFromSet(sel.MatchLabels).Matches(labels.Set(candidate.GetLabels())) {
+		return false
+	}
+	if sel.MatchControllerRef == nil || !*sel.MatchControllerRef {
+		return true
+	}
+	want := metav1.GetControllerOf(from)
+	got := metav1.GetControllerOf(candidate)
+	return want != nil && got != nil && want.UID == got.UID
+}
+
+// ResolveBucketID resolves a bucket ID from a direct value, a reference to a
+// Bucket in the namespace of from, or a selector, in that order. A selector
+// must match exactly one Bucket. References and selectors of a cluster-scoped
+// from are to ClusterBuckets. It returns an empty ID if none of them is set.
+func ResolveBucketID(ctx context.Context, kube client.Reader, from metav1.Object, id *string, ref *xpv1.Reference, sel *xpv1.Selector) (string, error) {
+	if id != nil && *id != "" {
+		return *id, nil
+	}
+
+	if ref != nil {
+		return referencedBucketID(ctx, kube, from.GetNamespace(), ref)
+	}
+
+	if sel != nil {
+		buckets, err := listBuckets(ctx, kube, from.GetNamespace(), sel)
+		if err != nil {
+			return "", errors.Wrap(err, errListBuckets)
+		}
+		var selected []string
+		var bucket *v1alpha1.Bucket
+		for _, b := range buckets {
+			if Selects(sel, from, b) {
+				selected = append(selected, b.GetName())
+				bucket = b
+			}
+		}
+		if len(selected) == 0 {
+			return "", errors.New(errNoBucketMatch)
+		}
+		if len(selected) > 1 {
+			sort.Strings(selected)
+			return "", errors.Errorf(errManyBucketsMatch, strings.Join(selected, ", "))
+		}
+		if bucket.Status.AtProvider.ID == "" {
+			return "", errors.New("selected Bucket has not been reconciled yet (ID is empty)")
+		}
+		return bucket.Status.AtProvider.ID, nil
+	}
+
+	return "", nil
+}
+
+// ResolveClusterBucketID resolves a bucket ID from a reference to a
+// ClusterBucket. A namespaced from may only reference a ClusterBucket that
+// lists its namespace in allowedNamespaces.
+func ResolveClusterBucketID(ctx context.Context, kube client.Reader, from metav1.Object, ref *xpv1.Reference) (string, error) {
+	bucket, err := referencedBucket(ctx, kube, "", ref)
+	if err != nil {
+		return "", err
+	}
+	if ns := from.GetNamespace(); ns != "" && !allowed(bucket.Spec.AllowedNamespaces, ns) {
+		return "", errors.Errorf(errNamespaceNotAllowed, ref.Name, ns)
+	}
+	return bucketID(bucket)
+}
+
+// referencedBucketID returns the ID of the referenced Bucket in the supplied
+// namespace, or of the referenced ClusterBucket if the namespace is empty.
+func referencedBucketID(ctx context.Context, kube client.Reader, namespace string, ref *xpv1.Reference) (string, error) {
+	bucket, err := referencedBucket(ctx, kube, namespace, ref)
+	if err != nil {
+		return "", err
+	}
+	return bucketID(bucket)
+}
+
+// referencedBucket returns the referenced Bucket in the supplied namespace, or
+// the referenced ClusterBucket if the namespace is empty.
+func referencedBucket(ctx context.Context, kube client.Reader, namespace string, ref *xpv1.Reference) (*v1alpha1.Bucket, error) {
+	if namespace == "" {
+		cb := &v1alpha1.ClusterBucket{}
+		if err := kube.Get(ctx, types.NamespacedName{Name: ref.Name}, cb); err != nil {
+			return nil, err
+		}
+		return (*v1alpha1.Bucket)(cb), nil
+	}
+	bucket := &v1alpha1.Bucket{}
+	if err := kube.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, bucket); err != nil {
+		return nil, err
+	}
+	return bucket, nil
+}
+
+// bucketID returns the ID of a referenced bucket, once it has one.
+func bucketID(bucket *v1alpha1.Bucket) (string, error) {
+	if bucket.Status.AtProvider.ID == "" {
+		return "", errors.New("referenced Bucket has not been reconciled yet (ID is empty)")
+	}
+	return bucket.Status.AtProvider.ID, nil
+}
+
+// allowed returns true if the supplied namespace is one of the allowed ones.
+func allowed(namespaces []string, namespace string) bool {
+	for _, ns := range namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// listBuckets lists the Buckets in the supplied namespace, or the
+// ClusterBuckets if the namespace is empty, that have the labels of sel.
+func listBuckets(ctx context.Context, kube client.Reader, namespace string, sel *xpv1.Selector) ([]*v1alpha1.Bucket, error) {
+	if namespace == "" {
+		l := &v1alpha1.ClusterBucketList{}
+		if err := kube.List(ctx, l, client.MatchingLabels(sel.MatchLabels)); err != nil {
+			return nil, err
+		}
+		buckets := make([]*v1alpha1.Bucket, len(l.Items))
+		for i := range l.Items {
+			buckets[i] = (*v1alpha1.Bucket)(&l.Items[i])
+		}
+		return buckets, nil
+	}
+	l := &v1alpha1.BucketList{}
+	if err := kube.List(ctx, l, client.InNamespace(namespace), client.MatchingLabels(sel.MatchLabels)); err != nil {
+		return nil, err
+	}
+	buckets := make([]*v1alpha1.Bucket, len(l.Items))
+	for i := range l.Items {
+		buckets[i] = &l.Items[i]
+	}
+	return buckets, nil
+}
+
+// ResolveAccessKeyID resolves an access key ID from a direct value, a
+// reference to a Key in the namespace of from, or a selector, in that order. A
+// selector must match exactly one Key. References and selectors of a
+// cluster-scoped from are to ClusterKeys. It returns an empty ID if none of
+// them is set.
+func ResolveAccessKeyID(ctx context.Context, kube client.Reader, from metav1.Object, id *string, ref *xpv1.Reference, sel *xpv1.Selector) (string, error) {
+	if id != nil && *id != "" {
+		return *id, nil
+	}
+
+	if ref != nil {
+		return referencedAccessKeyID(ctx, kube, from.GetNamespace(), ref)
+	}
+
+	if sel != nil {
+		keys, err := listKeys(ctx, kube, from.GetNamespace(), sel)
+		if err != nil {
+			return "", errors.Wrap(err, errListKeys)
+		}
+		var selected []string
+		var key *v1alpha1.Key
+		for _, k := range keys {
+			if Selects(sel, from, k) {
+				selected = append(selected, k.GetName())
+				key = k
+			}
+		}
+		if len(selected) == 0 {
+			return "", errors.New(errNoKeyMatch)
+		}
+		if len(selected) > 1 {
+			sort.Strings(selected)
+			return "", errors.Errorf(errManyKeysMatch, strings.Join(selected, ", "))
+		}
+		if key.Status.AtProvider.AccessKeyID == "" {
+			return "", errors.New("selected Key has not been reconciled yet (AccessKeyID is empty)")
+		}
+		return key.Status.AtProvider.AccessKeyID, nil
+	}
+
+	return "", nil
+}
+
+// ResolveClusterAccessKeyID resolves an access key ID from a reference to a
+// ClusterKey, which any namespaced resource may make.
+func ResolveClusterAccessKeyID(ctx context.Context, kube client.Reader, ref *xpv1.Reference) (string, error) {
+	return referencedAccessKeyID(ctx, kube, "", ref)
+}
+
+// referencedAccessKeyID returns the access key ID of the referenced Key in the
+// supplied namespace, or of the referenced ClusterKey if the namespace is
+// empty.
+func referencedAccessKeyID(ctx context.Context, kube client.Reader, namespace string, ref *xpv1.Reference) (string, error) {
+	var key *v1alpha1.Key
+	if namespace == "" {
+		ck := &v1alpha1.ClusterKey{}
+		if err := kube.Get(ctx, types.NamespacedName{Name: ref.Name}, ck); err != nil {
+			return "", err
+		}
+		key = (*v1alpha1.Key)(ck)
+	} else {
+		key = &v1alpha1.Key{}
+		if err := kube.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, key); err != nil {
+			return "", err
+		}
+	}
+	// Check if the Key has been reconciled and has an AccessKeyID
+	if key.Status.AtProvider.AccessKeyID == "" {
+		return "", errors.New("referenced Key has not been reconciled yet (AccessKeyID is empty)")
+	}
+	return key.Status.AtProvider.AccessKeyID, nil
+}
+
+// listKeys lists the Keys in the supplied namespace, or the ClusterKeys if the
+// namespace is empty, that have the labels of sel.
+func listKeys(ctx context.Context, kube client.Reader, namespace string, sel *xpv1.Selector) ([]*v1alpha1.Key, error) {
+	if namespace == "" {
+		l := &v1alpha1.ClusterKeyList{}
+		if err := kube.List(ctx, l, client.MatchingLabels(sel.MatchLabels)); err != nil {
+			return nil, err
+		}
+		keys := make([]*v1alpha1.Key, len(l.Items))
+		for i := range l.Items {
+			keys[i] = (*v1alpha1.Key)(&l.Items[i])
+		}
+		return keys, nil
+	}
+	l := &v1alpha1.KeyList{}
+	if err := kube.List(ctx, l, client.InNamespace(namespace), client.MatchingLabels(sel.MatchLabels)); err != nil {
+		return nil, err
+	}
+	keys := make([]*v1alpha1.Key, len(l.Items))
+	for i := range l.Items {
+		keys[i] = &l.Items[i]
+	}
+	return keys, nil
+}
+
+// KeyAccessForBucket returns the names of the KeyAccess resources in the
+// Bucket's namespace that reference it by name, selector or bucket ID. For a
+// ClusterBucket it returns the namespaced names of KeyAccess resources that
+// reference it by clusterBucketIdRef or bucket ID, and the names of
+// ClusterKeyAccess resources that reference it by either reference, selector
+// or bucket ID.
+func KeyAccessForBucket(ctx context.Context, kube client.Reader, b *v1alpha1.Bucket) ([]string, error) {
+	id := b.Status.AtProvider.ID
+	byID := func(ka *v1alpha1.KeyAccess) bool {
+		p := ka.Spec.ForProvider
+		return id != "" && ((p.BucketID != nil && *p.BucketID == id) || ka.Status.AtProvider.BucketID == id)
+	}
+	if b.GetNamespace() != "" {
+		return keyAccess(ctx, kube, b.GetNamespace(), func(ka *v1alpha1.KeyAccess) bool {
+			p := ka.Spec.ForProvider
+			return refersTo(p.BucketIDRef, b) || Selects(p.BucketIDSelector, ka, b) || byID(ka)
+		})
+	}
+	return clusterKeyAccess(ctx, kube,
+		func(ka *v1alpha1.KeyAccess) bool {
+			return refersTo(ka.Spec.ForProvider.ClusterBucketIDRef, b) || byID(ka)
+		},
+		func(ka *v1alpha1.KeyAccess) bool {
+			p := ka.Spec.ForProvider
+			return refersTo(p.BucketIDRef, b) || refersTo(p.ClusterBucketIDRef, b) || Selects(p.BucketIDSelector, ka, b) || byID(ka)
+		})
+}
+
+// KeyAccessForKey returns the names of the KeyAccess resources in the Key's
+// namespace that reference it by name, selector or access key ID. For a
+// ClusterKey it returns the namespaced names of KeyAccess resources that
+// reference it by clusterAccessKeyIdRef or access key ID, and the names of
+// ClusterKeyAccess resources that reference it by either reference, selector
+// or access key ID.
+func KeyAccessForKey(ctx context.Context, kube client.Reader, k *v1alpha1.Key) ([]string, error) {
+	namespaced, cluster := keyAccessForKey(k)
+	if k.GetNamespace() != "" {
+		return keyAccess(ctx, kube, k.GetNamespace(), namespaced)
+	}
+	return clusterKeyAccess(ctx, kube, namespaced, cluster)
+}
+
+// keyAccessForKey returns the functions that match the KeyAccess and
+// ClusterKeyAccess resources that reference the Key. Those of a namespaced Key
+// are KeyAccess resources in its namespace.
+func keyAccessForKey(k *v1alpha1.Key) (namespaced, cluster func(*v1alpha1.KeyAccess) bool) {
+	id := k.Status.AtProvider.AccessKeyID
+	byID := func(ka *v1alpha1.KeyAccess) bool {
+		p := ka.Spec.ForProvider
+		return id != "" && ((p.AccessKeyID != nil && *p.AccessKeyID == id) || ka.Status.AtProvider.AccessKeyID == id)
+	}
+	if k.GetNamespace() != "" {
+		return func(ka *v1alpha1.KeyAccess) bool {
+			p := ka.Spec.ForProvider
+			return refersTo(p.AccessKeyIDRef, k) || Selects(p.AccessKeyIDSelector, ka, k) || byID(ka)
+		}, nil
+	}
+	return func(ka *v1alpha1.KeyAccess) bool {
+			return refersTo(ka.Spec.ForProvider.ClusterAccessKeyIDRef, k) || byID(ka)
+		},
+		func(ka *v1alpha1.KeyAccess) bool {
+			p := ka.Spec.ForProvider
+			return refersTo(p.AccessKeyIDRef, k) || refersTo(p.ClusterAccessKeyIDRef, k) || Selects(p.AccessKeyIDSelector, ka, k) || byID(ka)
+		}
+}
+
+func refersTo(ref *xpv1.Reference, o metav1.Object) bool {
+	return ref != nil && ref.Name == o.GetName()
+}
+
+func keyAccess(ctx context.Context, kube client.Reader, namespace string, matches func(*v1alpha1.KeyAccess) bool) ([]string, error) {
+	l := &v1alpha1.KeyAccessList{}
+	if err := kube.List(ctx, l, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrap(err, errListKeyAccess)
+	}
+	var names []string
+	for i := range l.Items {
+		if matches(&l.Items[i]) {
+			names = append(names, l.Items[i].GetName())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// clusterKeyAccess returns the namespace/name of the KeyAccess resources in
+// any namespace that match namespaced, and the names of the ClusterKeyAccess
+// resources that match cluster.
+func clusterKeyAccess(ctx context.Context, kube client.Reader, namespaced, cluster func(*v1alpha1.KeyAccess) bool) ([]string, error) {
+	l := &v1alpha1.KeyAccessList{}
+	if err := kube.List(ctx, l); err != nil {
+		return nil, errors.Wrap(err, errListKeyAccess)
+	}
+	var names []string
+	for i := range l.Items {
+		if namespaced(&l.Items[i]) {
+			names = append(names, l.Items[i].GetNamespace()+"/"+l.Items[i].GetName())
+		}
+	}
+	cl := &v1alpha1.ClusterKeyAccessList{}
+	if err := kube.List(ctx, cl); err != nil {
+		return nil, errors.Wrap(err, errListClusterKeyAccess)
+	}
+	for i := range cl.Items {
+		if cluster((*v1alpha1.KeyAccess)(&cl.Items[i])) {
+			names = append(names, cl.Items[i].GetName())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// PoliciesForBucket returns the names of the BucketAccessPolicy resources in
+// the Bucket's namespace that reference it by name, selector or bucket ID. A
+// ClusterBucket can only be referenced by ID, by policies in any namespace,
+// whose namespaced names are returned.
+func PoliciesForBucket(ctx context.Context, kube client.Reader, b *v1alpha1.Bucket) ([]string, error) {
+	id := b.Status.AtProvider.ID
+	byID := func(bap *v1alpha1.BucketAccessPolicy) bool {
+		p := bap.Spec.ForProvider
+		return id != "" && ((p.BucketID != nil && *p.BucketID == id) || bap.Status.AtProvider.BucketID == id)
+	}
+	if b.GetNamespace() != "" {
+		return policies(ctx, kube, b.GetNamespace(), func(bap *v1alpha1.BucketAccessPolicy) bool {
+			p := bap.Spec.ForProvider
+			return refersTo(p.BucketIDRef, b) || Selects(p.BucketIDSelector, bap, b) || byID(bap)
+		})
+	}
+	return policies(ctx, kube, "", byID)
+}
+
+// PoliciesForKey returns the names of the BucketAccessPolicy resources in the
+// Key's namespace with a grant that references it by name, selector or access
+// key ID, or that granted it access. A ClusterKey can only be referenced by
+// ID, by policies in any namespace, whose namespaced names are returned.
+func PoliciesForKey(ctx context.Context, kube client.Reader, k *v1alpha1.Key) ([]string, error) {
+	return policies(ctx, kube, k.GetNamespace(), policiesForKey(k))
+}
+
+// policiesForKey returns a function that matches the BucketAccessPolicy
+// resources with a grant for the Key.
+func policiesForKey(k *v1alpha1.Key) func(*v1alpha1.BucketAccessPolicy) bool {
+	id := k.Status.AtProvider.AccessKeyID
+	namespaced := k.GetNamespace() != ""
+	return func(bap *v1alpha1.BucketAccessPolicy) bool {
+		for _, g := range bap.Spec.ForProvider.Grants {
+			if namespaced && (refersTo(g.AccessKeyIDRef, k) || Selects(g.AccessKeyIDSelector, bap, k)) {
+				return true
+			}
+			if id != "" && g.AccessKeyID != nil && *g.AccessKeyID == id {
+				return true
+			}
+		}
+		for _, g := range bap.Status.AtProvider.Grants {
+			if id != "" && g.AccessKeyID == id {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// GrantedBuckets returns the IDs of the buckets that the KeyAccess and
+// BucketAccessPolicy resources which reference the Key grant it access to. A
+// resource whose bucket is only referenced is included once it resolved the
+// bucket ID.
+func GrantedBuckets(ctx context.Context, kube client.Reader, k *v1alpha1.Key) ([]string, error) {
+	var ids []string
+	add := func(id *string, observed string) {
+		if id != nil && *id != "" {
+			ids = append(ids, *id)
+		}
+		if observed != "" {
+			ids = append(ids, observed)
+		}
+	}
+
+	namespaced, cluster := keyAccessForKey(k)
+	kal := &v1alpha1.KeyAccessList{}
+	if err := kube.List(ctx, kal, client.InNamespace(k.GetNamespace())); err != nil {
+		return nil, errors.Wrap(err, errListKeyAccess)
+	}
+	for i := range kal.Items {
+		if namespaced(&kal.Items[i]) {
+			add(kal.Items[i].Spec.ForProvider.BucketID, kal.Items[i].Status.AtProvider.BucketID)
+		}
+	}
+	if cluster != nil {
+		cl := &v1alpha1.ClusterKeyAccessList{}
+		if err := kube.List(ctx, cl); err != nil {
+			return nil, errors.Wrap(err, errListClusterKeyAccess)
+		}
+		for i := range cl.Items {
+			if cluster((*v1alpha1.KeyAccess)(&cl.Items[i])) {
+				add(cl.Items[i].Spec.ForProvider.BucketID, cl.Items[i].Status.AtProvider.BucketID)
+			}
+		}
+	}
+
+	matches := policiesForKey(k)
+	bl := &v1alpha1.BucketAccessPolicyList{}
+	if err := kube.List(ctx, bl, client.InNamespace(k.GetNamespace())); err != nil {
+		return nil, errors.Wrap(err, errListPolicies)
+	}
+	for i := range bl.Items {
+		if matches(&bl.Items[i]) {
+			add(bl.Items[i].Spec.ForProvider.BucketID, bl.Items[i].Status.AtProvider.BucketID)
+		}
+	}
+
+	sort.Strings(ids)
+	return slices.Compact(ids), nil
+}
+
+// policies returns the names of the BucketAccessPolicy resources in the
+// supplied namespace that match, or the namespace/name of those in any
+// namespace if it is empty.
+func policies(ctx context.Context, kube client.Reader, namespace string, matches func(*v1alpha1.BucketAccessPolicy) bool) ([]string, error) {
+	l := &v1alpha1.BucketAccessPolicyList{}
+	if err := kube.List(ctx, l, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrap(err, errListPolicies)
+	}
+	var names []string
+	for i := range l.Items {
+		if !matches(&l.Items[i]) {
+			continue
+		}
+		if namespace == "" {
+			names = append(names, l.Items[i].GetNamespace()+"/"+l.Items[i].GetName())
+			continue
+		}
+		names = append(names, l.Items[i].GetName())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// KeysForBucket returns the names of the Keys in the Bucket's namespace whose
+// bucketAccess references it by name, selector, bucket ID or alias, or that
+// granted themselves access to it. For a ClusterBucket it returns the
+// namespaced names of Keys that reference it by ID or alias, and the names of
+// ClusterKeys that reference it in any way.
+func KeysForBucket(ctx context.Context, kube client.Reader, b *v1alpha1.Bucket) ([]string, error) {
+	id := b.Status.AtProvider.ID
+	lists := func(k *v1alpha1.Key, byName bool) bool {
+		for _, a := range k.Spec.ForProvider.BucketAccess {
+			if byName && (refersTo(a.BucketIDRef, b) || Selects(a.BucketIDSelector, k, b)) {
+				return true
+			}
+			if id != "" && a.BucketID != nil && *a.BucketID == id {
+				return true
+			}
+			if a.BucketAlias != nil && slices.Contains(b.Status.AtProvider.GlobalAliases, *a.BucketAlias) {
+				return true
+			}
+		}
+		for _, o := range k.Status.AtProvider.Buckets {
+			if id != "" && o.ID == id && o.BucketAccessPermissions != nil {
+				return true
+			}
+		}
+		return false
+	}
+	if b.GetNamespace() != "" {
+		l := &v1alpha1.KeyList{}
+		if err := kube.List(ctx, l, client.InNamespace(b.GetNamespace())); err != nil {
+			return nil, errors.Wrap(err, errListKeyResources)
+		}
+		var names []string
+		for i := range l.Items {
+			if lists(&l.Items[i], true) {
+				names = append(names, l.Items[i].GetName())
+			}
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+
+	l := &v1alpha1.KeyList{}
+	if err := kube.List(ctx, l); err != nil {
+		return nil, errors.Wrap(err, errListKeyResources)
+	}
+	var names []string
+	for i := range l.Items {
+		if lists(&l.Items[i], false) {
+			names = append(names, l.Items[i].GetNamespace()+"/"+l.Items[i].GetName())
+		}
+	}
+	cl := &v1alpha1.ClusterKeyList{}
+	if err := kube.List(ctx, cl); err != nil {
+		return nil, errors.Wrap(err, errListClusterKeys)
+	}
+	for i := range cl.Items {
+		if lists((*v1alpha1.Key)(&cl.Items[i]), true) {
+			names = append(names, cl.Items[i].GetName())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Dependents are the names of the resources of a kind that depend on a Bucket
+// or Key.
+type Dependents struct {
+	Kind  string
+	Names []string
+}
+
+// BucketDependents returns the resources that grant access to the Bucket, so
+// that it is not deleted while they would be unable to revoke it: KeyAccess,
+// BucketAccessPolicy and Key resources. Kinds without dependents are omitted.
+func BucketDependents(ctx context.Context, kube client.Reader, b *v1alpha1.Bucket) ([]Dependents, error) {
+	return dependents(ctx, kube, b, map[string]func(context.Context, client.Reader, *v1alpha1.Bucket) ([]string, error){
+		v1alpha1.KeyAccessKind:          KeyAccessForBucket,
+		v1alpha1.BucketAccessPolicyKind: PoliciesForBucket,
+		v1alpha1.KeyKind:                KeysForBucket,
+	})
+}
+
+// KeyDependents returns the resources that grant the Key access to buckets,
+// which Garage silently drops when the key is deleted: KeyAccess and
+// BucketAccessPolicy resources. Kinds without dependents are omitted.
+func KeyDependents(ctx context.Context, kube client.Reader, k *v1alpha1.Key) ([]Dependents, error) {
+	return dependents(ctx, kube, k, map[string]func(context.Context, client.Reader, *v1alpha1.Key) ([]string, error){
+		v1alpha1.KeyAccessKind:          KeyAccessForKey,
+		v1alpha1.BucketAccessPolicyKind: PoliciesForKey,
+	})
+}
+
+// dependents returns the dependents of each kind found by the supplied
+// functions, sorted by kind.
+func dependents[T any](ctx context.Context, kube client.Reader, o T, fns map[string]func(context.Context, client.Reader, T) ([]string, error)) ([]Dependents, error) {
+	kinds := make([]string, 0, len(fns))
+	for kind := range fns {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	var deps []Dependents
+	for _, kind := range kinds {
+		names, err := fns[kind](ctx, kube, o)
+		if err != nil {
+			return nil, err
+		}
+		if len(names) > 0 {
+			deps = append(deps, Dependents{Kind: kind, Names: names})
+		}
+	}
+	return deps, nil
+}
+
+// EnqueueDeletingBuckets returns a function that maps a resource that may
+// depend on a Bucket to the Buckets in its namespace that are being deleted,
+// so that a Bucket waiting on its dependents is reconciled as they go away.
+// Dependents name their bucket by reference, selector, ID or alias, so every
+// waiting Bucket checks its dependents again.
+func EnqueueDeletingBuckets(kube client.Reader) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		if o.GetNamespace() == "" {
+			return nil
+		}
+		l := &v1alpha1.BucketList{}
+		if err := kube.List(ctx, l, client.InNamespace(o.GetNamespace())); err != nil {
+			return nil
+		}
+		var reqs []reconcile.Request
+		for i := range l.Items {
+			reqs = append(reqs, deleting(&l.Items[i])...)
+		}
+		return reqs
+	}
+}
+
+// EnqueueDeletingClusterBuckets returns a function that maps a resource that
+// may depend on a ClusterBucket to the ClusterBuckets that are being deleted,
+// so that a ClusterBucket waiting on its dependents is reconciled as they go
+// away.
+func EnqueueDeletingClusterBuckets(kube client.Reader) handler.MapFunc {
+	return func(ctx context.Context, _ client.Object) []reconcile.Request {
+		l := &v1alpha1.ClusterBucketList{}
+		if err := kube.List(ctx, l); err != nil {
+			return nil
+		}
+		var reqs []reconcile.Request
+		for i := range l.Items {
+			reqs = append(reqs, deleting(&l.Items[i])...)
+		}
+		return reqs
+	}
+}
+
+// EnqueueDeletingKeys returns a function that maps a resource that may depend
+// on a Key to the Keys in its namespace that are being deleted, so that a Key
+// waiting on its dependents is reconciled as they go away.
+func EnqueueDeletingKeys(kube client.Reader) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		if o.GetNamespace() == "" {
+			return nil
+		}
+		l := &v1alpha1.KeyList{}
+		if err := kube.List(ctx, l, client.InNamespace(o.GetNamespace())); err != nil {
+			return nil
+		}
+		var reqs []reconcile.Request
+		for i := range l.Items {
+			reqs = append(reqs, deleting(&l.Items[i])...)
+		}
+		return reqs
+	}
+}
+
+// EnqueueDeletingClusterKeys returns a function that maps a resource that may
+// depend on a ClusterKey to the ClusterKeys that are being deleted, so that a
+// ClusterKey waiting on its dependents is reconciled as they go away.
+func EnqueueDeletingClusterKeys(kube client.Reader) handler.MapFunc {
+	return func(ctx context.Context, _ client.Object) []reconcile.Request {
+		l := &v1alpha1.ClusterKeyList{}
+		if err := kube.List(ctx, l); err != nil {
+			return nil
+		}
+		var reqs []reconcile.Request
+		for i := range l.Items {
+			reqs = append(reqs, deleting(&l.Items[i])...)
+		}
+		return reqs
+	}
+}
+
+// deleting returns a request for the supplied object if it is being deleted.
+func deleting(o metav1.Object) []reconcile.Request {
+	if o.GetDeletionTimestamp() == nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()}}}
+}
+
+// Message describes why a resource of the supplied kind cannot be deleted
+// while the supplied resources still depend on it.
+func Message(kind string, deps []Dependents) string {
+	refs := make([]string, len(deps))
+	for i, d := range deps {
+		refs[i] = fmt.Sprintf("%d %s resource(s): %s", len(d.Names), d.Kind, strings.Join(d.Names, ", "))
+	}
+	return fmt.Sprintf("%s is referenced by %s; delete them first or set the %s annotation to \"true\"",
+		kind, strings.Join(refs, "; "), v1alpha1.AnnotationKeyForceDelete)
+}
@@ -0,0 +1,779 @@
+package dependency
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+)
+
+func TestSelects(t *testing.T) {
+	yes := true
+	owner := metav1.OwnerReference{UID: types.UID("xr"), Controller: &yes}
+	other := metav1.OwnerReference{UID: types.UID("other"), Controller: &yes}
+
+	cases := map[string]struct {
+		reason    string
+		sel       *xpv1.Selector
+		from      metav1.Object
+		candidate metav1.Object
+		want      bool
+	}{
+		"NilSelector": {
+			reason:    "A nil selector should select nothing",
+			from:      &v1alpha1.KeyAccess{},
+			candidate: &v1alpha1.Bucket{},
+			want:      false,
+		},
+		"LabelsMatch": {
+			reason:    "Should select a candidate whose labels match",
+			sel:       &xpv1.Selector{MatchLabels: map[string]string{"app": "web"}},
+			from:      &v1alpha1.KeyAccess{},
+			candidate: &v1alpha1.Bucket{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}}},
+			want:      true,
+		},
+		"LabelsDoNotMatch": {
+			reason:    "Should not select a candidate whose labels do not match",
+			sel:       &xpv1.Selector{MatchLabels: map[string]string{"app": "web"}},
+			from:      &v1alpha1.KeyAccess{},
+			candidate: &v1alpha1.Bucket{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "db"}}},
+			want:      false,
+		},
+		"ControllerRefMatches": {
+			reason:    "Should select a candidate with the same controller",
+			sel:       &xpv1.Selector{MatchControllerRef: &yes},
+			from:      &v1alpha1.KeyAccess{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}},
+			candidate: &v1alpha1.Bucket{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}},
+			want:      true,
+		},
+		"ControllerRefDiffers": {
+			reason:    "Should not select a candidate with a different controller",
+			sel:       &xpv1.Selector{MatchControllerRef: &yes},
+			from:      &v1alpha1.KeyAccess{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}},
+			candidate: &v1alpha1.Bucket{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{other}}},
+			want:      false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Selects(tc.sel, tc.from, tc.candidate)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nSelects(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestKeyAccessForBucket(t *testing.T) {
+	bucketID := "bucket-123"
+	otherID := "bucket-456"
+
+	bucket := &v1alpha1.Bucket{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-bucket", Labels: map[string]string{"app": "web"}},
+		Status:     v1alpha1.BucketStatus{AtProvider: v1alpha1.BucketObservation{ID: bucketID}},
+	}
+
+	list := func(items ...v1alpha1.KeyAccess) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			obj.(*v1alpha1.KeyAccessList).Items = items
+			return nil
+		}
+	}
+	ka := func(name string, p v1alpha1.KeyAccessParameters) v1alpha1.KeyAccess {
+		return v1alpha1.KeyAccess{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       v1alpha1.KeyAccessSpec{ForProvider: p},
+		}
+	}
+
+	type want struct {
+		names []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Reader
+		want   want
+	}{
+		"ListError": {
+			reason: "Should return an error if KeyAccess resources cannot be listed",
+			kube:   &test.MockClient{MockList: test.NewMockListFn(errors.New("boom"))},
+			want:   want{err: errors.Wrap(errors.New("boom"), errListKeyAccess)},
+		},
+		"NoDependents": {
+			reason: "Should return no names when no KeyAccess references the Bucket",
+			kube: &test.MockClient{MockList: list(
+				ka("other-ref", v1alpha1.KeyAccessParameters{BucketIDRef: &xpv1.Reference{Name: "other"}}),
+				ka("other-id", v1alpha1.KeyAccessParameters{BucketID: &otherID}),
+			)},
+			want: want{},
+		},
+		"Dependents": {
+			reason: "Should return KeyAccess resources referencing the Bucket by name, selector or ID, sorted",
+			kube: &test.MockClient{MockList: list(
+				ka("ref", v1alpha1.KeyAccessParameters{BucketIDRef: &xpv1.Reference{Name: "my-bucket"}}),
+				ka("id", v1alpha1.KeyAccessParameters{BucketID: &bucketID}),
+				ka("selector", v1alpha1.KeyAccessParameters{BucketIDSelector: &xpv1.Selector{MatchLabels: map[string]string{"app": "web"}}}),
+				ka("other-ref", v1alpha1.KeyAccessParameters{BucketIDRef: &xpv1.Reference{Name: "other"}}),
+			)},
+			want: want{names: []string{"id", "ref", "selector"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := KeyAccessForBucket(context.Background(), tc.kube, bucket)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nKeyAccessForBucket(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.names, got); diff != "" {
+				t.Errorf("\n%s\nKeyAccessForBucket(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestKeyAccessForClusterBucket(t *testing.T) {
+	bucketID := "bucket-123"
+
+	bucket := (*v1alpha1.Bucket)(&v1alpha1.ClusterBucket{
+		ObjectMeta: metav1.ObjectMeta{Name: "platform", Labels: map[string]string{"app": "loki"}},
+		Status:     v1alpha1.BucketStatus{AtProvider: v1alpha1.BucketObservation{ID: bucketID}},
+	})
+
+	list := func(namespaced []v1alpha1.KeyAccess, cluster []v1alpha1.ClusterKeyAccess) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			switch l := obj.(type) {
+			case *v1alpha1.KeyAccessList:
+				l.Items = namespaced
+			case *v1alpha1.ClusterKeyAccessList:
+				l.Items = cluster
+			}
+			return nil
+		}
+	}
+	ka := func(name string, p v1alpha1.KeyAccessParameters) v1alpha1.KeyAccess {
+		return v1alpha1.KeyAccess{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: name},
+			Spec:       v1alpha1.KeyAccessSpec{ForProvider: p},
+		}
+	}
+	cka := func(name string, p v1alpha1.KeyAccessParameters) v1alpha1.ClusterKeyAccess {
+		return v1alpha1.ClusterKeyAccess{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1alpha1.KeyAccessSpec{ForProvider: p},
+		}
+	}
+
+	type want struct {
+		names []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Reader
+		want   want
+	}{
+		"ListError": {
+			reason: "Should return an error if KeyAccess resources cannot be listed",
+			kube:   &test.MockClient{MockList: test.NewMockListFn(errors.New("boom"))},
+			want:   want{err: errors.Wrap(errors.New("boom"), errListKeyAccess)},
+		},
+		"Dependents": {
+			reason: "Should return KeyAccess resources referencing the ClusterBucket by clusterBucketIdRef or ID, and ClusterKeyAccess resources by either reference, selector or ID",
+			kube: &test.MockClient{MockList: list(
+				[]v1alpha1.KeyAccess{
+					ka("cluster-ref", v1alpha1.KeyAccessParameters{ClusterBucketIDRef: &xpv1.Reference{Name: "platform"}}),
+					ka("namespaced-ref", v1alpha1.KeyAccessParameters{BucketIDRef: &xpv1.Reference{Name: "platform"}}),
+					ka("id", v1alpha1.KeyAccessParameters{BucketID: &bucketID}),
+				},
+				[]v1alpha1.ClusterKeyAccess{
+					cka("ref", v1alpha1.KeyAccessParameters{BucketIDRef: &xpv1.Reference{Name: "platform"}}),
+					cka("cluster-ref", v1alpha1.KeyAccessParameters{ClusterBucketIDRef: &xpv1.Reference{Name: "platform"}}),
+					cka("selector", v1alpha1.KeyAccessParameters{BucketIDSelector: &xpv1.Selector{MatchLabels: map[string]string{"app": "loki"}}}),
+					cka("other-ref", v1alpha1.KeyAccessParameters{BucketIDRef: &xpv1.Reference{Name: "other"}}),
+				},
+			)},
+			want: want{names: []string{"cluster-ref", "ref", "selector", "team-a/cluster-ref", "team-a/id"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := KeyAccessForBucket(context.Background(), tc.kube, bucket)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nKeyAccessForBucket(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.names, got); diff != "" {
+				t.Errorf("\n%s\nKeyAccessForBucket(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestResolveBucketID(t *testing.T) {
+	bucketID := "bucket-123"
+	sel := &xpv1.Selector{MatchLabels: map[string]string{"app": "web"}}
+	from := &v1alpha1.KeyAccess{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "access"}}
+
+	bucket := func(name, id string) v1alpha1.Bucket {
+		return v1alpha1.Bucket{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: sel.MatchLabels},
+			Status:     v1alpha1.BucketStatus{AtProvider: v1alpha1.BucketObservation{ID: id}},
+		}
+	}
+	list := func(items ...v1alpha1.Bucket) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			obj.(*v1alpha1.BucketList).Items = items
+			return nil
+		}
+	}
+
+	type args struct {
+		id  *string
+		ref *xpv1.Reference
+		sel *xpv1.Selector
+	}
+	type want struct {
+		id  string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Reader
+		args   args
+		want   want
+	}{
+		"None": {
+			reason: "Should return an empty ID if no ID, reference or selector is set",
+			kube:   &test.MockClient{},
+			want:   want{},
+		},
+		"ID": {
+			reason: "Should return a literal ID without reading the API server",
+			kube:   &test.MockClient{},
+			args:   args{id: &bucketID, sel: sel},
+			want:   want{id: bucketID},
+		},
+		"Reference": {
+			reason: "Should return the ID of the referenced Bucket",
+			kube: &test.MockClient{MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+				if key.Namespace != "default" || key.Name != "my-bucket" {
+					return errors.Errorf("unexpected key %s", key)
+				}
+				obj.(*v1alpha1.Bucket).Status.AtProvider.ID = bucketID
+				return nil
+			}},
+			args: args{ref: &xpv1.Reference{Name: "my-bucket"}},
+			want: want{id: bucketID},
+		},
+		"ListError": {
+			reason: "Should return an error if the selected Buckets cannot be listed",
+			kube:   &test.MockClient{MockList: test.NewMockListFn(errors.New("boom"))},
+			args:   args{sel: sel},
+			want:   want{err: errors.Wrap(errors.New("boom"), errListBuckets)},
+		},
+		"NoMatch": {
+			reason: "Should return an error if no Bucket matches the selector",
+			kube:   &test.MockClient{MockList: list()},
+			args:   args{sel: sel},
+			want:   want{err: errors.New(errNoBucketMatch)},
+		},
+		"OneMatch": {
+			reason: "Should return the ID of the only Bucket matching the selector",
+			kube:   &test.MockClient{MockList: list(bucket("my-bucket", bucketID))},
+			args:   args{sel: sel},
+			want:   want{id: bucketID},
+		},
+		"NotReconciled": {
+			reason: "Should return an error if the selected Bucket has no ID yet",
+			kube:   &test.MockClient{MockList: list(bucket("my-bucket", ""))},
+			args:   args{sel: sel},
+			want:   want{err: errors.New("selected Bucket has not been reconciled yet (ID is empty)")},
+		},
+		"ManyMatches": {
+			reason: "Should refuse to pick one of several Buckets matching the selector",
+			kube:   &test.MockClient{MockList: list(bucket("web-b", "bucket-456"), bucket("web-a", bucketID))},
+			args:   args{sel: sel},
+			want:   want{err: errors.Errorf(errManyBucketsMatch, "web-a, web-b")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			id, err := ResolveBucketID(context.Background(), tc.kube, from, tc.args.id, tc.args.ref, tc.args.sel)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolveBucketID(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.id, id); diff != "" {
+				t.Errorf("\n%s\nResolveBucketID(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestResolveAccessKeyID(t *testing.T) {
+	accessKeyID := "GK123"
+	sel := &xpv1.Selector{MatchLabels: map[string]string{"app": "web"}}
+
+	key := func(name, id string) v1alpha1.Key {
+		return v1alpha1.Key{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: sel.MatchLabels},
+			Status:     v1alpha1.KeyStatus{AtProvider: v1alpha1.KeyObservation{AccessKeyID: id}},
+		}
+	}
+	list := func(items ...v1alpha1.Key) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			switch l := obj.(type) {
+			case *v1alpha1.KeyList:
+				l.Items = items
+			case *v1alpha1.ClusterKeyList:
+				for _, k := range items {
+					l.Items = append(l.Items, v1alpha1.ClusterKey(k))
+				}
+			}
+			return nil
+		}
+	}
+
+	type want struct {
+		id  string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Reader
+		from   metav1.Object
+		want   want
+	}{
+		"ListError": {
+			reason: "Should return an error if the selected Keys cannot be listed",
+			kube:   &test.MockClient{MockList: test.NewMockListFn(errors.New("boom"))},
+			from:   &v1alpha1.KeyAccess{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}},
+			want:   want{err: errors.Wrap(errors.New("boom"), errListKeys)},
+		},
+		"NoMatch": {
+			reason: "Should return an error if no Key matches the selector",
+			kube:   &test.MockClient{MockList: list()},
+			from:   &v1alpha1.KeyAccess{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}},
+			want:   want{err: errors.New(errNoKeyMatch)},
+		},
+		"OneMatch": {
+			reason: "Should return the access key ID of the only Key matching the selector",
+			kube:   &test.MockClient{MockList: list(key("my-key", accessKeyID))},
+			from:   &v1alpha1.KeyAccess{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}},
+			want:   want{id: accessKeyID},
+		},
+		"ClusterScoped": {
+			reason: "Should select a ClusterKey for a cluster-scoped resource",
+			kube:   &test.MockClient{MockList: list(key("platform", accessKeyID))},
+			from:   &v1alpha1.ClusterKeyAccess{},
+			want:   want{id: accessKeyID},
+		},
+		"ManyMatches": {
+			reason: "Should refuse to pick one of several Keys matching the selector",
+			kube:   &test.MockClient{MockList: list(key("web-b", "GK456"), key("web-a", accessKeyID))},
+			from:   &v1alpha1.KeyAccess{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}},
+			want:   want{err: errors.Errorf(errManyKeysMatch, "web-a, web-b")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			id, err := ResolveAccessKeyID(context.Background(), tc.kube, tc.from, nil, nil, sel)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolveAccessKeyID(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.id, id); diff != "" {
+				t.Errorf("\n%s\nResolveAccessKeyID(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestResolveClusterBucketID(t *testing.T) {
+	ref := &xpv1.Reference{Name: "platform"}
+	get := func(allowed ...string) test.MockGetFn {
+		return func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			cb := obj.(*v1alpha1.ClusterBucket)
+			cb.Spec.AllowedNamespaces = allowed
+			cb.Status.AtProvider.ID = "bucket-123"
+			return nil
+		}
+	}
+
+	type want struct {
+		id  string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Reader
+		from   metav1.Object
+		want   want
+	}{
+		"GetError": {
+			reason: "Should return an error if the ClusterBucket cannot be read",
+			kube:   &test.MockClient{MockGet: test.NewMockGetFn(errors.New("boom"))},
+			from:   &v1alpha1.KeyAccess{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}},
+			want:   want{err: errors.New("boom")},
+		},
+		"NamespaceAllowed": {
+			reason: "Should resolve a ClusterBucket that allows the namespace of the KeyAccess",
+			kube:   &test.MockClient{MockGet: get("team-a")},
+			from:   &v1alpha1.KeyAccess{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}},
+			want:   want{id: "bucket-123"},
+		},
+		"NamespaceNotAllowed": {
+			reason: "Should refuse a ClusterBucket that does not allow the namespace of the KeyAccess",
+			kube:   &test.MockClient{MockGet: get("team-b")},
+			from:   &v1alpha1.KeyAccess{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}},
+			want:   want{err: errors.Errorf(errNamespaceNotAllowed, "platform", "team-a")},
+		},
+		"ClusterScoped": {
+			reason: "Should resolve a ClusterBucket for a ClusterKeyAccess regardless of allowedNamespaces",
+			kube:   &test.MockClient{MockGet: get()},
+			from:   &v1alpha1.ClusterKeyAccess{},
+			want:   want{id: "bucket-123"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			id, err := ResolveClusterBucketID(context.Background(), tc.kube, tc.from, ref)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolveClusterBucketID(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.id, id); diff != "" {
+				t.Errorf("\n%s\nResolveClusterBucketID(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPoliciesForBucket(t *testing.T) {
+	bucketID := "bucket-123"
+	otherID := "bucket-456"
+
+	list := func(items ...v1alpha1.BucketAccessPolicy) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			obj.(*v1alpha1.BucketAccessPolicyList).Items = items
+			return nil
+		}
+	}
+	bap := func(namespace, name string, p v1alpha1.BucketAccessPolicyParameters, observed string) v1alpha1.BucketAccessPolicy {
+		return v1alpha1.BucketAccessPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       v1alpha1.BucketAccessPolicySpec{ForProvider: p},
+			Status:     v1alpha1.BucketAccessPolicyStatus{AtProvider: v1alpha1.BucketAccessPolicyObservation{BucketID: observed}},
+		}
+	}
+
+	type want struct {
+		names []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Reader
+		bucket *v1alpha1.Bucket
+		want   want
+	}{
+		"ListError": {
+			reason: "Should return an error if BucketAccessPolicy resources cannot be listed",
+			kube:   &test.MockClient{MockList: test.NewMockListFn(errors.New("boom"))},
+			bucket: &v1alpha1.Bucket{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-bucket"}},
+			want:   want{err: errors.Wrap(errors.New("boom"), errListPolicies)},
+		},
+		"Bucket": {
+			reason: "Should return policies referencing the Bucket by name, selector, ID or observed ID, sorted",
+			kube: &test.MockClient{MockList: list(
+				bap("default", "ref", v1alpha1.BucketAccessPolicyParameters{BucketIDRef: &xpv1.Reference{Name: "my-bucket"}}, ""),
+				bap("default", "selector", v1alpha1.BucketAccessPolicyParameters{BucketIDSelector: &xpv1.Selector{MatchLabels: map[string]string{"app": "web"}}}, ""),
+				bap("default", "id", v1alpha1.BucketAccessPolicyParameters{BucketID: &bucketID}, ""),
+				bap("default", "observed", v1alpha1.BucketAccessPolicyParameters{}, bucketID),
+				bap("default", "other", v1alpha1.BucketAccessPolicyParameters{BucketID: &otherID}, otherID),
+			)},
+			bucket: &v1alpha1.Bucket{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-bucket", Labels: map[string]string{"app": "web"}},
+				Status:     v1alpha1.BucketStatus{AtProvider: v1alpha1.BucketObservation{ID: bucketID}},
+			},
+			want: want{names: []string{"id", "observed", "ref", "selector"}},
+		},
+		"ClusterBucket": {
+			reason: "Should return the namespaced names of policies referencing a ClusterBucket by ID, ignoring references by name",
+			kube: &test.MockClient{MockList: list(
+				bap("team-a", "ref", v1alpha1.BucketAccessPolicyParameters{BucketIDRef: &xpv1.Reference{Name: "platform"}}, ""),
+				bap("team-a", "id", v1alpha1.BucketAccessPolicyParameters{BucketID: &bucketID}, ""),
+				bap("team-b", "observed", v1alpha1.BucketAccessPolicyParameters{}, bucketID),
+			)},
+			bucket: (*v1alpha1.Bucket)(&v1alpha1.ClusterBucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "platform"},
+				Status:     v1alpha1.BucketStatus{AtProvider: v1alpha1.BucketObservation{ID: bucketID}},
+			}),
+			want: want{names: []string{"team-a/id", "team-b/observed"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := PoliciesForBucket(context.Background(), tc.kube, tc.bucket)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPoliciesForBucket(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.names, got); diff != "" {
+				t.Errorf("\n%s\nPoliciesForBucket(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPoliciesForKey(t *testing.T) {
+	accessKeyID := "GK123"
+	otherID := "GK456"
+
+	list := func(items ...v1alpha1.BucketAccessPolicy) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			obj.(*v1alpha1.BucketAccessPolicyList).Items = items
+			return nil
+		}
+	}
+	bap := func(namespace, name string, g v1alpha1.BucketAccessGrant, observed string) v1alpha1.BucketAccessPolicy {
+		p := v1alpha1.BucketAccessPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       v1alpha1.BucketAccessPolicySpec{ForProvider: v1alpha1.BucketAccessPolicyParameters{Grants: []v1alpha1.BucketAccessGrant{g}}},
+		}
+		if observed != "" {
+			p.Status.AtProvider.Grants = []v1alpha1.BucketAccessGrantObservation{{AccessKeyID: observed}}
+		}
+		return p
+	}
+
+	type want struct {
+		names []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Reader
+		key    *v1alpha1.Key
+		want   want
+	}{
+		"ListError": {
+			reason: "Should return an error if BucketAccessPolicy resources cannot be listed",
+			kube:   &test.MockClient{MockList: test.NewMockListFn(errors.New("boom"))},
+			key:    &v1alpha1.Key{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-key"}},
+			want:   want{err: errors.Wrap(errors.New("boom"), errListPolicies)},
+		},
+		"Key": {
+			reason: "Should return policies with a grant referencing the Key by name, selector or access key ID, or that granted it access",
+			kube: &test.MockClient{MockList: list(
+				bap("default", "ref", v1alpha1.BucketAccessGrant{AccessKeyIDRef: &xpv1.Reference{Name: "my-key"}}, ""),
+				bap("default", "selector", v1alpha1.BucketAccessGrant{AccessKeyIDSelector: &xpv1.Selector{MatchLabels: map[string]string{"app": "web"}}}, ""),
+				bap("default", "id", v1alpha1.BucketAccessGrant{AccessKeyID: &accessKeyID}, ""),
+				bap("default", "observed", v1alpha1.BucketAccessGrant{}, accessKeyID),
+				bap("default", "other", v1alpha1.BucketAccessGrant{AccessKeyID: &otherID}, otherID),
+			)},
+			key: &v1alpha1.Key{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-key", Labels: map[string]string{"app": "web"}},
+				Status:     v1alpha1.KeyStatus{AtProvider: v1alpha1.KeyObservation{AccessKeyID: accessKeyID}},
+			},
+			want: want{names: []string{"id", "observed", "ref", "selector"}},
+		},
+		"ClusterKey": {
+			reason: "Should return the namespaced names of policies granting a ClusterKey access by ID, ignoring references by name",
+			kube: &test.MockClient{MockList: list(
+				bap("team-a", "ref", v1alpha1.BucketAccessGrant{AccessKeyIDRef: &xpv1.Reference{Name: "platform"}}, ""),
+				bap("team-a", "id", v1alpha1.BucketAccessGrant{AccessKeyID: &accessKeyID}, ""),
+				bap("team-b", "observed", v1alpha1.BucketAccessGrant{}, accessKeyID),
+			)},
+			key: (*v1alpha1.Key)(&v1alpha1.ClusterKey{
+				ObjectMeta: metav1.ObjectMeta{Name: "platform"},
+				Status:     v1alpha1.KeyStatus{AtProvider: v1alpha1.KeyObservation{AccessKeyID: accessKeyID}},
+			}),
+			want: want{names: []string{"team-a/id", "team-b/observed"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := PoliciesForKey(context.Background(), tc.kube, tc.key)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPoliciesForKey(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.names, got); diff != "" {
+				t.Errorf("\n%s\nPoliciesForKey(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestKeysForBucket(t *testing.T) {
+	bucketID := "bucket-123"
+	otherID := "bucket-456"
+	alias := "assets"
+
+	list := func(namespaced []v1alpha1.Key, cluster []v1alpha1.ClusterKey) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			switch l := obj.(type) {
+			case *v1alpha1.KeyList:
+				l.Items = namespaced
+			case *v1alpha1.ClusterKeyList:
+				l.Items = cluster
+			}
+			return nil
+		}
+	}
+	key := func(namespace, name string, a v1alpha1.KeyBucketAccess, observed ...v1alpha1.KeyBucketObservation) v1alpha1.Key {
+		return v1alpha1.Key{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       v1alpha1.KeySpec{ForProvider: v1alpha1.KeyParameters{BucketAccess: []v1alpha1.KeyBucketAccess{a}}},
+			Status:     v1alpha1.KeyStatus{AtProvider: v1alpha1.KeyObservation{Buckets: observed}},
+		}
+	}
+	status := v1alpha1.BucketStatus{AtProvider: v1alpha1.BucketObservation{ID: bucketID, GlobalAliases: []string{alias}}}
+
+	type want struct {
+		names []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Reader
+		bucket *v1alpha1.Bucket
+		want   want
+	}{
+		"ListError": {
+			reason: "Should return an error if Key resources cannot be listed",
+			kube:   &test.MockClient{MockList: test.NewMockListFn(errors.New("boom"))},
+			bucket: &v1alpha1.Bucket{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-bucket"}},
+			want:   want{err: errors.Wrap(errors.New("boom"), errListKeyResources)},
+		},
+		"Bucket": {
+			reason: "Should return Keys whose bucketAccess references the Bucket by name, selector, ID or alias, or that were granted access by it",
+			kube: &test.MockClient{MockList: list([]v1alpha1.Key{
+				key("default", "ref", v1alpha1.KeyBucketAccess{BucketIDRef: &xpv1.Reference{Name: "my-bucket"}}),
+				key("default", "selector", v1alpha1.KeyBucketAccess{BucketIDSelector: &xpv1.Selector{MatchLabels: map[string]string{"app": "web"}}}),
+				key("default", "id", v1alpha1.KeyBucketAccess{BucketID: &bucketID}),
+				key("default", "alias", v1alpha1.KeyBucketAccess{BucketAlias: &alias}),
+				key("default", "observed", v1alpha1.KeyBucketAccess{BucketID: &otherID}, v1alpha1.KeyBucketObservation{ID: bucketID, BucketAccessPermissions: &v1alpha1.KeyAccessPermissions{Read: true}}),
+				key("default", "unmanaged", v1alpha1.KeyBucketAccess{BucketID: &otherID}, v1alpha1.KeyBucketObservation{ID: bucketID}),
+			}, nil)},
+			bucket: &v1alpha1.Bucket{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-bucket", Labels: map[string]string{"app": "web"}},
+				Status:     status,
+			},
+			want: want{names: []string{"alias", "id", "observed", "ref", "selector"}},
+		},
+		"ClusterBucket": {
+			reason: "Should return the namespaced names of Keys referencing a ClusterBucket by ID or alias, and ClusterKeys referencing it in any way",
+			kube: &test.MockClient{MockList: list(
+				[]v1alpha1.Key{
+					key("team-a", "ref", v1alpha1.KeyBucketAccess{BucketIDRef: &xpv1.Reference{Name: "platform"}}),
+					key("team-a", "id", v1alpha1.KeyBucketAccess{BucketID: &bucketID}),
+					key("team-b", "alias", v1alpha1.KeyBucketAccess{BucketAlias: &alias}),
+				},
+				[]v1alpha1.ClusterKey{
+					v1alpha1.ClusterKey(key("", "ref", v1alpha1.KeyBucketAccess{BucketIDRef: &xpv1.Reference{Name: "platform"}})),
+					v1alpha1.ClusterKey(key("", "other", v1alpha1.KeyBucketAccess{BucketIDRef: &xpv1.Reference{Name: "other"}})),
+				},
+			)},
+			bucket: (*v1alpha1.Bucket)(&v1alpha1.ClusterBucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "platform"},
+				Status:     status,
+			}),
+			want: want{names: []string{"ref", "team-a/id", "team-b/alias"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := KeysForBucket(context.Background(), tc.kube, tc.bucket)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nKeysForBucket(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.names, got); diff != "" {
+				t.Errorf("\n%s\nKeysForBucket(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBucketDependents(t *testing.T) {
+	bucketID := "bucket-123"
+
+	bucket := &v1alpha1.Bucket{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-bucket"},
+		Status:     v1alpha1.BucketStatus{AtProvider: v1alpha1.BucketObservation{ID: bucketID}},
+	}
+
+	kube := &test.MockClient{MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+		switch l := obj.(type) {
+		case *v1alpha1.KeyAccessList:
+			l.Items = []v1alpha1.KeyAccess{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ka"},
+				Spec:       v1alpha1.KeyAccessSpec{ForProvider: v1alpha1.KeyAccessParameters{BucketID: &bucketID}},
+			}}
+		case *v1alpha1.BucketAccessPolicyList:
+			l.Items = []v1alpha1.BucketAccessPolicy{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bap"},
+				Spec:       v1alpha1.BucketAccessPolicySpec{ForProvider: v1alpha1.BucketAccessPolicyParameters{BucketID: &bucketID}},
+			}}
+		}
+		return nil
+	}}
+
+	got, err := BucketDependents(context.Background(), kube, bucket)
+	if err != nil {
+		t.Fatalf("BucketDependents(...): %v", err)
+	}
+	want := []Dependents{
+		{Kind: v1alpha1.BucketAccessPolicyKind, Names: []string{"bap"}},
+		{Kind: v1alpha1.KeyAccessKind, Names: []string{"ka"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("BucketDependents(...): -want, +got:\n%s\n", diff)
+	}
+
+	msg := `Bucket is referenced by 1 BucketAccessPolicy resource(s): bap; 1 KeyAccess resource(s): ka; delete them first or set the ` +
+		v1alpha1.AnnotationKeyForceDelete + ` annotation to "true"`
+	if diff := cmp.Diff(msg, Message(v1alpha1.BucketKind, got)); diff != "" {
+		t.Errorf("Message(...): -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestEnqueueDeletingBuckets(t *testing.T) {
+	now := metav1.Now()
+	kube := &test.MockClient{MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+		obj.(*v1alpha1.BucketList).Items = []v1alpha1.Bucket{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deleting", DeletionTimestamp: &now}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "live"}},
+		}
+		return nil
+	}}
+
+	got := EnqueueDeletingBuckets(kube)(context.Background(), &v1alpha1.BucketAccessPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bap"}})
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "deleting"}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("EnqueueDeletingBuckets(...): -want, +got:\n%s\n", diff)
+	}
+}
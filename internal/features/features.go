@@ -0,0 +1,9 @@
+// Package features defines the feature flags of the Garage provider that are
+// not defined by crossplane-runtime.
+package features
+
+import "github.com/crossplane/crossplane-runtime/pkg/feature"
+
+// EnableAlphaExternalSecretStores enables alpha support for publishing
+// connection details to External Secret Stores configured by a StoreConfig.
+const EnableAlphaExternalSecretStores feature.Flag = "EnableAlphaExternalSecretStores"
@@ -0,0 +1,66 @@
+// Package grant computes the changes needed to converge the permissions of a
+// key on a bucket
+package grant
+
+import (
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/pkg/garage"
+)
+
+// None are no permissions at all.
+var None = v1alpha1.KeyAccessPermissions{}
+
+// All are all the permissions a key can have on a bucket.
+var All = v1alpha1.KeyAccessPermissions{Read: true, Write: true, Owner: true}
+
+// Diff returns the permissions that must be allowed and denied to turn the
+// current permissions into the wanted ones.
+func Diff(current, want v1alpha1.KeyAccessPermissions) (allow, deny v1alpha1.KeyAccessPermissions) {
+	allow = v1alpha1.KeyAccessPermissions{
+		Read:  want.Read && !current.Read,
+		Write: want.Write && !current.Write,
+		Owner: want.Owner && !current.Owner,
+	}
+	deny = v1alpha1.KeyAccessPermissions{
+		Read:  current.Read && !want.Read,
+		Write: current.Write && !want.Write,
+		Owner: current.Owner && !want.Owner,
+	}
+	return allow, deny
+}
+
+// Union returns the permissions granted by either a or b.
+func Union(a, b v1alpha1.KeyAccessPermissions) v1alpha1.KeyAccessPermissions {
+	return v1alpha1.KeyAccessPermissions{
+		Read:  a.Read || b.Read,
+		Write: a.Write || b.Write,
+		Owner: a.Owner || b.Owner,
+	}
+}
+
+// Intersection returns the permissions granted by both a and b.
+func Intersection(a, b v1alpha1.KeyAccessPermissions) v1alpha1.KeyAccessPermissions {
+	return v1alpha1.KeyAccessPermissions{
+		Read:  a.Read && b.Read,
+		Write: a.Write && b.Write,
+		Owner: a.Owner && b.Owner,
+	}
+}
+
+// Allow returns a request that grants the supplied permissions.
+func Allow(bucketID, accessKeyID string, p v1alpha1.KeyAccessPermissions) *garage.GrantKeyAccessRequest {
+	req := &garage.GrantKeyAccessRequest{BucketID: bucketID, AccessKeyID: accessKeyID}
+	req.Permissions.Read = p.Read
+	req.Permissions.Write = p.Write
+	req.Permissions.Owner = p.Owner
+	return req
+}
+
+// Deny returns a request that revokes the supplied permissions.
+func Deny(bucketID, accessKeyID string, p v1alpha1.KeyAccessPermissions) *garage.RevokeKeyAccessRequest {
+	req := &garage.RevokeKeyAccessRequest{BucketID: bucketID, AccessKeyID: accessKeyID}
+	req.Permissions.Read = p.Read
+	req.Permissions.Write = p.Write
+	req.Permissions.Owner = p.Owner
+	return req
+}
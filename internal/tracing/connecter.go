@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// tracerName identifies the spans recorded for external clients.
+const tracerName = "github.com/kikokikok/provider-garage/internal/tracing"
+
+// An Option configures a traced ExternalConnecter.
+type Option func(*Connecter)
+
+// WithTracerProvider records spans with the supplied provider instead of the
+// global one, e.g. to record them in memory in tests.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Connecter) {
+		c.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// A Connecter records a span for connecting to the external API of a managed
+// resource and for every call of the resulting ExternalClient. Spans are
+// named after the kind of the managed resource and the call, e.g.
+// Bucket.Observe, so that Admin API requests show up as their children.
+type Connecter struct {
+	kind   string
+	c      managed.ExternalConnecter
+	tracer trace.Tracer
+}
+
+// NewConnecter returns a Connecter that traces the supplied connecter of
+// managed resources of the supplied kind.
+func NewConnecter(kind string, c managed.ExternalConnecter, o ...Option) *Connecter {
+	tc := &Connecter{kind: kind, c: c, tracer: otel.Tracer(tracerName)}
+	for _, fn := range o {
+		fn(tc)
+	}
+	return tc
+}
+
+// Connect to the external API of the supplied managed resource.
+func (c *Connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	ctx, span := c.start(ctx, "Connect", mg)
+	ec, err := c.c.Connect(ctx, mg)
+	end(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &external{Connecter: c, client: ec}, nil
+}
+
+func (c *Connecter) start(ctx context.Context, call string, mg resource.Managed) (context.Context, trace.Span) {
+	return c.tracer.Start(ctx, c.kind+"."+call, trace.WithAttributes(
+		attribute.String("crossplane.resource.kind", c.kind),
+		attribute.String("crossplane.resource.name", mg.GetName()),
+		attribute.String("crossplane.resource.namespace", mg.GetNamespace()),
+		attribute.String("crossplane.resource.external_name", meta.GetExternalName(mg)),
+	))
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+type external struct {
+	*Connecter
+	client managed.ExternalClient
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	ctx, span := e.start(ctx, "Observe", mg)
+	o, err := e.client.Observe(ctx, mg)
+	span.SetAttributes(attribute.Bool("crossplane.resource.exists", o.ResourceExists), attribute.Bool("crossplane.resource.up_to_date", o.ResourceUpToDate))
+	end(span, err)
+	return o, err
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	ctx, span := e.start(ctx, "Create", mg)
+	c, err := e.client.Create(ctx, mg)
+	end(span, err)
+	return c, err
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	ctx, span := e.start(ctx, "Update", mg)
+	u, err := e.client.Update(ctx, mg)
+	end(span, err)
+	return u, err
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) (managed.ExternalDelete, error) {
+	ctx, span := e.start(ctx, "Delete", mg)
+	d, err := e.client.Delete(ctx, mg)
+	end(span, err)
+	return d, err
+}
+
+func (e *external) Disconnect(ctx context.Context) error {
+	return e.client.Disconnect(ctx)
+}
@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+)
+
+func TestConnecter(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	var observed trace.SpanContext
+	ec := &managed.ExternalClientFns{
+		ObserveFn: func(ctx context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+			observed = trace.SpanContextFromContext(ctx)
+			return managed.ExternalObservation{ResourceExists: true}, nil
+		},
+		CreateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+			return managed.ExternalCreation{}, errBoom
+		},
+	}
+	c := NewConnecter(v1alpha1.BucketKind, managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		return ec, nil
+	}), WithTracerProvider(tp))
+
+	cr := &v1alpha1.Bucket{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "team-a"}}
+	client, err := c.Connect(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("Connect(...): %v", err)
+	}
+	if _, err := client.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("Observe(...): %v", err)
+	}
+	if _, err := client.Create(context.Background(), cr); !errors.Is(err, errBoom) {
+		t.Fatalf("Create(...): want error %v, got %v", errBoom, err)
+	}
+
+	type span struct {
+		Name   string
+		Status codes.Code
+	}
+	var got []span
+	for _, s := range exporter.GetSpans() {
+		got = append(got, span{Name: s.Name, Status: s.Status.Code})
+	}
+	want := []span{
+		{Name: "Bucket.Connect", Status: codes.Unset},
+		{Name: "Bucket.Observe", Status: codes.Unset},
+		{Name: "Bucket.Create", Status: codes.Error},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("spans: -want, +got:\n%s", diff)
+	}
+
+	if observed.SpanID() != exporter.GetSpans()[1].SpanContext.SpanID() {
+		t.Errorf("Observe(...): should be called with the context of its span")
+	}
+}
@@ -0,0 +1,55 @@
+// Package tracing records OpenTelemetry spans for the external clients of
+// managed resources and configures how spans are exported
+package tracing
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	errExporter = "cannot create OTLP trace exporter"
+	errResource = "cannot detect tracing resource"
+)
+
+// ServiceName is the service spans are reported for unless OTEL_SERVICE_NAME
+// says otherwise.
+const ServiceName = "provider-garage"
+
+// Setup installs a global tracer provider that exports spans with OTLP over
+// HTTP, and propagates W3C trace context and baggage. The exporter is
+// configured by the standard OTEL_EXPORTER_OTLP_* environment variables and
+// sampling by OTEL_TRACES_SAMPLER; a non-empty endpoint URL overrides the
+// environment. The returned function flushes and stops the exporter.
+func Setup(ctx context.Context, endpoint string) (func(context.Context) error, error) {
+	var opts []otlptracehttp.Option
+	if endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	}
+	exp, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, errExporter)
+	}
+
+	// Detectors listed later win, so OTEL_SERVICE_NAME and
+	// OTEL_RESOURCE_ATTRIBUTES override our service name.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv())
+	if err != nil {
+		return nil, errors.Wrap(err, errResource)
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp.Shutdown, nil
+}
@@ -0,0 +1,358 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/apis/v1beta1"
+)
+
+const errNotHub = "object is not a v1beta1 Key or KeyAccess"
+
+var (
+	spec        = field.NewPath("spec")
+	forProvider = spec.Child("forProvider")
+)
+
+// validate returns the field errors of the spec of a Garage resource.
+func validate(obj runtime.Object) field.ErrorList {
+	switch cr := obj.(type) {
+	case *v1alpha1.Bucket:
+		errs := validateBucket(cr.Spec.ForProvider)
+		if len(cr.Spec.AllowedNamespaces) > 0 {
+			errs = append(errs, field.Forbidden(spec.Child("allowedNamespaces"), "only a ClusterBucket can allow other namespaces"))
+		}
+		return errs
+	case *v1alpha1.Key:
+		return validateKey(cr.Spec.ForProvider)
+	case *v1alpha1.KeyAccess:
+		return validateKeyAccess(cr.Spec.ForProvider)
+	case *v1alpha1.BucketAccessPolicy:
+		return validateBucketAccessPolicy(cr.Spec.ForProvider)
+	case *v1alpha1.ClusterBucket:
+		return append(validateClusterScoped(cr.Spec.ResourceSpec), validateBucket(cr.Spec.ForProvider)...)
+	case *v1alpha1.ClusterKey:
+		return append(validateClusterScoped(cr.Spec.ResourceSpec), validate((*v1alpha1.Key)(cr))...)
+	case *v1alpha1.ClusterKeyAccess:
+		return append(validateClusterScoped(cr.Spec.ResourceSpec), validate((*v1alpha1.KeyAccess)(cr))...)
+	case *v1beta1.Bucket:
+		return validateBucketV1beta1(cr.Spec.ForProvider)
+	case *v1beta1.Key, *v1beta1.KeyAccess:
+		// Keys and KeyAccesses convert to v1alpha1 without loss.
+		if o, err := spoke(cr); err == nil {
+			return validate(o)
+		}
+	case *v1.ClusterProviderConfig:
+		return validateProviderConfig(cr.Spec)
+	case *v1.ProviderConfig:
+		return validateProviderConfig(cr.Spec)
+	}
+	return nil
+}
+
+// validateImmutable returns a field error for each immutable field that was
+// changed by an update.
+func validateImmutable(oldObj, newObj runtime.Object) field.ErrorList {
+	switch cr := newObj.(type) {
+	case *v1alpha1.Bucket:
+		if old, ok := oldObj.(*v1alpha1.Bucket); ok {
+			return immutableBucket(old.Spec.ForProvider, cr.Spec.ForProvider)
+		}
+	case *v1alpha1.Key:
+		if old, ok := oldObj.(*v1alpha1.Key); ok {
+			return apivalidation.ValidateImmutableField(cr.Spec.ForProvider.Name, old.Spec.ForProvider.Name, forProvider.Child("name"))
+		}
+	case *v1alpha1.KeyAccess:
+		if old, ok := oldObj.(*v1alpha1.KeyAccess); ok {
+			return immutableKeyAccess(old.Spec.ForProvider, cr.Spec.ForProvider)
+		}
+	case *v1alpha1.BucketAccessPolicy:
+		if old, ok := oldObj.(*v1alpha1.BucketAccessPolicy); ok {
+			return immutableBucketAccessPolicy(old.Spec.ForProvider, cr.Spec.ForProvider)
+		}
+	case *v1alpha1.ClusterBucket:
+		if old, ok := oldObj.(*v1alpha1.ClusterBucket); ok {
+			return immutableBucket(old.Spec.ForProvider, cr.Spec.ForProvider)
+		}
+	case *v1alpha1.ClusterKey:
+		if old, ok := oldObj.(*v1alpha1.ClusterKey); ok {
+			return apivalidation.ValidateImmutableField(cr.Spec.ForProvider.Name, old.Spec.ForProvider.Name, forProvider.Child("name"))
+		}
+	case *v1alpha1.ClusterKeyAccess:
+		if old, ok := oldObj.(*v1alpha1.ClusterKeyAccess); ok {
+			return immutableKeyAccess(old.Spec.ForProvider, cr.Spec.ForProvider)
+		}
+	case *v1beta1.Bucket:
+		if old, ok := oldObj.(*v1beta1.Bucket); ok {
+			return immutableBucketV1beta1(old.Spec.ForProvider, cr.Spec.ForProvider)
+		}
+	case *v1beta1.Key, *v1beta1.KeyAccess:
+		o, oerr := spoke(oldObj)
+		n, nerr := spoke(cr)
+		if oerr == nil && nerr == nil {
+			return validateImmutable(o, n)
+		}
+	}
+	return nil
+}
+
+// spoke converts a v1beta1 Key or KeyAccess to its v1alpha1 version.
+func spoke(obj runtime.Object) (runtime.Object, error) {
+	switch hub := obj.(type) {
+	case *v1beta1.Key:
+		o := &v1alpha1.Key{}
+		return o, o.ConvertFrom(hub)
+	case *v1beta1.KeyAccess:
+		o := &v1alpha1.KeyAccess{}
+		return o, o.ConvertFrom(hub)
+	}
+	return nil, errors.New(errNotHub)
+}
+
+// validateClusterScoped returns the field errors of the spec of a
+// cluster-scoped resource, which has no namespace to read a
+// ProviderConfig from or to write its connection secret to.
+func validateClusterScoped(rs v1alpha1.ResourceSpec) field.ErrorList {
+	var errs field.ErrorList
+	if ref := rs.ProviderConfigReference; ref != nil && ref.Kind == v1.ProviderConfigKind {
+		errs = append(errs, field.Invalid(spec.Child("providerConfigRef", "kind"), ref.Kind, "cluster-scoped resources must use a ClusterProviderConfig"))
+	}
+	if ref := rs.WriteConnectionSecretToReference; ref != nil && ref.Namespace == "" {
+		errs = append(errs, field.Required(spec.Child("writeConnectionSecretToRef", "namespace"), "cluster-scoped resources must name the namespace of their connection secret"))
+	}
+	return errs
+}
+
+// validateProviderConfig rejects a CA bundle that names both a Secret and a
+// ConfigMap, since only one of them would be trusted.
+func validateProviderConfig(s v1.ProviderConfigSpec) field.ErrorList {
+	if s.TLS == nil || s.TLS.CABundle == nil {
+		return nil
+	}
+	b := s.TLS.CABundle
+	return exactlyOne(spec.Child("tls", "caBundle"), option{"secretRef", b.SecretRef != nil}, option{"configMapRef", b.ConfigMapRef != nil})
+}
+
+func validateBucket(p v1alpha1.BucketParameters) field.ErrorList {
+	var errs field.ErrorList
+	if p.GlobalAlias != nil {
+		errs = append(errs, validateBucketName(forProvider.Child("globalAlias"), *p.GlobalAlias)...)
+	}
+	if p.LocalAlias != nil {
+		fp := forProvider.Child("localAlias")
+		if p.LocalAlias.AccessKeyID == "" {
+			errs = append(errs, field.Required(fp.Child("accessKeyId"), ""))
+		}
+		errs = append(errs, validateBucketName(fp.Child("alias"), p.LocalAlias.Alias)...)
+	}
+	if p.Quotas != nil {
+		errs = append(errs, validateQuotas(p.Quotas.MaxSize, p.Quotas.MaxObjects)...)
+	}
+	return errs
+}
+
+func validateBucketV1beta1(p v1beta1.BucketParameters) field.ErrorList {
+	var errs field.ErrorList
+	// The controllers reconcile the v1alpha1 form of a bucket, which has one
+	// global and one local alias; any others would never be applied.
+	if n := len(p.GlobalAliases); n > 1 {
+		errs = append(errs, field.TooMany(forProvider.Child("globalAliases"), n, 1))
+	}
+	if n := len(p.LocalAliases); n > 1 {
+		errs = append(errs, field.TooMany(forProvider.Child("localAliases"), n, 1))
+	}
+	for i, a := range p.GlobalAliases {
+		errs = append(errs, validateBucketName(forProvider.Child("globalAliases").Index(i), a)...)
+	}
+	for i, a := range p.LocalAliases {
+		fp := forProvider.Child("localAliases").Index(i)
+		if a.AccessKeyID == "" {
+			errs = append(errs, field.Required(fp.Child("accessKeyId"), ""))
+		}
+		errs = append(errs, validateBucketName(fp.Child("alias"), a.Alias)...)
+	}
+	if p.Quotas != nil {
+		errs = append(errs, validateQuotas(p.Quotas.MaxSize, p.Quotas.MaxObjects)...)
+	}
+	return errs
+}
+
+func validateQuotas(maxSize, maxObjects *int64) field.ErrorList {
+	fp := forProvider.Child("quotas")
+	errs := validateNonNegative(fp.Child("maxSize"), maxSize)
+	return append(errs, validateNonNegative(fp.Child("maxObjects"), maxObjects)...)
+}
+
+// immutableBucket rejects changes to the aliases of a bucket. An alias that
+// was unset may be set, e.g. when it is late-initialized.
+func immutableBucket(old, p v1alpha1.BucketParameters) field.ErrorList {
+	var errs field.ErrorList
+	if old.GlobalAlias != nil {
+		errs = append(errs, apivalidation.ValidateImmutableField(p.GlobalAlias, old.GlobalAlias, forProvider.Child("globalAlias"))...)
+	}
+	if old.LocalAlias != nil {
+		errs = append(errs, apivalidation.ValidateImmutableField(p.LocalAlias, old.LocalAlias, forProvider.Child("localAlias"))...)
+	}
+	return errs
+}
+
+// immutableBucketV1beta1 rejects changes to the aliases of a bucket once
+// they are set.
+func immutableBucketV1beta1(old, p v1beta1.BucketParameters) field.ErrorList {
+	var errs field.ErrorList
+	if len(old.GlobalAliases) > 0 {
+		errs = append(errs, apivalidation.ValidateImmutableField(p.GlobalAliases, old.GlobalAliases, forProvider.Child("globalAliases"))...)
+	}
+	if len(old.LocalAliases) > 0 {
+		errs = append(errs, apivalidation.ValidateImmutableField(p.LocalAliases, old.LocalAliases, forProvider.Child("localAliases"))...)
+	}
+	return errs
+}
+
+func validateKey(p v1alpha1.KeyParameters) field.ErrorList {
+	var errs field.ErrorList
+	if p.Name == "" {
+		errs = append(errs, field.Required(forProvider.Child("name"), ""))
+	}
+	for i, a := range p.BucketAccess {
+		fp := forProvider.Child("bucketAccess").Index(i)
+		errs = append(errs, exactlyOne(fp, append(bucketOptions(a.BucketID, a.BucketIDRef, a.BucketIDSelector), option{"bucketAlias", isSet(a.BucketAlias)})...)...)
+		if isSet(a.BucketAlias) {
+			errs = append(errs, validateBucketName(fp.Child("bucketAlias"), *a.BucketAlias)...)
+		}
+	}
+	return errs
+}
+
+func validateKeyAccess(p v1alpha1.KeyAccessParameters) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, exactlyOne(forProvider, append(bucketOptions(p.BucketID, p.BucketIDRef, p.BucketIDSelector), option{"clusterBucketIdRef", p.ClusterBucketIDRef != nil})...)...)
+	errs = append(errs, exactlyOne(forProvider, append(keyOptions(p.AccessKeyID, p.AccessKeyIDRef, p.AccessKeyIDSelector), option{"clusterAccessKeyIdRef", p.ClusterAccessKeyIDRef != nil})...)...)
+	return errs
+}
+
+// immutableKeyAccess rejects changes to the bucket and key of a KeyAccess.
+// The controller only knows the grant it made from its status, so a new
+// target would leave the old grant behind.
+func immutableKeyAccess(old, p v1alpha1.KeyAccessParameters) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, apivalidation.ValidateImmutableField(p.BucketID, old.BucketID, forProvider.Child("bucketId"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(p.BucketIDRef, old.BucketIDRef, forProvider.Child("bucketIdRef"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(p.BucketIDSelector, old.BucketIDSelector, forProvider.Child("bucketIdSelector"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(p.ClusterBucketIDRef, old.ClusterBucketIDRef, forProvider.Child("clusterBucketIdRef"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(p.AccessKeyID, old.AccessKeyID, forProvider.Child("accessKeyId"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(p.AccessKeyIDRef, old.AccessKeyIDRef, forProvider.Child("accessKeyIdRef"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(p.AccessKeyIDSelector, old.AccessKeyIDSelector, forProvider.Child("accessKeyIdSelector"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(p.ClusterAccessKeyIDRef, old.ClusterAccessKeyIDRef, forProvider.Child("clusterAccessKeyIdRef"))...)
+	return errs
+}
+
+func validateBucketAccessPolicy(p v1alpha1.BucketAccessPolicyParameters) field.ErrorList {
+	errs := exactlyOne(forProvider, bucketOptions(p.BucketID, p.BucketIDRef, p.BucketIDSelector)...)
+	for i, g := range p.Grants {
+		opts := append(keyOptions(g.AccessKeyID, g.AccessKeyIDRef, g.AccessKeyIDSelector), option{"keyName", isSet(g.KeyName)})
+		errs = append(errs, exactlyOne(forProvider.Child("grants").Index(i), opts...)...)
+	}
+	return errs
+}
+
+// immutableBucketAccessPolicy rejects changes to the bucket of a policy,
+// which would leave its grants on the old bucket behind.
+func immutableBucketAccessPolicy(old, p v1alpha1.BucketAccessPolicyParameters) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, apivalidation.ValidateImmutableField(p.BucketID, old.BucketID, forProvider.Child("bucketId"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(p.BucketIDRef, old.BucketIDRef, forProvider.Child("bucketIdRef"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(p.BucketIDSelector, old.BucketIDSelector, forProvider.Child("bucketIdSelector"))...)
+	return errs
+}
+
+// validateBucketName validates a bucket alias against the naming rules
+// Garage enforces, which follow those of S3.
+func validateBucketName(fp *field.Path, name string) field.ErrorList {
+	invalid := func(msg string) field.ErrorList {
+		return field.ErrorList{field.Invalid(fp, name, msg)}
+	}
+	switch {
+	case len(name) < 3 || len(name) > 63:
+		return invalid("must be between 3 and 63 characters long")
+	case strings.IndexFunc(name, invalidBucketNameRune) >= 0:
+		return invalid("must consist of lower case letters, numbers, '.' and '-'")
+	case strings.ContainsAny(name[:1]+name[len(name)-1:], ".-"):
+		return invalid("must start and end with a letter or number")
+	case net.ParseIP(name) != nil:
+		return invalid("must not be formatted as an IP address")
+	case strings.HasPrefix(name, "xn--"):
+		return invalid("must not start with 'xn--'")
+	case strings.HasSuffix(name, "-s3alias"):
+		return invalid("must not end with '-s3alias'")
+	}
+	return nil
+}
+
+func invalidBucketNameRune(r rune) bool {
+	return (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '.' && r != '-'
+}
+
+func validateNonNegative(fp *field.Path, v *int64) field.ErrorList {
+	if v != nil && *v < 0 {
+		return field.ErrorList{field.Invalid(fp, *v, "must be greater than or equal to 0")}
+	}
+	return nil
+}
+
+// An option is one of several mutually exclusive fields.
+type option struct {
+	name string
+	set  bool
+}
+
+// exactlyOne returns a field error unless exactly one of the supplied options
+// is set.
+func exactlyOne(fp *field.Path, opts ...option) field.ErrorList {
+	names := make([]string, len(opts))
+	var set []string
+	for i, o := range opts {
+		names[i] = o.name
+		if o.set {
+			set = append(set, o.name)
+		}
+	}
+	switch len(set) {
+	case 1:
+		return nil
+	case 0:
+		return field.ErrorList{field.Required(fp, fmt.Sprintf("one of %s is required", either(names)))}
+	default:
+		return field.ErrorList{field.Forbidden(fp, fmt.Sprintf("only one of %s may be set", either(names)))}
+	}
+}
+
+func bucketOptions(id *string, ref *xpv1.Reference, sel *xpv1.Selector) []option {
+	return []option{{"bucketId", isSet(id)}, {"bucketIdRef", ref != nil}, {"bucketIdSelector", sel != nil}}
+}
+
+func keyOptions(id *string, ref *xpv1.Reference, sel *xpv1.Selector) []option {
+	return []option{{"accessKeyId", isSet(id)}, {"accessKeyIdRef", ref != nil}, {"accessKeyIdSelector", sel != nil}}
+}
+
+// either returns names as "a, b or c".
+func either(names []string) string {
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+func isSet(s *string) bool {
+	return s != nil && *s != ""
+}
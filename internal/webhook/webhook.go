@@ -0,0 +1,128 @@
+// Package webhook validates Garage resources on admission, so that invalid
+// specs are rejected before they are reconciled.
+package webhook
+
+//go:generate go run sigs.k8s.io/controller-tools/cmd/controller-gen webhook paths=./... output:webhook:artifacts:config=../../package/webhookconfigurations
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/apis/v1beta1"
+)
+
+const errSetup = "cannot setup webhook"
+
+// +kubebuilder:webhook:path=/validate-garage-crossplane-io-v1alpha1-bucket,mutating=false,failurePolicy=fail,sideEffects=None,groups=garage.crossplane.io,resources=buckets,verbs=create;update,versions=v1alpha1,name=buckets.garage.crossplane.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-garage-crossplane-io-v1alpha1-key,mutating=false,failurePolicy=fail,sideEffects=None,groups=garage.crossplane.io,resources=keys,verbs=create;update,versions=v1alpha1,name=keys.garage.crossplane.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-garage-crossplane-io-v1alpha1-keyaccess,mutating=false,failurePolicy=fail,sideEffects=None,groups=garage.crossplane.io,resources=keyaccesses,verbs=create;update,versions=v1alpha1,name=keyaccesses.garage.crossplane.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-garage-crossplane-io-v1beta1-bucket,mutating=false,failurePolicy=fail,sideEffects=None,groups=garage.crossplane.io,resources=buckets,verbs=create;update,versions=v1beta1,name=v1beta1.buckets.garage.crossplane.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-garage-crossplane-io-v1beta1-key,mutating=false,failurePolicy=fail,sideEffects=None,groups=garage.crossplane.io,resources=keys,verbs=create;update,versions=v1beta1,name=v1beta1.keys.garage.crossplane.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-garage-crossplane-io-v1beta1-keyaccess,mutating=false,failurePolicy=fail,sideEffects=None,groups=garage.crossplane.io,resources=keyaccesses,verbs=create;update,versions=v1beta1,name=v1beta1.keyaccesses.garage.crossplane.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-garage-crossplane-io-v1alpha1-clusterbucket,mutating=false,failurePolicy=fail,sideEffects=None,groups=garage.crossplane.io,resources=clusterbuckets,verbs=create;update,versions=v1alpha1,name=clusterbuckets.garage.crossplane.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-garage-crossplane-io-v1alpha1-clusterkey,mutating=false,failurePolicy=fail,sideEffects=None,groups=garage.crossplane.io,resources=clusterkeys,verbs=create;update,versions=v1alpha1,name=clusterkeys.garage.crossplane.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-garage-crossplane-io-v1alpha1-clusterkeyaccess,mutating=false,failurePolicy=fail,sideEffects=None,groups=garage.crossplane.io,resources=clusterkeyaccesses,verbs=create;update,versions=v1alpha1,name=clusterkeyaccesses.garage.crossplane.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-garage-crossplane-io-v1alpha1-bucketaccesspolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=garage.crossplane.io,resources=bucketaccesspolicies,verbs=create;update,versions=v1alpha1,name=bucketaccesspolicies.garage.crossplane.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-garage-crossplane-io-v1-providerconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=garage.crossplane.io,resources=providerconfigs,verbs=create;update,versions=v1,name=providerconfigs.garage.crossplane.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-garage-crossplane-io-v1-clusterproviderconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=garage.crossplane.io,resources=clusterproviderconfigs,verbs=create;update,versions=v1,name=clusterproviderconfigs.garage.crossplane.io,admissionReviewVersions=v1
+
+// Setup registers a validating webhook for each version of each Garage managed
+// resource, and for provider configs, with the webhook server of the supplied
+// manager, and the conversion webhook of the resources that have several
+// versions.
+func Setup(mgr ctrl.Manager) error {
+	objs := []client.Object{
+		&v1alpha1.Bucket{}, &v1alpha1.Key{}, &v1alpha1.KeyAccess{}, &v1alpha1.BucketAccessPolicy{},
+		&v1alpha1.ClusterBucket{}, &v1alpha1.ClusterKey{}, &v1alpha1.ClusterKeyAccess{},
+		&v1beta1.Bucket{}, &v1beta1.Key{}, &v1beta1.KeyAccess{},
+		&v1.ProviderConfig{}, &v1.ClusterProviderConfig{},
+	}
+	for _, o := range objs {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(o).WithValidator(&Validator{}).Complete(); err != nil {
+			return errors.Wrap(err, errSetup)
+		}
+	}
+	return nil
+}
+
+// A Validator validates Garage resources. It rejects invalid specs on create
+// and update, and changes to immutable fields on update.
+type Validator struct{}
+
+// ValidateCreate validates a resource that is being created.
+func (v *Validator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, invalid(obj, validate(obj))
+}
+
+// ValidateUpdate validates a resource that is being updated. Resources created
+// before they were validated may be invalid, so the spec is only validated
+// when it changes and the resource is not being deleted; otherwise finalizers,
+// labels and annotations could not be removed from them.
+func (v *Validator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	var errs field.ErrorList
+	if !deleting(newObj) && specChanged(oldObj, newObj) {
+		errs = validate(newObj)
+	}
+	errs = append(errs, validateImmutable(oldObj, newObj)...)
+	return nil, invalid(newObj, errs)
+}
+
+// ValidateDelete allows any resource to be deleted.
+func (v *Validator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// A kinded resource knows its GroupVersionKind even when its type metadata is
+// not set.
+type kinded interface {
+	GroupVersionKind() schema.GroupVersionKind
+}
+
+// invalid returns an Invalid API error for the supplied field errors, or nil
+// if there are none.
+func invalid(obj runtime.Object, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	name := ""
+	if m, err := meta.Accessor(obj); err == nil {
+		name = m.GetName()
+	}
+	gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+	if k, ok := obj.(kinded); ok {
+		gk = k.GroupVersionKind().GroupKind()
+	}
+	return kerrors.NewInvalid(gk, name, errs)
+}
+
+// deleting returns true if the supplied resource is being deleted.
+func deleting(obj runtime.Object) bool {
+	m, err := meta.Accessor(obj)
+	return err == nil && m.GetDeletionTimestamp() != nil
+}
+
+// specChanged returns true if the spec of the supplied resources differ, or if
+// they cannot be compared.
+func specChanged(oldObj, newObj runtime.Object) bool {
+	o, err := runtime.DefaultUnstructuredConverter.ToUnstructured(oldObj)
+	if err != nil {
+		return true
+	}
+	n, err := runtime.DefaultUnstructuredConverter.ToUnstructured(newObj)
+	if err != nil {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(o["spec"], n["spec"])
+}
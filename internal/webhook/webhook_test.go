@@ -0,0 +1,374 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/kikokikok/provider-garage/apis/v1"
+	"github.com/kikokikok/provider-garage/apis/v1alpha1"
+	"github.com/kikokikok/provider-garage/apis/v1beta1"
+)
+
+func bucket(p v1alpha1.BucketParameters) *v1alpha1.Bucket {
+	return &v1alpha1.Bucket{
+		ObjectMeta: metav1.ObjectMeta{Name: "b"},
+		Spec:       v1alpha1.BucketSpec{ForProvider: p},
+	}
+}
+
+func key(p v1alpha1.KeyParameters) *v1alpha1.Key {
+	return &v1alpha1.Key{
+		ObjectMeta: metav1.ObjectMeta{Name: "k"},
+		Spec:       v1alpha1.KeySpec{ForProvider: p},
+	}
+}
+
+func keyAccess(p v1alpha1.KeyAccessParameters) *v1alpha1.KeyAccess {
+	return &v1alpha1.KeyAccess{
+		ObjectMeta: metav1.ObjectMeta{Name: "ka"},
+		Spec:       v1alpha1.KeyAccessSpec{ForProvider: p},
+	}
+}
+
+func policy(p v1alpha1.BucketAccessPolicyParameters) *v1alpha1.BucketAccessPolicy {
+	return &v1alpha1.BucketAccessPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "bap"},
+		Spec:       v1alpha1.BucketAccessPolicySpec{ForProvider: p},
+	}
+}
+
+func invalidErr(kind, name string, errs ...*field.Error) error {
+	return kerrors.NewInvalid(schema.GroupKind{Group: v1alpha1.Group, Kind: kind}, name, errs)
+}
+
+func TestValidateCreate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		obj    runtime.Object
+		want   error
+	}{
+		"ValidBucket": {
+			reason: "Should accept a bucket with valid aliases and quotas",
+			obj: bucket(v1alpha1.BucketParameters{
+				GlobalAlias: ptr.To("my-bucket.v1"),
+				LocalAlias:  &v1alpha1.LocalAlias{AccessKeyID: "GK123", Alias: "local"},
+				Quotas:      &v1alpha1.BucketQuotas{MaxSize: ptr.To[int64](0), MaxObjects: ptr.To[int64](100)},
+			}),
+		},
+		"BucketAliasTooShort": {
+			reason: "Should reject a global alias shorter than 3 characters",
+			obj:    bucket(v1alpha1.BucketParameters{GlobalAlias: ptr.To("ab")}),
+			want: invalidErr(v1alpha1.BucketKind, "b",
+				field.Invalid(field.NewPath("spec", "forProvider", "globalAlias"), "ab", "must be between 3 and 63 characters long")),
+		},
+		"BucketAliasUpperCase": {
+			reason: "Should reject a global alias with upper case letters",
+			obj:    bucket(v1alpha1.BucketParameters{GlobalAlias: ptr.To("My_Bucket")}),
+			want: invalidErr(v1alpha1.BucketKind, "b",
+				field.Invalid(field.NewPath("spec", "forProvider", "globalAlias"), "My_Bucket", "must consist of lower case letters, numbers, '.' and '-'")),
+		},
+		"BucketAliasIP": {
+			reason: "Should reject a global alias formatted as an IP address",
+			obj:    bucket(v1alpha1.BucketParameters{GlobalAlias: ptr.To("192.168.1.1")}),
+			want: invalidErr(v1alpha1.BucketKind, "b",
+				field.Invalid(field.NewPath("spec", "forProvider", "globalAlias"), "192.168.1.1", "must not be formatted as an IP address")),
+		},
+		"BucketLocalAlias": {
+			reason: "Should reject a local alias without a key that starts with a dash",
+			obj:    bucket(v1alpha1.BucketParameters{LocalAlias: &v1alpha1.LocalAlias{Alias: "-local"}}),
+			want: invalidErr(v1alpha1.BucketKind, "b",
+				field.Required(field.NewPath("spec", "forProvider", "localAlias", "accessKeyId"), ""),
+				field.Invalid(field.NewPath("spec", "forProvider", "localAlias", "alias"), "-local", "must start and end with a letter or number")),
+		},
+		"NegativeQuotas": {
+			reason: "Should reject negative quotas",
+			obj:    bucket(v1alpha1.BucketParameters{Quotas: &v1alpha1.BucketQuotas{MaxSize: ptr.To[int64](-1), MaxObjects: ptr.To[int64](-2)}}),
+			want: invalidErr(v1alpha1.BucketKind, "b",
+				field.Invalid(field.NewPath("spec", "forProvider", "quotas", "maxSize"), int64(-1), "must be greater than or equal to 0"),
+				field.Invalid(field.NewPath("spec", "forProvider", "quotas", "maxObjects"), int64(-2), "must be greater than or equal to 0")),
+		},
+		"ValidKey": {
+			reason: "Should accept a key with one bucket per bucketAccess entry",
+			obj: key(v1alpha1.KeyParameters{Name: "my-key", BucketAccess: []v1alpha1.KeyBucketAccess{
+				{BucketIDRef: &xpv1.Reference{Name: "b"}},
+				{BucketAlias: ptr.To("shared-assets")},
+			}}),
+		},
+		"KeyBucketAccess": {
+			reason: "Should reject bucketAccess entries with no or several buckets",
+			obj: key(v1alpha1.KeyParameters{Name: "my-key", BucketAccess: []v1alpha1.KeyBucketAccess{
+				{},
+				{BucketIDRef: &xpv1.Reference{Name: "b"}, BucketAlias: ptr.To("shared-assets")},
+			}}),
+			want: invalidErr(v1alpha1.KeyKind, "k",
+				field.Required(field.NewPath("spec", "forProvider", "bucketAccess").Index(0), "one of bucketId, bucketIdRef, bucketIdSelector or bucketAlias is required"),
+				field.Forbidden(field.NewPath("spec", "forProvider", "bucketAccess").Index(1), "only one of bucketId, bucketIdRef, bucketIdSelector or bucketAlias may be set")),
+		},
+		"KeyNoName": {
+			reason: "Should reject a key without a name",
+			obj:    key(v1alpha1.KeyParameters{}),
+			want:   invalidErr(v1alpha1.KeyKind, "k", field.Required(field.NewPath("spec", "forProvider", "name"), "")),
+		},
+		"ValidKeyAccess": {
+			reason: "Should accept a KeyAccess with one bucket and one key",
+			obj: keyAccess(v1alpha1.KeyAccessParameters{
+				BucketIDSelector: &xpv1.Selector{MatchLabels: map[string]string{"app": "a"}},
+				AccessKeyID:      ptr.To("GK123"),
+			}),
+		},
+		"KeyAccessNoBucket": {
+			reason: "Should reject a KeyAccess with neither a bucketId nor a reference",
+			obj:    keyAccess(v1alpha1.KeyAccessParameters{AccessKeyIDRef: &xpv1.Reference{Name: "k"}}),
+			want: invalidErr(v1alpha1.KeyAccessKind, "ka",
+				field.Required(field.NewPath("spec", "forProvider"), "one of bucketId, bucketIdRef, bucketIdSelector or clusterBucketIdRef is required")),
+		},
+		"KeyAccessSeveralKeys": {
+			reason: "Should reject a KeyAccess with both an accessKeyId and a reference",
+			obj: keyAccess(v1alpha1.KeyAccessParameters{
+				BucketID:       ptr.To("abc"),
+				AccessKeyID:    ptr.To("GK123"),
+				AccessKeyIDRef: &xpv1.Reference{Name: "k"},
+			}),
+			want: invalidErr(v1alpha1.KeyAccessKind, "ka",
+				field.Forbidden(field.NewPath("spec", "forProvider"), "only one of accessKeyId, accessKeyIdRef, accessKeyIdSelector or clusterAccessKeyIdRef may be set")),
+		},
+		"V1beta1BucketAliases": {
+			reason: "Should validate every alias of a v1beta1 bucket",
+			obj: &v1beta1.Bucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "b"},
+				Spec: v1beta1.BucketSpec{ForProvider: v1beta1.BucketParameters{
+					GlobalAliases: []string{"xn--bucket"},
+					LocalAliases:  []v1beta1.LocalAlias{{AccessKeyID: "GK123", Alias: "x"}},
+				}},
+			},
+			want: invalidErr(v1alpha1.BucketKind, "b",
+				field.Invalid(field.NewPath("spec", "forProvider", "globalAliases").Index(0), "xn--bucket", "must not start with 'xn--'"),
+				field.Invalid(field.NewPath("spec", "forProvider", "localAliases").Index(0).Child("alias"), "x", "must be between 3 and 63 characters long")),
+		},
+		"V1beta1BucketTooManyAliases": {
+			reason: "Should reject more than one global or local alias, which the controllers cannot apply yet",
+			obj: &v1beta1.Bucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "b"},
+				Spec: v1beta1.BucketSpec{ForProvider: v1beta1.BucketParameters{
+					GlobalAliases: []string{"my-bucket", "my-bucket-legacy"},
+					LocalAliases:  []v1beta1.LocalAlias{{AccessKeyID: "GK123", Alias: "data"}, {AccessKeyID: "GK456", Alias: "data"}},
+				}},
+			},
+			want: invalidErr(v1alpha1.BucketKind, "b",
+				field.TooMany(field.NewPath("spec", "forProvider", "globalAliases"), 2, 1),
+				field.TooMany(field.NewPath("spec", "forProvider", "localAliases"), 2, 1)),
+		},
+		"V1beta1KeyAccessNoKey": {
+			reason: "Should validate a v1beta1 KeyAccess like a v1alpha1 one",
+			obj: &v1beta1.KeyAccess{
+				ObjectMeta: metav1.ObjectMeta{Name: "ka"},
+				Spec:       v1beta1.KeyAccessSpec{ForProvider: v1beta1.KeyAccessParameters{BucketID: ptr.To("abc")}},
+			},
+			want: invalidErr(v1alpha1.KeyAccessKind, "ka",
+				field.Required(field.NewPath("spec", "forProvider"), "one of accessKeyId, accessKeyIdRef, accessKeyIdSelector or clusterAccessKeyIdRef is required")),
+		},
+		"ClusterKeyAccessCrossScope": {
+			reason: "Should accept a KeyAccess that references a ClusterBucket and a ClusterKey",
+			obj: keyAccess(v1alpha1.KeyAccessParameters{
+				ClusterBucketIDRef:    &xpv1.Reference{Name: "platform"},
+				ClusterAccessKeyIDRef: &xpv1.Reference{Name: "loki"},
+			}),
+		},
+		"ClusterBucketNamespaced": {
+			reason: "Should reject a ClusterBucket that uses a ProviderConfig or writes its connection secret to no namespace",
+			obj: &v1alpha1.ClusterBucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "b"},
+				Spec: v1alpha1.BucketSpec{
+					ResourceSpec: v1alpha1.ResourceSpec{
+						ProviderConfigReference:          &v1.ProviderConfigReference{Name: "team", Kind: v1.ProviderConfigKind},
+						WriteConnectionSecretToReference: &xpv1.SecretReference{Name: "b"},
+					},
+					ForProvider: v1alpha1.BucketParameters{GlobalAlias: ptr.To("Platform")},
+				},
+			},
+			want: invalidErr(v1alpha1.ClusterBucketKind, "b",
+				field.Invalid(field.NewPath("spec", "providerConfigRef", "kind"), v1.ProviderConfigKind, "cluster-scoped resources must use a ClusterProviderConfig"),
+				field.Required(field.NewPath("spec", "writeConnectionSecretToRef", "namespace"), "cluster-scoped resources must name the namespace of their connection secret"),
+				field.Invalid(field.NewPath("spec", "forProvider", "globalAlias"), "Platform", "must consist of lower case letters, numbers, '.' and '-'")),
+		},
+		"BucketAllowedNamespaces": {
+			reason: "Should reject allowedNamespaces on a namespaced Bucket",
+			obj: &v1alpha1.Bucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "b"},
+				Spec:       v1alpha1.BucketSpec{AllowedNamespaces: []string{"team-a"}},
+			},
+			want: invalidErr(v1alpha1.BucketKind, "b",
+				field.Forbidden(field.NewPath("spec", "allowedNamespaces"), "only a ClusterBucket can allow other namespaces")),
+		},
+		"BucketAccessPolicyGrants": {
+			reason: "Should reject grants with no or several keys",
+			obj: policy(v1alpha1.BucketAccessPolicyParameters{
+				BucketIDRef: &xpv1.Reference{Name: "b"},
+				Grants: []v1alpha1.BucketAccessGrant{
+					{KeyName: ptr.To("my-key")},
+					{},
+					{AccessKeyID: ptr.To("GK123"), KeyName: ptr.To("my-key")},
+				},
+			}),
+			want: invalidErr(v1alpha1.BucketAccessPolicyKind, "bap",
+				field.Required(field.NewPath("spec", "forProvider", "grants").Index(1), "one of accessKeyId, accessKeyIdRef, accessKeyIdSelector or keyName is required"),
+				field.Forbidden(field.NewPath("spec", "forProvider", "grants").Index(2), "only one of accessKeyId, accessKeyIdRef, accessKeyIdSelector or keyName may be set")),
+		},
+		"ClusterProviderConfigCABundle": {
+			reason: "Should reject a CA bundle that names both a Secret and a ConfigMap",
+			obj: &v1.ClusterProviderConfig{
+				TypeMeta:   metav1.TypeMeta{APIVersion: v1.GroupVersion.String(), Kind: v1.ClusterProviderConfigKind},
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec: v1.ProviderConfigSpec{TLS: &v1.TLSConfig{CABundle: &v1.CABundleSource{
+					SecretRef:    &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: "ns", Name: "ca"}, Key: "ca.crt"},
+					ConfigMapRef: &v1.ConfigMapKeySelector{Namespace: "ns", Name: "ca", Key: "ca.crt"},
+				}}},
+			},
+			want: invalidErr(v1.ClusterProviderConfigKind, "default",
+				field.Forbidden(field.NewPath("spec", "tls", "caBundle"), "only one of secretRef or configMapRef may be set")),
+		},
+		"ProviderConfigCABundle": {
+			reason: "Should accept a CA bundle read from a ConfigMap",
+			obj: &v1.ProviderConfig{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "team"},
+				Spec: v1.ProviderConfigSpec{TLS: &v1.TLSConfig{CABundle: &v1.CABundleSource{
+					ConfigMapRef: &v1.ConfigMapKeySelector{Namespace: "team-a", Name: "ca", Key: "ca.crt"},
+				}}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := (&Validator{}).ValidateCreate(context.Background(), tc.obj)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidateCreate(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		old    runtime.Object
+		obj    runtime.Object
+		want   error
+	}{
+		"BucketAliasLateInitialized": {
+			reason: "Should allow an unset global alias to be set",
+			old:    bucket(v1alpha1.BucketParameters{}),
+			obj:    bucket(v1alpha1.BucketParameters{GlobalAlias: ptr.To("my-bucket")}),
+		},
+		"BucketAliasChanged": {
+			reason: "Should reject a change to the global alias",
+			old:    bucket(v1alpha1.BucketParameters{GlobalAlias: ptr.To("my-bucket")}),
+			obj:    bucket(v1alpha1.BucketParameters{GlobalAlias: ptr.To("other-bucket")}),
+			want: invalidErr(v1alpha1.BucketKind, "b",
+				field.Invalid(field.NewPath("spec", "forProvider", "globalAlias"), ptr.To("other-bucket"), "field is immutable")),
+		},
+		"BucketQuotasChanged": {
+			reason: "Should allow quotas to be changed",
+			old:    bucket(v1alpha1.BucketParameters{Quotas: &v1alpha1.BucketQuotas{MaxObjects: ptr.To[int64](1)}}),
+			obj:    bucket(v1alpha1.BucketParameters{Quotas: &v1alpha1.BucketQuotas{MaxObjects: ptr.To[int64](2)}}),
+		},
+		"KeyNameChanged": {
+			reason: "Should reject a change to the name of a key",
+			old:    key(v1alpha1.KeyParameters{Name: "my-key"}),
+			obj:    key(v1alpha1.KeyParameters{Name: "new-key"}),
+			want: invalidErr(v1alpha1.KeyKind, "k",
+				field.Invalid(field.NewPath("spec", "forProvider", "name"), "new-key", "field is immutable")),
+		},
+		"V1beta1BucketAliasChanged": {
+			reason: "Should reject a change to the global aliases of a v1beta1 bucket once set",
+			old: &v1beta1.Bucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "b"},
+				Spec:       v1beta1.BucketSpec{ForProvider: v1beta1.BucketParameters{GlobalAliases: []string{"my-bucket"}}},
+			},
+			obj: &v1beta1.Bucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "b"},
+				Spec:       v1beta1.BucketSpec{ForProvider: v1beta1.BucketParameters{GlobalAliases: []string{"other-bucket"}}},
+			},
+			want: invalidErr(v1alpha1.BucketKind, "b",
+				field.Invalid(field.NewPath("spec", "forProvider", "globalAliases"), []string{"other-bucket"}, "field is immutable")),
+		},
+		"V1beta1KeyNameChanged": {
+			reason: "Should reject a change to the name of a v1beta1 key",
+			old:    &v1beta1.Key{ObjectMeta: metav1.ObjectMeta{Name: "k"}, Spec: v1beta1.KeySpec{ForProvider: v1beta1.KeyParameters{Name: "my-key"}}},
+			obj:    &v1beta1.Key{ObjectMeta: metav1.ObjectMeta{Name: "k"}, Spec: v1beta1.KeySpec{ForProvider: v1beta1.KeyParameters{Name: "new-key"}}},
+			want: invalidErr(v1alpha1.KeyKind, "k",
+				field.Invalid(field.NewPath("spec", "forProvider", "name"), "new-key", "field is immutable")),
+		},
+		"KeyPermissionsChanged": {
+			reason: "Should allow the permissions of a key to be changed",
+			old:    key(v1alpha1.KeyParameters{Name: "my-key"}),
+			obj:    key(v1alpha1.KeyParameters{Name: "my-key", Permissions: &v1alpha1.KeyPermissions{CreateBucket: true}}),
+		},
+		"KeyAccessBucketChanged": {
+			reason: "Should reject a change to the bucket of a KeyAccess",
+			old:    keyAccess(v1alpha1.KeyAccessParameters{BucketIDRef: &xpv1.Reference{Name: "a"}, AccessKeyID: ptr.To("GK123")}),
+			obj:    keyAccess(v1alpha1.KeyAccessParameters{BucketIDRef: &xpv1.Reference{Name: "b"}, AccessKeyID: ptr.To("GK123")}),
+			want: invalidErr(v1alpha1.KeyAccessKind, "ka",
+				field.Invalid(field.NewPath("spec", "forProvider", "bucketIdRef"), &xpv1.Reference{Name: "b"}, "field is immutable")),
+		},
+		"KeyAccessPermissionsChanged": {
+			reason: "Should allow the permissions of a KeyAccess to be changed",
+			old:    keyAccess(v1alpha1.KeyAccessParameters{BucketID: ptr.To("abc"), AccessKeyID: ptr.To("GK123")}),
+			obj: keyAccess(v1alpha1.KeyAccessParameters{BucketID: ptr.To("abc"), AccessKeyID: ptr.To("GK123"),
+				Permissions: v1alpha1.KeyAccessPermissions{Read: true}}),
+		},
+		"InvalidBucketFinalizerRemoved": {
+			reason: "Should allow the finalizer of an invalid bucket to be removed",
+			old: &v1alpha1.Bucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "b", Finalizers: []string{"finalizer.managedresource.crossplane.io"}},
+				Spec:       v1alpha1.BucketSpec{ForProvider: v1alpha1.BucketParameters{GlobalAlias: ptr.To("Invalid_Bucket")}},
+			},
+			obj: bucket(v1alpha1.BucketParameters{GlobalAlias: ptr.To("Invalid_Bucket")}),
+		},
+		"InvalidBucketDeleted": {
+			reason: "Should allow a change to the spec of an invalid bucket that is being deleted",
+			old:    bucket(v1alpha1.BucketParameters{GlobalAlias: ptr.To("Invalid_Bucket")}),
+			obj: &v1alpha1.Bucket{
+				ObjectMeta: metav1.ObjectMeta{Name: "b", DeletionTimestamp: &metav1.Time{}},
+				Spec: v1alpha1.BucketSpec{
+					ResourceSpec: v1alpha1.ResourceSpec{DeletionPolicy: xpv1.DeletionOrphan},
+					ForProvider:  v1alpha1.BucketParameters{GlobalAlias: ptr.To("Invalid_Bucket")},
+				},
+			},
+		},
+		"InvalidBucketChanged": {
+			reason: "Should reject a change to the spec of an invalid bucket",
+			old:    bucket(v1alpha1.BucketParameters{GlobalAlias: ptr.To("Invalid_Bucket")}),
+			obj:    bucket(v1alpha1.BucketParameters{GlobalAlias: ptr.To("Invalid_Bucket"), Quotas: &v1alpha1.BucketQuotas{MaxObjects: ptr.To[int64](1)}}),
+			want: invalidErr(v1alpha1.BucketKind, "b",
+				field.Invalid(field.NewPath("spec", "forProvider", "globalAlias"), "Invalid_Bucket", "must consist of lower case letters, numbers, '.' and '-'")),
+		},
+		"BucketAccessPolicyBucketChanged": {
+			reason: "Should reject a change to the bucket of a BucketAccessPolicy",
+			old:    policy(v1alpha1.BucketAccessPolicyParameters{BucketID: ptr.To("abc")}),
+			obj:    policy(v1alpha1.BucketAccessPolicyParameters{BucketID: ptr.To("def")}),
+			want: invalidErr(v1alpha1.BucketAccessPolicyKind, "bap",
+				field.Invalid(field.NewPath("spec", "forProvider", "bucketId"), ptr.To("def"), "field is immutable")),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := (&Validator{}).ValidateUpdate(context.Background(), tc.old, tc.obj)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidateUpdate(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
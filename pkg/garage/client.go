@@ -5,69 +5,444 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// DefaultTimeout is the request timeout used when none is configured
+const DefaultTimeout = 30 * time.Second
+
+// DefaultUserAgent is the User-Agent sent when none is configured
+const DefaultUserAgent = "provider-garage"
+
+// tracerName identifies the spans recorded by the client
+const tracerName = "github.com/kikokikok/provider-garage/pkg/garage"
+
+// unixHost is the placeholder host used in request URLs for Unix socket
+// endpoints; the transport dials the socket regardless of it
+const unixHost = "localhost"
+
 // Client is a client for the Garage Admin API v2
 type Client struct {
 	endpoint   string
 	adminToken string
+	userAgent  string
 	httpClient *http.Client
+
+	// endpoints the client fails over between, starting with endpoint
+	endpoints []*adminEndpoint
+	cooldown  time.Duration
+	observer  EndpointObserver
+	requests  RequestObserver
+	retry     RetryPolicy
+
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+
+	mu     sync.Mutex
+	active int
+}
+
+// options are the settings collected from a list of Option
+type options struct {
+	httpClient *http.Client
+	transport  http.RoundTripper
+	timeout    time.Duration
+	userAgent  string
+	endpoints  []string
+	cooldown   time.Duration
+	observer   EndpointObserver
+	requests   RequestObserver
+	retry      RetryPolicy
+	tracer     trace.TracerProvider
+	propagator propagation.TextMapPropagator
+}
+
+// Option configures a Client
+type Option func(*options)
+
+// WithHTTPClient makes the client send requests with the supplied HTTP client,
+// e.g. to share connections between clients
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = hc
+	}
+}
+
+// WithTransport makes the client send requests through the supplied
+// transport. It takes precedence over the transport of an HTTP client supplied
+// with WithHTTPClient, and over the dialer used for Unix socket endpoints.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = rt
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(ua string) Option {
+	return func(o *options) {
+		o.userAgent = ua
+	}
+}
+
+// WithTimeout sets the timeout of every request. It takes precedence over the
+// timeout of an HTTP client supplied with WithHTTPClient.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// A RequestObserver is notified of every HTTP request the client sends, e.g.
+// to export metrics
+type RequestObserver interface {
+	// Observe is called when a request for the supplied operation (e.g.
+	// CreateBucket) completed. The code is the HTTP status code, or zero when
+	// no response was received.
+	Observe(operation string, code int, duration time.Duration, err error)
+}
+
+// WithRequestObserver notifies the supplied observer of every HTTP request
+// the client sends
+func WithRequestObserver(obs RequestObserver) Option {
+	return func(o *options) {
+		o.requests = obs
+	}
+}
+
+// WithTracerProvider makes the client record a span for every HTTP request
+// with the supplied provider instead of the global one
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracer = tp
+	}
+}
+
+// WithPropagator makes the client propagate trace context in request headers
+// with the supplied propagator instead of the global one
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(o *options) {
+		o.propagator = p
+	}
 }
 
-// NewClient creates a new Garage Admin API client
-func NewClient(endpoint, adminToken string) *Client {
-	return &Client{
+// NewClient creates a new Garage Admin API client. The endpoint is either an
+// HTTP(S) URL, optionally with a path prefix, or a Unix socket such as
+// unix:///run/garage/admin.sock.
+func NewClient(endpoint, adminToken string, opts ...Option) *Client {
+	o := &options{
+		userAgent:  DefaultUserAgent,
+		cooldown:   DefaultCooldown,
+		retry:      DefaultRetryPolicy,
+		tracer:     otel.GetTracerProvider(),
+		propagator: otel.GetTextMapPropagator(),
+	}
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	c := &Client{
 		endpoint:   endpoint,
 		adminToken: adminToken,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		userAgent:  o.userAgent,
+		httpClient: o.httpClient,
+		cooldown:   o.cooldown,
+		observer:   o.observer,
+		requests:   o.requests,
+		retry:      o.retry,
+		tracer:     o.tracer.Tracer(tracerName),
+		propagator: o.propagator,
+	}
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	if o.transport != nil || o.timeout != 0 {
+		// Copy the client so that a shared one is left untouched.
+		hc := *c.httpClient
+		if o.transport != nil {
+			hc.Transport = o.transport
+		}
+		if o.timeout != 0 {
+			hc.Timeout = o.timeout
+		}
+		c.httpClient = &hc
 	}
+
+	for _, e := range append([]string{endpoint}, o.endpoints...) {
+		c.endpoints = append(c.endpoints, newAdminEndpoint(e, c.httpClient, o.transport != nil))
+	}
+	return c
 }
 
-// doRequest performs an HTTP request to the Garage Admin API
-func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}, result interface{}) error {
-	var reqBody io.Reader
+// unixTransport returns a transport that dials the supplied Unix socket for
+// every request
+func unixTransport(socket string) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", socket)
+	}
+	return t
+}
+
+// APIError is returned when the Garage Admin API responds with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Body       string
+
+	// retryAfter is how long the server asked to wait before retrying
+	retryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// IsNotFound returns true if the error indicates that the requested bucket or key does not exist
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// doRequest performs an HTTP request for the supplied operation (e.g.
+// CreateBucket) to the Garage Admin API. Idempotent
+// requests are retried with backoff when they fail transiently.
+func (c *Client) doRequest(ctx context.Context, op, method, path string, body interface{}, result interface{}) error {
+	return c.do(ctx, op, method, path, body, result, nil)
+}
+
+// do performs an HTTP request to the Garage Admin API, retrying it while it
+// fails transiently and it is safe to do so. A request that is not idempotent
+// is retried only when the supplied lookup confirms that the failed attempt did
+// not take effect; when it did, lookup fills in the result instead.
+func (c *Client) do(ctx context.Context, op, method, path string, body interface{}, result interface{}, lookup func(context.Context) (bool, error)) error {
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
+		var err error
+		if jsonBody, err = json.Marshal(body); err != nil {
 			return fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonBody)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reqBody)
+	safe := idempotent(method, path)
+	for attempt := 1; ; attempt++ {
+		err := c.attempt(ctx, op, method, path, jsonBody, result, safe)
+		if err == nil || ctx.Err() != nil || attempt >= c.retry.MaxAttempts {
+			return err
+		}
+		if !retryable(err, safe) {
+			if lookup == nil || !retryable(err, true) {
+				return err
+			}
+			found, lerr := lookup(ctx)
+			if lerr != nil {
+				return err
+			}
+			if found {
+				return nil
+			}
+		}
+		wait, ok := c.retry.delay(attempt, err)
+		if !ok {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// attempt sends a request once, failing over to the next endpoint when the
+// active one cannot serve it. A request that is not idempotent fails over only
+// when it never left the client; otherwise it may have been processed, and the
+// error is returned for do to find out.
+func (c *Client) attempt(ctx context.Context, op, method, path string, body []byte, result interface{}, safe bool) error {
+	var err error
+	for _, i := range c.order(time.Now()) {
+		var failed bool
+		failed, err = c.send(ctx, op, c.endpoints[i], method, path, body, result)
+		if ctx.Err() != nil {
+			return err
+		}
+		if !failed {
+			c.activate(i)
+			return err
+		}
+		c.eject(i, err)
+		if !safe && c.endpoints[i].err == nil && !notSent(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// send performs an HTTP request against a single endpoint. It reports whether
+// the error means the endpoint is unavailable, so that it should be skipped.
+func (c *Client) send(ctx context.Context, op string, e *adminEndpoint, method, path string, body []byte, result interface{}) (failed bool, err error) {
+	ctx, span := c.tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String(method), attribute.String("garage.endpoint", e.raw)))
+	code := 0
+	start := time.Now()
+	defer func() {
+		if code != 0 {
+			span.SetAttributes(semconv.HTTPResponseStatusCode(code))
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		if c.requests != nil {
+			c.requests.Observe(op, code, time.Since(start), err)
+		}
+	}()
+
+	if e.err != nil {
+		return true, e.err
+	}
+	u, err := e.url(path)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return false, fmt.Errorf("failed to build request URL: %w", err)
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.adminToken)
 	req.Header.Set("Content-Type", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	span.SetAttributes(semconv.URLFull(u))
+	c.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := e.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		return true, fmt.Errorf("failed to execute request: %w", err)
 	}
+	code = resp.StatusCode
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return unavailable(resp.StatusCode), &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       string(bodyBytes),
+			retryAfter: retryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
 	}
 
 	if result != nil && resp.StatusCode != http.StatusNoContent {
 		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
+			return false, fmt.Errorf("failed to decode response: %w", err)
 		}
 	}
 
-	return nil
+	return false, nil
+}
+
+// IsUnauthorized returns true if the error indicates that the admin token was rejected
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden)
+}
+
+// Cluster health states reported by Garage
+const (
+	ClusterHealthy     = "healthy"
+	ClusterDegraded    = "degraded"
+	ClusterUnavailable = "unavailable"
+)
+
+// ClusterHealth represents the health of a Garage cluster
+type ClusterHealth struct {
+	Status           string `json:"status"`
+	KnownNodes       int    `json:"knownNodes"`
+	ConnectedNodes   int    `json:"connectedNodes"`
+	StorageNodes     int    `json:"storageNodes"`
+	StorageNodesOK   int    `json:"storageNodesOk"`
+	Partitions       int    `json:"partitions"`
+	PartitionsQuorum int    `json:"partitionsQuorum"`
+	PartitionsAllOK  int    `json:"partitionsAllOk"`
+}
+
+// ClusterStatus represents the status of a Garage cluster as seen by the node that answered
+type ClusterStatus struct {
+	Node          string       `json:"node"`
+	GarageVersion string       `json:"garageVersion"`
+	LayoutVersion int64        `json:"layoutVersion"`
+	Nodes         []NodeStatus `json:"nodes,omitempty"`
+}
+
+// NodeStatus represents the status of a node of a Garage cluster
+type NodeStatus struct {
+	ID                string     `json:"id"`
+	Addr              *string    `json:"addr,omitempty"`
+	Hostname          *string    `json:"hostname,omitempty"`
+	GarageVersion     *string    `json:"garageVersion,omitempty"`
+	IsUp              bool       `json:"isUp"`
+	LastSeenSecsAgo   *int64     `json:"lastSeenSecsAgo,omitempty"`
+	Role              *NodeRole  `json:"role,omitempty"`
+	Draining          bool       `json:"draining"`
+	DataPartition     *DiskSpace `json:"dataPartition,omitempty"`
+	MetadataPartition *DiskSpace `json:"metadataPartition,omitempty"`
+}
+
+// NodeRole represents the role of a node in the cluster layout. Gateway nodes
+// have no capacity.
+type NodeRole struct {
+	Zone     string   `json:"zone"`
+	Capacity *int64   `json:"capacity,omitempty"`
+	Tags     []string `json:"tags"`
+}
+
+// DiskSpace represents the space of the disk holding a node's data or metadata
+type DiskSpace struct {
+	Available int64 `json:"available"`
+	Total     int64 `json:"total"`
+}
+
+// GetClusterHealth retrieves the health of the cluster
+func (c *Client) GetClusterHealth(ctx context.Context) (*ClusterHealth, error) {
+	var result ClusterHealth
+	err := c.doRequest(ctx, "GetClusterHealth", "GET", "/v1/health", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetClusterStatus retrieves the status of the cluster
+func (c *Client) GetClusterStatus(ctx context.Context) (*ClusterStatus, error) {
+	var result ClusterStatus
+	err := c.doRequest(ctx, "GetClusterStatus", "GET", "/v1/status", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
 // Bucket represents a Garage bucket
@@ -77,6 +452,14 @@ type Bucket struct {
 	LocalAliases  map[string]string `json:"localAliases,omitempty"`
 	Keys          []BucketKeyPerm   `json:"keys,omitempty"`
 	Quotas        *BucketQuotas     `json:"quotas,omitempty"`
+	WebsiteAccess bool              `json:"websiteAccess,omitempty"`
+	WebsiteConfig *WebsiteConfig    `json:"websiteConfig,omitempty"`
+}
+
+// WebsiteConfig represents the website configuration of a bucket
+type WebsiteConfig struct {
+	IndexDocument string  `json:"indexDocument"`
+	ErrorDocument *string `json:"errorDocument,omitempty"`
 }
 
 // BucketKeyPerm represents permissions for a key on a bucket
@@ -108,7 +491,23 @@ type CreateBucketRequest struct {
 // CreateBucket creates a new bucket
 func (c *Client) CreateBucket(ctx context.Context, req *CreateBucketRequest) (*Bucket, error) {
 	var result Bucket
-	err := c.doRequest(ctx, "POST", "/v1/bucket", req, &result)
+	var lookup func(context.Context) (bool, error)
+	if req.GlobalAlias != nil {
+		// A bucket with the alias exists only if an earlier attempt created it;
+		// creating a bucket under a taken alias fails outright.
+		lookup = func(ctx context.Context) (bool, error) {
+			b, err := c.GetBucketByAlias(ctx, *req.GlobalAlias)
+			if IsNotFound(err) {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			result = *b
+			return true, nil
+		}
+	}
+	err := c.do(ctx, "CreateBucket", "POST", "/v1/bucket", req, &result, lookup)
 	if err != nil {
 		return nil, err
 	}
@@ -118,7 +517,7 @@ func (c *Client) CreateBucket(ctx context.Context, req *CreateBucketRequest) (*B
 // GetBucket retrieves a bucket by ID
 func (c *Client) GetBucket(ctx context.Context, bucketID string) (*Bucket, error) {
 	var result Bucket
-	err := c.doRequest(ctx, "GET", "/v1/bucket?id="+bucketID, nil, &result)
+	err := c.doRequest(ctx, "GetBucket", "GET", "/v1/bucket?id="+bucketID, nil, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +527,7 @@ func (c *Client) GetBucket(ctx context.Context, bucketID string) (*Bucket, error
 // GetBucketByAlias retrieves a bucket by global alias
 func (c *Client) GetBucketByAlias(ctx context.Context, globalAlias string) (*Bucket, error) {
 	var result Bucket
-	err := c.doRequest(ctx, "GET", "/v1/bucket?globalAlias="+globalAlias, nil, &result)
+	err := c.doRequest(ctx, "GetBucketByAlias", "GET", "/v1/bucket?globalAlias="+globalAlias, nil, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -137,7 +536,7 @@ func (c *Client) GetBucketByAlias(ctx context.Context, globalAlias string) (*Buc
 
 // DeleteBucket deletes a bucket
 func (c *Client) DeleteBucket(ctx context.Context, bucketID string) error {
-	return c.doRequest(ctx, "DELETE", "/v1/bucket?id="+bucketID, nil, nil)
+	return c.doRequest(ctx, "DeleteBucket", "DELETE", "/v1/bucket?id="+bucketID, nil, nil)
 }
 
 // UpdateBucketRequest is the request to update a bucket
@@ -152,13 +551,21 @@ type UpdateBucketRequest struct {
 		Add         *string `json:"add,omitempty"`
 		Remove      *string `json:"remove,omitempty"`
 	} `json:"localAlias,omitempty"`
-	Quotas *BucketQuotas `json:"quotas,omitempty"`
+	Quotas        *BucketQuotas  `json:"quotas,omitempty"`
+	WebsiteAccess *WebsiteAccess `json:"websiteAccess,omitempty"`
+}
+
+// WebsiteAccess enables or disables serving a bucket on the web endpoint
+type WebsiteAccess struct {
+	Enabled       bool    `json:"enabled"`
+	IndexDocument *string `json:"indexDocument,omitempty"`
+	ErrorDocument *string `json:"errorDocument,omitempty"`
 }
 
 // UpdateBucket updates a bucket
 func (c *Client) UpdateBucket(ctx context.Context, req *UpdateBucketRequest) (*Bucket, error) {
 	var result Bucket
-	err := c.doRequest(ctx, "PUT", "/v1/bucket", req, &result)
+	err := c.doRequest(ctx, "UpdateBucket", "PUT", "/v1/bucket", req, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -170,6 +577,9 @@ type Key struct {
 	AccessKeyID     string           `json:"accessKeyId"`
 	Name            string           `json:"name"`
 	SecretAccessKey string           `json:"secretAccessKey,omitempty"`
+	Created         *time.Time       `json:"created,omitempty"`
+	Expiration      *time.Time       `json:"expiration,omitempty"`
+	Expired         bool             `json:"expired,omitempty"`
 	Permissions     KeyPermissions   `json:"permissions"`
 	Buckets         []KeyBucketPerms `json:"buckets,omitempty"`
 }
@@ -183,6 +593,7 @@ type KeyPermissions struct {
 type KeyBucketPerms struct {
 	ID            string   `json:"id"`
 	GlobalAliases []string `json:"globalAliases"`
+	LocalAliases  []string `json:"localAliases,omitempty"`
 	Permissions   struct {
 		Read  bool `json:"read"`
 		Write bool `json:"write"`
@@ -195,10 +606,12 @@ type CreateKeyRequest struct {
 	Name string `json:"name"`
 }
 
-// CreateKey creates a new access key
+// CreateKey creates a new access key. Key names are not unique, so when an
+// attempt fails in a way that it may have taken effect there is no telling
+// which key it created; the error is returned and the key is not retried.
 func (c *Client) CreateKey(ctx context.Context, req *CreateKeyRequest) (*Key, error) {
 	var result Key
-	err := c.doRequest(ctx, "POST", "/v1/key", req, &result)
+	err := c.doRequest(ctx, "CreateKey", "POST", "/v1/key", req, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -208,17 +621,28 @@ func (c *Client) CreateKey(ctx context.Context, req *CreateKeyRequest) (*Key, er
 // GetKey retrieves a key by ID
 func (c *Client) GetKey(ctx context.Context, accessKeyID string) (*Key, error) {
 	var result Key
-	err := c.doRequest(ctx, "GET", "/v1/key?id="+accessKeyID, nil, &result)
+	err := c.doRequest(ctx, "GetKey", "GET", "/v1/key?id="+accessKeyID, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetKeyWithSecret retrieves a key by ID, including its secret access key
+func (c *Client) GetKeyWithSecret(ctx context.Context, accessKeyID string) (*Key, error) {
+	var result Key
+	err := c.doRequest(ctx, "GetKeyWithSecret", "GET", "/v1/key?id="+accessKeyID+"&showSecretKey=true", nil, &result)
 	if err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
-// GetKeyByName searches for a key by name pattern and returns it if exactly one match is found
+// GetKeyByName searches for a key by name pattern and returns it if exactly one match is found.
+// It returns an error for which IsNotFound is true if no key has the name.
 func (c *Client) GetKeyByName(ctx context.Context, name string) (*Key, error) {
 	var results []KeyInfo
-	err := c.doRequest(ctx, "GET", "/v1/key?search="+name, nil, &results)
+	err := c.doRequest(ctx, "GetKeyByName", "GET", "/v1/key?search="+name, nil, &results)
 	if err != nil {
 		return nil, err
 	}
@@ -229,7 +653,7 @@ func (c *Client) GetKeyByName(ctx context.Context, name string) (*Key, error) {
 			return c.GetKey(ctx, k.ID)
 		}
 	}
-	return nil, fmt.Errorf("key with name %q not found", name)
+	return nil, &APIError{StatusCode: http.StatusNotFound, Body: fmt.Sprintf("key with name %q not found", name)}
 }
 
 // KeyInfo represents basic key information from list/search
@@ -240,7 +664,7 @@ type KeyInfo struct {
 
 // DeleteKey deletes a key
 func (c *Client) DeleteKey(ctx context.Context, accessKeyID string) error {
-	return c.doRequest(ctx, "DELETE", "/v1/key?id="+accessKeyID, nil, nil)
+	return c.doRequest(ctx, "DeleteKey", "DELETE", "/v1/key?id="+accessKeyID, nil, nil)
 }
 
 // UpdateKeyRequest is the request to update a key
@@ -249,12 +673,13 @@ type UpdateKeyRequest struct {
 	Name        *string         `json:"name,omitempty"`
 	Allow       *KeyPermissions `json:"allow,omitempty"`
 	Deny        *KeyPermissions `json:"deny,omitempty"`
+	Expiration  *time.Time      `json:"expiration,omitempty"`
 }
 
 // UpdateKey updates a key
 func (c *Client) UpdateKey(ctx context.Context, req *UpdateKeyRequest) (*Key, error) {
 	var result Key
-	err := c.doRequest(ctx, "PUT", "/v1/key", req, &result)
+	err := c.doRequest(ctx, "UpdateKey", "PUT", "/v1/key", req, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -275,23 +700,29 @@ type GrantKeyAccessRequest struct {
 // GrantKeyAccess grants a key access to a bucket
 func (c *Client) GrantKeyAccess(ctx context.Context, req *GrantKeyAccessRequest) (*Bucket, error) {
 	var result Bucket
-	err := c.doRequest(ctx, "POST", "/v1/bucket/allow", req, &result)
+	err := c.doRequest(ctx, "GrantKeyAccess", "POST", "/v1/bucket/allow", req, &result)
 	if err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
-// RevokeKeyAccessRequest is the request to revoke key access from a bucket
+// RevokeKeyAccessRequest is the request to revoke key access from a bucket.
+// Only the permissions set to true are revoked.
 type RevokeKeyAccessRequest struct {
 	BucketID    string `json:"bucketId"`
 	AccessKeyID string `json:"accessKeyId"`
+	Permissions struct {
+		Read  bool `json:"read"`
+		Write bool `json:"write"`
+		Owner bool `json:"owner"`
+	} `json:"permissions"`
 }
 
 // RevokeKeyAccess revokes a key's access from a bucket
 func (c *Client) RevokeKeyAccess(ctx context.Context, req *RevokeKeyAccessRequest) (*Bucket, error) {
 	var result Bucket
-	err := c.doRequest(ctx, "POST", "/v1/bucket/deny", req, &result)
+	err := c.doRequest(ctx, "RevokeKeyAccess", "POST", "/v1/bucket/deny", req, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -3,9 +3,12 @@ package garage
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -19,6 +22,12 @@ func TestNewClient(t *testing.T) {
 	if client.adminToken != "test-token" {
 		t.Errorf("Expected token 'test-token', got '%s'", client.adminToken)
 	}
+
+	hc := &http.Client{}
+	client = NewClient("http://localhost:3903", "test-token", WithHTTPClient(hc))
+	if client.httpClient != hc {
+		t.Error("Expected the supplied HTTP client to be used")
+	}
 }
 
 func TestCreateBucket(t *testing.T) {
@@ -213,6 +222,9 @@ func TestDeleteBucket(t *testing.T) {
 					t.Errorf("Unexpected error: %v", err)
 				}
 			}
+			if got, want := IsNotFound(err), tt.responseStatus == http.StatusNotFound; got != want {
+				t.Errorf("Expected IsNotFound to be %t, got %t", want, got)
+			}
 		})
 	}
 }
@@ -296,3 +308,266 @@ func TestGrantKeyAccess(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestGetClusterHealth(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   ClusterHealth
+		expectError    bool
+		unauthorized   bool
+	}{
+		{
+			name:           "healthy cluster",
+			responseStatus: http.StatusOK,
+			responseBody: ClusterHealth{
+				Status:          ClusterHealthy,
+				KnownNodes:      3,
+				ConnectedNodes:  3,
+				Partitions:      256,
+				PartitionsAllOK: 256,
+			},
+			expectError: false,
+		},
+		{
+			name:           "invalid token",
+			responseStatus: http.StatusForbidden,
+			expectError:    true,
+			unauthorized:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/health" {
+					t.Errorf("Expected path '/v1/health', got '%s'", r.URL.Path)
+				}
+
+				w.WriteHeader(tt.responseStatus)
+				if tt.responseStatus == http.StatusOK {
+					_ = json.NewEncoder(w).Encode(tt.responseBody)
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "test-token")
+			health, err := client.GetClusterHealth(context.Background())
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				if IsUnauthorized(err) != tt.unauthorized {
+					t.Errorf("Expected IsUnauthorized to be %v for %v", tt.unauthorized, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if *health != tt.responseBody {
+					t.Errorf("Expected health %+v, got %+v", tt.responseBody, *health)
+				}
+			}
+		})
+	}
+}
+
+func TestGetClusterStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/status" {
+			t.Errorf("Expected path '/v1/status', got '%s'", r.URL.Path)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{
+			"node": "n1",
+			"garageVersion": "v1.0.1",
+			"layoutVersion": 4,
+			"nodes": [
+				{
+					"id": "n1",
+					"addr": "10.0.0.1:3901",
+					"hostname": "garage-0",
+					"isUp": true,
+					"lastSeenSecsAgo": null,
+					"role": {"zone": "dc1", "capacity": 1000000000, "tags": ["ssd"]},
+					"draining": false,
+					"dataPartition": {"available": 600, "total": 1000},
+					"metadataPartition": {"available": 60, "total": 100}
+				},
+				{
+					"id": "n2",
+					"isUp": false,
+					"lastSeenSecsAgo": 42,
+					"role": {"zone": "dc1", "capacity": null, "tags": []},
+					"draining": true
+				}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	status, err := client.GetClusterStatus(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if status.Node != "n1" || status.GarageVersion != "v1.0.1" || status.LayoutVersion != 4 {
+		t.Errorf("Unexpected status %+v", *status)
+	}
+	if len(status.Nodes) != 2 {
+		t.Fatalf("Expected 2 nodes, got %d", len(status.Nodes))
+	}
+
+	n1 := status.Nodes[0]
+	if n1.Addr == nil || *n1.Addr != "10.0.0.1:3901" || n1.Hostname == nil || *n1.Hostname != "garage-0" || !n1.IsUp {
+		t.Errorf("Unexpected node %+v", n1)
+	}
+	if n1.Role == nil || n1.Role.Zone != "dc1" || n1.Role.Capacity == nil || *n1.Role.Capacity != 1000000000 {
+		t.Errorf("Unexpected role %+v", n1.Role)
+	}
+	if n1.DataPartition == nil || *n1.DataPartition != (DiskSpace{Available: 600, Total: 1000}) {
+		t.Errorf("Unexpected data partition %+v", n1.DataPartition)
+	}
+	if n1.MetadataPartition == nil || *n1.MetadataPartition != (DiskSpace{Available: 60, Total: 100}) {
+		t.Errorf("Unexpected metadata partition %+v", n1.MetadataPartition)
+	}
+
+	n2 := status.Nodes[1]
+	if n2.IsUp || !n2.Draining || n2.LastSeenSecsAgo == nil || *n2.LastSeenSecsAgo != 42 {
+		t.Errorf("Unexpected node %+v", n2)
+	}
+	if n2.Role == nil || n2.Role.Capacity != nil {
+		t.Errorf("Expected a gateway role, got %+v", n2.Role)
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	var userAgent, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		path = r.URL.RequestURI()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hc := &http.Client{Timeout: time.Minute}
+	client := NewClient(server.URL+"/admin/", "test-token", WithHTTPClient(hc), WithTimeout(time.Second), WithUserAgent("test-agent"))
+	if hc.Timeout != time.Minute {
+		t.Error("Expected the supplied HTTP client to be left untouched")
+	}
+	if client.httpClient.Timeout != time.Second {
+		t.Errorf("Expected timeout 1s, got %s", client.httpClient.Timeout)
+	}
+	if err := client.DeleteBucket(context.Background(), "bucket-123"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if userAgent != "test-agent" {
+		t.Errorf("Expected User-Agent 'test-agent', got '%s'", userAgent)
+	}
+	if path != "/admin/v1/bucket?id=bucket-123" {
+		t.Errorf("Expected path '/admin/v1/bucket?id=bucket-123', got '%s'", path)
+	}
+
+	var used bool
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		used = true
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	client = NewClient(server.URL, "test-token", WithTransport(rt))
+	if err := client.DeleteBucket(context.Background(), "bucket-123"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !used {
+		t.Error("Expected the supplied transport to be used")
+	}
+	if userAgent != DefaultUserAgent {
+		t.Errorf("Expected User-Agent '%s', got '%s'", DefaultUserAgent, userAgent)
+	}
+}
+
+func TestUnixSocketEndpoint(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health" {
+			t.Errorf("Expected path '/v1/health', got '%s'", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ClusterHealth{Status: ClusterHealthy})
+	}))
+	server.Listener = l
+	server.Start()
+	defer server.Close()
+
+	client := NewClient("unix://"+socket, "test-token")
+	health, err := client.GetClusterHealth(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if health.Status != ClusterHealthy {
+		t.Errorf("Expected status '%s', got '%s'", ClusterHealthy, health.Status)
+	}
+}
+
+func TestInvalidEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"ftp://localhost", "unix://", "http://[::1"} {
+		client := NewClient(endpoint, "test-token")
+		if _, err := client.GetClusterHealth(context.Background()); err == nil {
+			t.Errorf("Expected an error for endpoint '%s'", endpoint)
+		}
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+type requestRecorder struct {
+	operations []string
+	codes      []int
+	errs       []error
+}
+
+func (r *requestRecorder) Observe(operation string, code int, _ time.Duration, err error) {
+	r.operations = append(r.operations, operation)
+	r.codes = append(r.codes, code)
+	r.errs = append(r.errs, err)
+}
+
+func TestRequestObserver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Bucket{ID: "bucket-123"})
+	}))
+	defer server.Close()
+
+	rec := &requestRecorder{}
+	client := NewClient(server.URL, "test-token", WithRequestObserver(rec))
+	if _, err := client.GetBucket(context.Background(), "bucket-123"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := client.DeleteBucket(context.Background(), "bucket-123"); !IsNotFound(err) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+
+	if len(rec.operations) != 2 || rec.operations[0] != "GetBucket" || rec.operations[1] != "DeleteBucket" {
+		t.Fatalf("Expected GetBucket and DeleteBucket to be observed, got %v", rec.operations)
+	}
+	if rec.codes[0] != http.StatusOK || rec.errs[0] != nil {
+		t.Errorf("Expected GetBucket to succeed with 200, got %d and %v", rec.codes[0], rec.errs[0])
+	}
+	if rec.codes[1] != http.StatusNotFound || rec.errs[1] == nil {
+		t.Errorf("Expected DeleteBucket to fail with 404, got %d and %v", rec.codes[1], rec.errs[1])
+	}
+}
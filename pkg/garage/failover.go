@@ -0,0 +1,166 @@
+package garage
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultCooldown is how long an endpoint that failed is skipped when none is
+// configured
+const DefaultCooldown = 30 * time.Second
+
+// An EndpointObserver is notified when a client fails over between endpoints,
+// e.g. to log or export which endpoint is in use
+type EndpointObserver interface {
+	// Ejected is called when an endpoint failed to serve a request and is
+	// skipped for the cool-down period
+	Ejected(endpoint string, err error)
+
+	// Activated is called when requests are served by another endpoint
+	Activated(endpoint string)
+}
+
+// WithEndpoints adds further endpoints of the same Garage cluster. Requests are
+// sent to the active endpoint; when it is unreachable or unavailable the client
+// fails over to the next one in turn. Requests that are not idempotent fail
+// over only when they could not be sent at all.
+func WithEndpoints(endpoints ...string) Option {
+	return func(o *options) {
+		o.endpoints = append(o.endpoints, endpoints...)
+	}
+}
+
+// WithCooldown sets how long an endpoint that failed is skipped before it is
+// tried again
+func WithCooldown(d time.Duration) Option {
+	return func(o *options) {
+		o.cooldown = d
+	}
+}
+
+// WithEndpointObserver notifies the supplied observer when the client fails
+// over between endpoints
+func WithEndpointObserver(obs EndpointObserver) Option {
+	return func(o *options) {
+		o.observer = obs
+	}
+}
+
+// adminEndpoint is one of the admin API endpoints of a client
+type adminEndpoint struct {
+	raw        string
+	baseURL    *url.URL
+	httpClient *http.Client
+
+	// err records an invalid endpoint, returned by every request to it
+	err error
+
+	// ejectedUntil is when the endpoint is tried again after a failure
+	ejectedUntil time.Time
+}
+
+// newAdminEndpoint parses the supplied endpoint. Requests are sent with the
+// supplied HTTP client, unless the endpoint is a Unix socket and the client's
+// transport was not set explicitly.
+func newAdminEndpoint(raw string, hc *http.Client, customTransport bool) *adminEndpoint {
+	e := &adminEndpoint{raw: raw, httpClient: hc}
+	u, err := url.Parse(raw)
+	switch {
+	case err != nil:
+		e.err = fmt.Errorf("invalid endpoint %q: %w", raw, err)
+	case u.Scheme == "unix":
+		if u.Path == "" {
+			e.err = fmt.Errorf("invalid endpoint %q: missing socket path", raw)
+			break
+		}
+		if !customTransport {
+			c := *hc
+			c.Transport = unixTransport(u.Path)
+			e.httpClient = &c
+		}
+		e.baseURL = &url.URL{Scheme: "http", Host: unixHost}
+	case u.Scheme == "http" || u.Scheme == "https":
+		e.baseURL = u
+	default:
+		e.err = fmt.Errorf("invalid endpoint %q: unsupported scheme %q", raw, u.Scheme)
+	}
+	return e
+}
+
+// url returns the URL of the supplied API path, which may carry a query
+func (e *adminEndpoint) url(path string) (string, error) {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	u := *e.baseURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + ref.Path
+	u.RawPath = ""
+	u.RawQuery = ref.RawQuery
+	return u.String(), nil
+}
+
+// unavailable returns true if the supplied status means the node could not
+// serve the request and another one might
+func unavailable(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// ActiveEndpoint returns the endpoint requests are currently sent to
+func (c *Client) ActiveEndpoint() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.endpoints[c.active].raw
+}
+
+// order returns the indices of the endpoints in the order a request should try
+// them: round-robin from the active one, skipping ejected endpoints until all
+// others failed.
+func (c *Client) order(now time.Time) []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.endpoints)
+	order := make([]int, 0, n)
+	var ejected []int
+	for j := 0; j < n; j++ {
+		i := (c.active + j) % n
+		if now.Before(c.endpoints[i].ejectedUntil) {
+			ejected = append(ejected, i)
+			continue
+		}
+		order = append(order, i)
+	}
+	return append(order, ejected...)
+}
+
+// eject skips the endpoint with the supplied index for the cool-down period
+func (c *Client) eject(i int, err error) {
+	c.mu.Lock()
+	c.endpoints[i].ejectedUntil = time.Now().Add(c.cooldown)
+	c.mu.Unlock()
+
+	if c.observer != nil {
+		c.observer.Ejected(c.endpoints[i].raw, err)
+	}
+}
+
+// activate records that the endpoint with the supplied index served a request
+func (c *Client) activate(i int) {
+	c.mu.Lock()
+	c.endpoints[i].ejectedUntil = time.Time{}
+	changed := c.active != i
+	c.active = i
+	c.mu.Unlock()
+
+	if changed && c.observer != nil {
+		c.observer.Activated(c.endpoints[i].raw)
+	}
+}
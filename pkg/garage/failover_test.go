@@ -0,0 +1,151 @@
+package garage
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	mu        sync.Mutex
+	ejected   []string
+	activated []string
+}
+
+func (r *recorder) Ejected(endpoint string, _ error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ejected = append(r.ejected, endpoint)
+}
+
+func (r *recorder) Activated(endpoint string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.activated = append(r.activated, endpoint)
+}
+
+func TestFailover(t *testing.T) {
+	var downHits int
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		downHits++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	var upHits int
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		upHits++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer up.Close()
+
+	// Nothing listens on the address of a closed server.
+	gone := httptest.NewServer(http.NotFoundHandler())
+	gone.Close()
+
+	rec := &recorder{}
+	client := NewClient(gone.URL, "test-token", WithEndpoints(down.URL, up.URL), WithCooldown(time.Hour), WithEndpointObserver(rec))
+
+	if err := client.DeleteBucket(context.Background(), "bucket-123"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := client.ActiveEndpoint(); got != up.URL {
+		t.Errorf("Expected active endpoint '%s', got '%s'", up.URL, got)
+	}
+	if len(rec.ejected) != 2 || rec.ejected[0] != gone.URL || rec.ejected[1] != down.URL {
+		t.Errorf("Expected '%s' and '%s' to be ejected, got %v", gone.URL, down.URL, rec.ejected)
+	}
+	if len(rec.activated) != 1 || rec.activated[0] != up.URL {
+		t.Errorf("Expected '%s' to be activated, got %v", up.URL, rec.activated)
+	}
+
+	// Ejected endpoints are skipped during their cool-down.
+	if err := client.DeleteBucket(context.Background(), "bucket-123"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if downHits != 1 || upHits != 2 {
+		t.Errorf("Expected 1 request to the unavailable endpoint and 2 to the available one, got %d and %d", downHits, upHits)
+	}
+}
+
+func TestFailoverAllUnavailable(t *testing.T) {
+	var hits int
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	client := NewClient(down.URL, "test-token", WithEndpoints(down.URL+"/"), WithCooldown(time.Hour), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	for i := 0; i < 2; i++ {
+		if err := client.DeleteBucket(context.Background(), "bucket-123"); err == nil {
+			t.Fatal("Expected an error when every endpoint is unavailable")
+		}
+	}
+	// Ejected endpoints are still tried when no other endpoint is left.
+	if hits != 4 {
+		t.Errorf("Expected 4 requests, got %d", hits)
+	}
+}
+
+func TestNoFailoverOnClientError(t *testing.T) {
+	var secondHits int
+	first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer first.Close()
+	second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		secondHits++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer second.Close()
+
+	client := NewClient(first.URL, "test-token", WithEndpoints(second.URL))
+	if err := client.DeleteBucket(context.Background(), "bucket-123"); !IsNotFound(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+	if secondHits != 0 {
+		t.Errorf("Expected no request to the second endpoint, got %d", secondHits)
+	}
+}
+
+func TestNoFailoverAfterSend(t *testing.T) {
+	var mu sync.Mutex
+	var posts int
+	handler := func(hang bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			posts++
+			mu.Unlock()
+			if hang {
+				// The key is created, but the response never arrives.
+				_, _ = io.Copy(io.Discard, r.Body)
+				select {
+				case <-r.Context().Done():
+				case <-time.After(5 * time.Second):
+				}
+				return
+			}
+			_ = json.NewEncoder(w).Encode(Key{AccessKeyID: "GK123", Name: "test-key"})
+		}
+	}
+	first := httptest.NewServer(handler(true))
+	defer first.Close()
+	second := httptest.NewServer(handler(false))
+	defer second.Close()
+
+	client := NewClient(first.URL, "test-token", WithEndpoints(second.URL), WithTimeout(50*time.Millisecond), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if _, err := client.CreateKey(context.Background(), &CreateKeyRequest{Name: "test-key"}); err == nil {
+		t.Fatal("Expected an error when the create request timed out")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if posts != 1 {
+		t.Errorf("Expected a single create request, got %d", posts)
+	}
+}
@@ -0,0 +1,127 @@
+package garage
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// A RetryPolicy configures how requests that fail transiently are retried
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts per request, including the first.
+	// One disables retries.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. It doubles with every
+	// further retry, with jitter.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between attempts. A request whose server asks
+	// to wait longer is not retried.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy used when none is configured
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// WithRetryPolicy sets how requests that fail transiently are retried
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = p
+	}
+}
+
+// delay returns how long to wait before the supplied attempt is retried, and
+// false if the server asked to wait longer than the policy allows
+func (p RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.retryAfter > 0 {
+		return apiErr.retryAfter, apiErr.retryAfter <= p.MaxDelay
+	}
+
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0, true
+	}
+	// Equal jitter: wait at least half the delay so that retries still back off.
+	return d/2 + rand.N(d/2+1), true
+}
+
+// idempotent returns true if repeating the supplied request has the same
+// effect as sending it once
+func idempotent(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	// Granting and revoking access set permission flags.
+	return path == "/v1/bucket/allow" || path == "/v1/bucket/deny"
+}
+
+// retryable returns true if the supplied error is transient and the request
+// may be retried. Requests that are not idempotent may only be retried when
+// they were certainly not processed.
+func retryable(err error, idempotent bool) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode == http.StatusTooManyRequests {
+			return true
+		}
+		return idempotent && unavailable(apiErr.StatusCode)
+	}
+	if notSent(err) {
+		return true
+	}
+	return idempotent && interrupted(err)
+}
+
+// interrupted returns true if the supplied error means the connection failed
+// while the request was in flight, so that it may or may not have been
+// processed
+func interrupted(err error) bool {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
+
+// notSent returns true if the supplied error occurred before the request was
+// sent, i.e. while connecting
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryAfter parses the value of a Retry-After header, either in seconds or
+// as an HTTP date
+func retryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,225 @@
+package garage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var fastRetries = WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond})
+
+func TestRetryIdempotent(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+		if hits == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Bucket{ID: "bucket-123"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token", fastRetries)
+	bucket, err := client.GetBucket(context.Background(), "bucket-123")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bucket.ID != "bucket-123" {
+		t.Errorf("Expected bucket 'bucket-123', got '%s'", bucket.ID)
+	}
+	if hits != 2 {
+		t.Errorf("Expected 2 requests, got %d", hits)
+	}
+}
+
+func TestRetryAfterTooLong(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token", fastRetries)
+	if _, err := client.GetBucket(context.Background(), "bucket-123"); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if hits != 1 {
+		t.Errorf("Expected 1 request, got %d", hits)
+	}
+}
+
+func TestRetryCreateBucket(t *testing.T) {
+	tests := []struct {
+		name       string
+		tookEffect bool
+		posts      int
+	}{
+		{name: "first attempt did not take effect", tookEffect: false, posts: 2},
+		{name: "first attempt took effect", tookEffect: true, posts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posts int
+			created := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case "POST":
+					posts++
+					if posts == 1 {
+						created = tt.tookEffect
+						w.WriteHeader(http.StatusServiceUnavailable)
+						return
+					}
+					created = true
+					_ = json.NewEncoder(w).Encode(Bucket{ID: "bucket-123", GlobalAliases: []string{"test-bucket"}})
+				case "GET":
+					if !created {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					_ = json.NewEncoder(w).Encode(Bucket{ID: "bucket-123", GlobalAliases: []string{"test-bucket"}})
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "test-token", fastRetries)
+			bucket, err := client.CreateBucket(context.Background(), &CreateBucketRequest{GlobalAlias: stringPtr("test-bucket")})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if bucket.ID != "bucket-123" {
+				t.Errorf("Expected bucket 'bucket-123', got '%s'", bucket.ID)
+			}
+			if posts != tt.posts {
+				t.Errorf("Expected %d create requests, got %d", tt.posts, posts)
+			}
+		})
+	}
+}
+
+func TestRetryCreateBucketWithoutAlias(t *testing.T) {
+	var posts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		posts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// Without an alias there is no way to tell whether the bucket was created.
+	client := NewClient(server.URL, "test-token", fastRetries)
+	if _, err := client.CreateBucket(context.Background(), &CreateBucketRequest{}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if posts != 1 {
+		t.Errorf("Expected 1 create request, got %d", posts)
+	}
+}
+
+func TestRetryCreateKey(t *testing.T) {
+	var posts, searches int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			// The key may have been created, but the response is lost.
+			posts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		searches++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	// Key names are not unique, so the key the attempt created cannot be told
+	// from another one created with the same name.
+	client := NewClient(server.URL, "test-token", fastRetries)
+	if _, err := client.CreateKey(context.Background(), &CreateKeyRequest{Name: "test-key"}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if posts != 1 || searches != 0 {
+		t.Errorf("Expected 1 create request and no lookup, got %d and %d", posts, searches)
+	}
+}
+
+func TestRetryCreateBucketAfterFailover(t *testing.T) {
+	var posts int
+	created := false
+	first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			// The bucket is created, but the node answers 503.
+			posts++
+			created = true
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer first.Close()
+	second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			posts++
+		}
+		if !created {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Bucket{ID: "bucket-123", GlobalAliases: []string{"test-bucket"}})
+	}))
+	defer second.Close()
+
+	// The create must not fail over to the second endpoint; the lookup finds
+	// the bucket the first one created instead.
+	client := NewClient(first.URL, "test-token", WithEndpoints(second.URL), fastRetries)
+	bucket, err := client.CreateBucket(context.Background(), &CreateBucketRequest{GlobalAlias: stringPtr("test-bucket")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bucket.ID != "bucket-123" {
+		t.Errorf("Expected bucket 'bucket-123', got '%s'", bucket.ID)
+	}
+	if posts != 1 {
+		t.Errorf("Expected 1 create request, got %d", posts)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "empty", value: "", want: 0},
+		{name: "seconds", value: "5", want: 5 * time.Second},
+		{name: "date", value: now.Add(time.Minute).Format(http.TimeFormat), want: time.Minute},
+		{name: "past date", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{name: "invalid", value: "soon", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfter(tt.value, now); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 5: time.Second} {
+		d, ok := p.delay(attempt, errors.New("boom"))
+		if !ok || d < max/2 || d > max {
+			t.Errorf("Expected a delay between %s and %s for attempt %d, got %s", max/2, max, attempt, d)
+		}
+	}
+
+	if d, ok := p.delay(1, &APIError{StatusCode: http.StatusTooManyRequests, retryAfter: 500 * time.Millisecond}); !ok || d != 500*time.Millisecond {
+		t.Errorf("Expected the Retry-After delay of 500ms, got %s", d)
+	}
+}
@@ -0,0 +1,89 @@
+package garage
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewTransport(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"healthy"}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	// The test server's certificate doubles as CA bundle and client certificate.
+	serverCert := server.TLS.Certificates[0]
+	caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	key, err := x509.MarshalPKCS8PrivateKey(serverCert.PrivateKey)
+	if err != nil {
+		t.Fatalf("Cannot marshal key: %v", err)
+	}
+	keyData := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key})
+
+	tests := []struct {
+		name          string
+		config        TLSConfig
+		expectInvalid bool
+		expectError   bool
+	}{
+		{
+			name:   "trusted CA and client certificate",
+			config: TLSConfig{CAData: caData, CertData: caData, KeyData: keyData},
+		},
+		{
+			name:   "insecure skip verify",
+			config: TLSConfig{InsecureSkipVerify: true, CertData: caData, KeyData: keyData},
+		},
+		{
+			name:        "unknown CA",
+			config:      TLSConfig{CertData: caData, KeyData: keyData},
+			expectError: true,
+		},
+		{
+			name:        "missing client certificate",
+			config:      TLSConfig{CAData: caData},
+			expectError: true,
+		},
+		{
+			name:          "invalid CA bundle",
+			config:        TLSConfig{CAData: []byte("not a certificate")},
+			expectInvalid: true,
+		},
+		{
+			name:          "client certificate without key",
+			config:        TLSConfig{CertData: caData},
+			expectInvalid: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := NewTransport(tt.config)
+			if tt.expectInvalid {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			client := NewClient(server.URL, "test-token", WithHTTPClient(&http.Client{Transport: transport}))
+			_, err = client.GetClusterHealth(context.Background())
+			if tt.expectError && err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
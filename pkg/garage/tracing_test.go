@@ -0,0 +1,46 @@
+package garage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	client := NewClient(server.URL, "test-token", WithTracerProvider(tp), WithPropagator(propagation.TraceContext{}))
+
+	if err := client.DeleteBucket(context.Background(), "bucket-123"); !IsNotFound(err) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "DeleteBucket" {
+		t.Errorf("Expected span 'DeleteBucket', got '%s'", span.Name)
+	}
+	if span.Status.Code != codes.Error {
+		t.Errorf("Expected span status Error, got %s", span.Status.Code)
+	}
+	want := "00-" + span.SpanContext.TraceID().String() + "-" + span.SpanContext.SpanID().String() + "-01"
+	if traceparent != want {
+		t.Errorf("Expected traceparent '%s', got '%s'", want, traceparent)
+	}
+}
@@ -100,8 +100,8 @@ var _ = Describe("Bucket Controller", func() {
 			}
 			Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
 
-			// Create ProviderConfig
-			pc := &v1.ProviderConfig{
+			// Create ClusterProviderConfig
+			pc := &v1.ClusterProviderConfig{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "test-provider-config",
 				},
@@ -135,7 +135,7 @@ var _ = Describe("Bucket Controller", func() {
 					},
 				},
 			}
-			bucket.Spec.ProviderConfigReference = &xpv1.Reference{Name: "test-provider-config"}
+			bucket.Spec.ProviderConfigReference = &v1.ProviderConfigReference{Name: "test-provider-config", Kind: v1.ClusterProviderConfigKind}
 
 			Expect(k8sClient.Create(ctx, bucket)).Should(Succeed())
 